@@ -1,20 +1,31 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log/slog"
+	"net"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"todolist/internal/database"
+	"todolist/internal/graph"
+	"todolist/internal/grpcapi"
 	"todolist/internal/repository"
 	"todolist/internal/routes"
 	"todolist/internal/service"
+	"todolist/internal/smtpd"
+	"todolist/internal/storage"
 	"todolist/internal/utils"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // @title ToDoList API
@@ -66,6 +77,25 @@ func main() {
 
 	userRepo := repository.NewUsersRepository(db)
 	todoRepo := repository.NewTodoRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	inboundRepo := repository.NewInboundRepository(db)
+	chatRepo := repository.NewChatRepository(db)
+	templateRepo := repository.NewTemplateRepository(db)
+	shareRepo := repository.NewShareRepository(db)
+	workspaceRepo := repository.NewWorkspaceRepository(db)
+	commentRepo := repository.NewCommentRepository(db)
+	attachmentRepo := repository.NewAttachmentRepository(db)
+	publicLinkRepo := repository.NewPublicLinkRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	passwordResetRepo := repository.NewPasswordResetRepository(db)
+	recoveryCodeRepo := repository.NewRecoveryCodeRepository(db)
+	webauthnRepo := repository.NewWebAuthnRepository(db)
+	authAttemptRepo := repository.NewAuthAttemptRepository(db)
+	verificationTokenRepo := repository.NewVerificationTokenRepository(db)
+	oidcStateRepo := repository.NewOIDCStateRepository(db)
+	identityRepo := repository.NewUserIdentityRepository(db)
+	accessTokenRepo := repository.NewAccessTokenRepository(db)
 
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
@@ -73,19 +103,138 @@ func main() {
 		os.Exit(1)
 	}
 
-	jwtManager := utils.NewJWTManager(jwtSecret, time.Hour*24*7)
+	accessTTL := durationEnv("ACCESS_TOKEN_TTL", 15*time.Minute)
+	refreshTTL := durationEnv("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+	jwtManager := utils.NewJWTManager(jwtSecret, accessTTL)
 
-	userService := service.NewUserService(userRepo, jwtManager)
-	todoService := service.NewTodoService(todoRepo)
+	sessionService := service.NewSessionService(sessionRepo, jwtManager)
+	attemptLimiter := service.NewAttemptLimiter(authAttemptRepo)
+	userService := service.NewUserService(userRepo, refreshTokenRepo, passwordResetRepo, verificationTokenRepo, identityRepo, sessionService, attemptLimiter, jwtManager, refreshTTL)
+	twoFactorService := service.NewTwoFactorService(userRepo, recoveryCodeRepo, userService, attemptLimiter, jwtManager)
+	identityService := service.NewIdentityService(identityRepo, userRepo, webauthnRepo, userService)
+	accessTokenService := service.NewAccessTokenService(accessTokenRepo, sessionService, userService)
+	sessionService.AddRevocationListener(accessTokenService)
+	rpID, rpOrigins := webauthnRelyingParty()
+	webauthnService, err := service.NewWebAuthnService(webauthnRepo, userRepo, userService, identityService, attemptLimiter, rpID, rpOrigins)
+	if err != nil {
+		slog.Error("WebAuthn setup failed", "error", err)
+		os.Exit(1)
+	}
+	oidcService, err := service.NewOIDCService(oidcStateRepo, userService, identityService, oidcRedirectURL(), oidcProviders())
+	if err != nil {
+		slog.Error("OIDC setup failed", "error", err)
+		os.Exit(1)
+	}
+	todoService := service.NewTodoService(todoRepo, shareRepo, workspaceRepo)
+	webhookService := service.NewWebhookService(webhookRepo)
+	eventBroker := service.NewEventBroker()
+	templateService := service.NewTemplateService(templateRepo, todoService)
+	shareService := service.NewShareService(shareRepo, userRepo, todoService)
+	workspaceService := service.NewWorkspaceService(workspaceRepo, userRepo)
+	commentService := service.NewCommentService(commentRepo, userRepo, workspaceRepo, shareRepo, todoService)
+	publicLinkService := service.NewPublicLinkService(publicLinkRepo, todoService, attemptLimiter)
+	todoService.AddListener(webhookService)
+	todoService.AddListener(eventBroker)
 
-	userHandler := routes.NewUserHandler(userService)
-	todoHandler := routes.NewTodoHandler(todoService)
+	blobStore, err := newBlobStorage()
+	if err != nil {
+		slog.Error("Attachment storage setup failed", "error", err)
+		os.Exit(1)
+	}
+	attachmentMaxBytes, _ := strconv.ParseInt(os.Getenv("ATTACHMENT_MAX_BYTES"), 10, 64)
+	attachmentService := service.NewAttachmentService(attachmentRepo, blobStore, todoService, attachmentMaxBytes)
+	todoService.AddListener(attachmentService)
+	userService.AddDeletionHook(workspaceService)
+	userService.AddDeletionHook(attachmentService)
+	workspaceService.AddDeletionHook(attachmentService)
+
+	graphAPI, err := graph.New(todoService, userService)
+	if err != nil {
+		slog.Error("GraphQL schema build failed", "error", err)
+		os.Exit(1)
+	}
+
+	handlers := routes.Handlers{
+		User:        routes.NewUserHandler(userService),
+		Todo:        routes.NewTodoHandler(todoService),
+		Webhook:     routes.NewWebhookHandler(webhookService),
+		Event:       routes.NewEventHandler(eventBroker),
+		GraphQL:     routes.NewGraphQLHandler(graphAPI),
+		Template:    routes.NewTemplateHandler(templateService),
+		Share:       routes.NewShareHandler(shareService),
+		Workspace:   routes.NewWorkspaceHandler(workspaceService),
+		Comment:     routes.NewCommentHandler(commentService),
+		Attachment:  routes.NewAttachmentHandler(attachmentService),
+		PublicLink:  routes.NewPublicLinkHandler(publicLinkService),
+		Session:     routes.NewSessionHandler(sessionService),
+		TwoFactor:   routes.NewTwoFactorHandler(twoFactorService),
+		WebAuthn:    routes.NewWebAuthnHandler(webauthnService),
+		OIDC:        routes.NewOIDCHandler(oidcService),
+		Identity:    routes.NewIdentityHandler(identityService),
+		AccessToken: routes.NewAccessTokenHandler(accessTokenService),
+	}
+
+	if slackSecret := os.Getenv("SLACK_SIGNING_SECRET"); slackSecret != "" {
+		chatService := service.NewChatService(chatRepo, todoService)
+		handlers.Slack = routes.NewSlackHandler(chatService, slackSecret)
+	}
+
+	if inboundAddr := os.Getenv("INBOUND_SMTP_ADDR"); inboundAddr != "" {
+		inboundDomain := os.Getenv("INBOUND_SMTP_DOMAIN")
+		if inboundDomain == "" {
+			slog.Error("INBOUND_SMTP_DOMAIN environment variable not set")
+			os.Exit(1)
+		}
+		maxBytes, err := strconv.ParseInt(os.Getenv("INBOUND_SMTP_MAX_BYTES"), 10, 64)
+		if err != nil || maxBytes <= 0 {
+			maxBytes = 1 << 20
+		}
+
+		inboundService := service.NewInboundMailService(inboundRepo, userRepo, todoService, inboundDomain)
+		handlers.Inbound = routes.NewInboundHandler(inboundService)
+
+		smtpServer := smtpd.NewServer(inboundAddr, inboundDomain, maxBytes, inboundService)
+		go func() {
+			slog.Info("Starting inbound SMTP server", "addr", inboundAddr, "domain", inboundDomain)
+			if err := smtpServer.ListenAndServe(); err != nil {
+				slog.Error("Inbound SMTP server failed", "error", err)
+			}
+		}()
+		defer smtpServer.Close()
+	}
+
+	if grpcPort := os.Getenv("GRPC_PORT"); grpcPort != "" {
+		listener, err := net.Listen("tcp", ":"+grpcPort)
+		if err != nil {
+			slog.Error("gRPC listener failed", "error", err)
+			os.Exit(1)
+		}
+		var opts []grpc.ServerOption
+		if certFile, keyFile := os.Getenv("GRPC_TLS_CERT"), os.Getenv("GRPC_TLS_KEY"); certFile != "" || keyFile != "" {
+			creds, err := credentials.NewServerTLSFromFile(certFile, keyFile)
+			if err != nil {
+				slog.Error("Failed to load gRPC TLS certificate", "error", err)
+				os.Exit(1)
+			}
+			opts = append(opts, grpc.Creds(creds))
+		} else {
+			slog.Warn("gRPC server is running without TLS; set GRPC_TLS_CERT and GRPC_TLS_KEY outside local development")
+		}
+		grpcServer := grpcapi.NewServer(todoService, userService, sessionService, opts...)
+		go func() {
+			slog.Info("Starting gRPC server", "port", grpcPort)
+			if err := grpcServer.Serve(listener); err != nil {
+				slog.Error("gRPC server failed", "error", err)
+			}
+		}()
+		defer grpcServer.GracefulStop()
+	}
 
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.New()
 	r.Use(gin.Recovery())
 
-	routes.SetupRoutes(r, userHandler, todoHandler, jwtManager)
+	routes.SetupRoutes(r, handlers, accessTokenService)
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -100,3 +249,102 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// newBlobStorage picks the attachment storage from STORAGE_DRIVER: "local"
+// (default) keeps files in STORAGE_DIR, "s3" uses an S3-compatible bucket.
+func newBlobStorage() (storage.Storage, error) {
+	switch driver := os.Getenv("STORAGE_DRIVER"); driver {
+	case "", "local":
+		dir := os.Getenv("STORAGE_DIR")
+		if dir == "" {
+			dir = "./data/attachments"
+		}
+		return storage.NewLocal(dir)
+	case "s3":
+		useSSL, _ := strconv.ParseBool(os.Getenv("S3_USE_SSL"))
+		return storage.NewS3(context.Background(), storage.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Region:    os.Getenv("S3_REGION"),
+			UseSSL:    useSSL,
+		})
+	default:
+		return nil, errors.New("unknown STORAGE_DRIVER " + driver)
+	}
+}
+
+// webauthnRelyingParty reads the passkey domain (WEBAUTHN_RP_ID) and the
+// comma-separated page origins allowed to use it (WEBAUTHN_ORIGINS).
+func webauthnRelyingParty() (string, []string) {
+	rpID := os.Getenv("WEBAUTHN_RP_ID")
+	if rpID == "" {
+		rpID = "localhost"
+	}
+	var origins []string
+	for _, origin := range strings.Split(os.Getenv("WEBAUTHN_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	if len(origins) == 0 {
+		origins = []string{"http://localhost:5173", "http://localhost:8080"}
+	}
+	return rpID, origins
+}
+
+// oidcProviders reads the login providers listed in OIDC_PROVIDERS. Each one
+// is configured by OIDC_<ID>_* variables: CLIENT_ID, CLIENT_SECRET, NAME,
+// SCOPES, TRUST_EMAIL and either ISSUER for discovery or AUTH_URL, TOKEN_URL
+// and USERINFO_URL for plain OAuth 2.0.
+func oidcProviders() []service.OIDCProviderConfig {
+	var providers []service.OIDCProviderConfig
+	for _, id := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		id = strings.ToLower(strings.TrimSpace(id))
+		if id == "" {
+			continue
+		}
+		env := func(name string) string {
+			return os.Getenv("OIDC_" + strings.ToUpper(strings.ReplaceAll(id, "-", "_")) + "_" + name)
+		}
+		trustEmail, _ := strconv.ParseBool(env("TRUST_EMAIL"))
+		providers = append(providers, service.OIDCProviderConfig{
+			ID:           id,
+			Name:         env("NAME"),
+			Issuer:       env("ISSUER"),
+			ClientID:     env("CLIENT_ID"),
+			ClientSecret: env("CLIENT_SECRET"),
+			Scopes:       strings.Fields(strings.ReplaceAll(env("SCOPES"), ",", " ")),
+			AuthURL:      env("AUTH_URL"),
+			TokenURL:     env("TOKEN_URL"),
+			UserInfoURL:  env("USERINFO_URL"),
+			TrustEmail:   trustEmail,
+		})
+	}
+	return providers
+}
+
+// oidcRedirectURL is the page providers send the browser back to; it must be
+// registered with every provider.
+func oidcRedirectURL() string {
+	if url := os.Getenv("OIDC_REDIRECT_URL"); url != "" {
+		return url
+	}
+	return "http://localhost:5173/"
+}
+
+// durationEnv parses a duration such as "15m" or "720h", falling back to def
+// when the variable is unset or invalid.
+func durationEnv(name string, def time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		slog.Warn("Invalid duration, using default", "name", name, "value", value, "default", def)
+		return def
+	}
+	return d
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"todolist/internal/models"
+)
+
+type client struct {
+	server string
+	cfg    *config
+	http   *http.Client
+}
+
+func newClient(cfg *config) *client {
+	return &client{
+		server: strings.TrimRight(cfg.Server, "/"),
+		cfg:    cfg,
+		http:   &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type loginResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	MFARequired  bool   `json:"mfa_required"`
+	MFAToken     string `json:"mfa_token"`
+}
+
+var errUnauthorized = errors.New("session expired, run `todo login` again")
+
+type quickAddRequest struct {
+	Text     string `json:"text"`
+	Timezone string `json:"timezone"`
+}
+
+// do sends body as JSON and decodes a successful response into out.
+// Error responses carry {"err": "..."} and are returned as errors. An expired
+// access token is refreshed once and the request retried.
+func (c *client) do(method, path string, body, out any) error {
+	err := c.send(method, path, body, out)
+	if errors.Is(err, errUnauthorized) && c.cfg.RefreshToken != "" {
+		if c.refresh() == nil {
+			return c.send(method, path, body, out)
+		}
+	}
+	return err
+}
+
+// refresh swaps the stored refresh token for a new pair and saves it.
+func (c *client) refresh() error {
+	var resp loginResponse
+	refreshToken := c.cfg.RefreshToken
+	c.cfg.Token, c.cfg.RefreshToken = "", ""
+	err := c.send(http.MethodPost, "/auth/refresh", map[string]string{"refresh_token": refreshToken}, &resp)
+	if err == nil && resp.Token == "" {
+		err = errors.New("server did not return a token")
+	}
+	if err == nil {
+		c.cfg.Token, c.cfg.RefreshToken = resp.Token, resp.RefreshToken
+	}
+	if saveErr := c.cfg.save(); err == nil {
+		err = saveErr
+	}
+	return err
+}
+
+func (c *client) send(method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.server+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "todo-cli")
+	if c.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized && c.cfg.Token != "" {
+		return errUnauthorized
+	}
+	if resp.StatusCode >= 400 {
+		var apiErr struct {
+			Err string `json:"err"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Err != "" {
+			return errors.New(apiErr.Err)
+		}
+		return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (c *client) login(username, password string) (*loginResponse, error) {
+	var resp loginResponse
+	err := c.send(http.MethodPost, "/auth/login", map[string]string{"username": username, "password": password}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Token == "" && !resp.MFARequired {
+		return nil, errors.New("server did not return a token")
+	}
+	return &resp, nil
+}
+
+// verifyTwoFactor completes a login that asked for a second factor.
+func (c *client) verifyTwoFactor(mfaToken, code string) (*loginResponse, error) {
+	var resp loginResponse
+	err := c.send(http.MethodPost, "/auth/2fa/verify", map[string]string{"mfa_token": mfaToken, "code": code}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Token == "" {
+		return nil, errors.New("server did not return a token")
+	}
+	return &resp, nil
+}
+
+func (c *client) logout() error {
+	return c.do(http.MethodPost, "/auth/logout", nil, nil)
+}
+
+func (c *client) listTodos() ([]models.Todo, error) {
+	var todos []models.Todo
+	return todos, c.do(http.MethodGet, "/api/todos", nil, &todos)
+}
+
+func (c *client) quickAdd(text, timezone string) (*models.Todo, error) {
+	var todo models.Todo
+	return &todo, c.do(http.MethodPost, "/api/todos/quick", quickAddRequest{Text: text, Timezone: timezone}, &todo)
+}
+
+func (c *client) updateTodo(todo *models.Todo) error {
+	return c.do(http.MethodPut, "/api/todos/"+todo.Id, todo, nil)
+}
+
+func (c *client) deleteTodo(id string) error {
+	return c.do(http.MethodDelete, "/api/todos/"+id, nil, nil)
+}
+
+// findTodo resolves a full ID or a unique ID prefix, as printed by `todo ls`.
+func (c *client) findTodo(id string) (*models.Todo, error) {
+	todos, err := c.listTodos()
+	if err != nil {
+		return nil, err
+	}
+	var match *models.Todo
+	for i := range todos {
+		if todos[i].Id == id {
+			return &todos[i], nil
+		}
+		if strings.HasPrefix(todos[i].Id, id) {
+			if match != nil {
+				return nil, fmt.Errorf("id %q is ambiguous", id)
+			}
+			match = &todos[i]
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("todo %q not found", id)
+	}
+	return match, nil
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"todolist/internal/models"
+	"todolist/internal/quickadd"
+
+	"golang.org/x/term"
+)
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("todo "+name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
+
+func authedClient() (*client, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Token == "" {
+		return nil, errors.New("not logged in, run `todo login` first")
+	}
+	return newClient(cfg), nil
+}
+
+func runLogin(args []string) error {
+	fs := newFlagSet("login")
+	server := fs.String("server", "", "API base URL (default from config or "+defaultServer+")")
+	username := fs.String("u", "", "username or email")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if *server != "" {
+		cfg.Server = *server
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	if *username == "" {
+		fmt.Fprint(os.Stderr, "Username or email: ")
+		line, err := in.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		*username = strings.TrimSpace(line)
+	}
+	password, err := readPassword(in)
+	if err != nil {
+		return err
+	}
+
+	c := newClient(cfg)
+	resp, err := c.login(*username, password)
+	if err != nil {
+		return err
+	}
+	if resp.MFARequired {
+		fmt.Fprint(os.Stderr, "Authentication code (or recovery code): ")
+		line, err := in.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		resp, err = c.verifyTwoFactor(resp.MFAToken, strings.TrimSpace(line))
+		if err != nil {
+			return err
+		}
+	}
+	cfg.Token = resp.Token
+	cfg.RefreshToken = resp.RefreshToken
+	if err := cfg.save(); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Logged in to", cfg.Server)
+	return nil
+}
+
+// readPassword reads without echo on a terminal and falls back to a plain
+// line so that passwords can be piped in scripts.
+func readPassword(in *bufio.Reader) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, "Password: ")
+		password, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		return string(password), err
+	}
+	line, err := in.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func runLogout(args []string) error {
+	if _, err := parseArgs(newFlagSet("logout"), args); err != nil {
+		return err
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.Token != "" {
+		// Ending the session on the server is best effort; the local
+		// credentials are dropped either way.
+		if err := newClient(cfg).logout(); err != nil {
+			fmt.Fprintln(os.Stderr, "warning:", err)
+		}
+	}
+	cfg.Token = ""
+	cfg.RefreshToken = ""
+	return cfg.save()
+}
+
+func runAdd(args []string) error {
+	fs := newFlagSet("add")
+	due := fs.String("due", "", `deadline in words, e.g. "friday", "tomorrow at 9am", "2026-05-01"`)
+	output := fs.String("o", "table", "output format: table or json")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	text := strings.Join(positional, " ")
+	if text == "" {
+		return errors.New("usage: todo add <text> [--due <when>]")
+	}
+
+	if *due != "" {
+		// Check the phrase locally so a typo doesn't silently end up in the title.
+		parsed := quickadd.Parse(*due, time.Now())
+		if parsed.Deadline == nil || parsed.Title != "" {
+			return fmt.Errorf("cannot understand due date %q", *due)
+		}
+		text += " " + *due
+	}
+
+	c, err := authedClient()
+	if err != nil {
+		return err
+	}
+	todo, err := c.quickAdd(text, localTimezone())
+	if err != nil {
+		return err
+	}
+	return printTodos(*output, []models.Todo{*todo})
+}
+
+func runList(args []string) error {
+	fs := newFlagSet("ls")
+	all := fs.Bool("a", false, "include completed todos")
+	done := fs.Bool("done", false, "show only completed todos")
+	overdue := fs.Bool("overdue", false, "show only overdue todos")
+	tag := fs.String("tag", "", "show only todos with this tag")
+	output := fs.String("o", "table", "output format: table or json")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	c, err := authedClient()
+	if err != nil {
+		return err
+	}
+	todos, err := c.listTodos()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	filtered := make([]models.Todo, 0, len(todos))
+	for _, t := range todos {
+		switch {
+		case *done && !t.Completed:
+			continue
+		case !*done && !*all && t.Completed:
+			continue
+		case *overdue && (t.Completed || !t.Deadline.Before(now)):
+			continue
+		case *tag != "" && !slices.Contains(t.Tags, strings.TrimPrefix(*tag, "#")):
+			continue
+		}
+		filtered = append(filtered, t)
+	}
+	slices.SortStableFunc(filtered, func(a, b models.Todo) int {
+		return a.Deadline.Compare(b.Deadline)
+	})
+	return printTodos(*output, filtered)
+}
+
+func runSetCompleted(name string, args []string, completed bool) error {
+	ids, err := parseArgs(newFlagSet(name), args)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("usage: todo %s <id>...", name)
+	}
+	c, err := authedClient()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		todo, err := c.findTodo(id)
+		if err != nil {
+			return err
+		}
+		todo.Completed = completed
+		if err := c.updateTodo(todo); err != nil {
+			return err
+		}
+		fmt.Printf("%s %s\n", checkbox(completed), todo.Title)
+	}
+	return nil
+}
+
+func runRemove(args []string) error {
+	ids, err := parseArgs(newFlagSet("rm"), args)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return errors.New("usage: todo rm <id>...")
+	}
+	c, err := authedClient()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		todo, err := c.findTodo(id)
+		if err != nil {
+			return err
+		}
+		if err := c.deleteTodo(todo.Id); err != nil {
+			return err
+		}
+		fmt.Println("deleted", todo.Title)
+	}
+	return nil
+}
+
+// runIDs prints "<short id>\t<title>" lines for shell completion. Errors are
+// swallowed so that completion never prints garbage into the prompt.
+func runIDs(_ []string) error {
+	c, err := authedClient()
+	if err != nil {
+		return nil
+	}
+	todos, err := c.listTodos()
+	if err != nil {
+		return nil
+	}
+	for _, t := range todos {
+		fmt.Printf("%s\t%s\n", shortID(t.Id), t.Title)
+	}
+	return nil
+}
+
+func printTodos(format string, todos []models.Todo) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(todos)
+	case "table":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\t \tTITLE\tDUE\tPRIORITY\tTAGS")
+		for _, t := range todos {
+			tags := make([]string, len(t.Tags))
+			for i, tag := range t.Tags {
+				tags[i] = "#" + tag
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				shortID(t.Id), checkbox(t.Completed), t.Title, formatDue(t), t.Priority, strings.Join(tags, " "))
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+func checkbox(completed bool) string {
+	if completed {
+		return "[x]"
+	}
+	return "[ ]"
+}
+
+func formatDue(t models.Todo) string {
+	if t.Deadline.IsZero() {
+		return "-"
+	}
+	due := t.Deadline.Local().Format("Mon 02 Jan 15:04")
+	if !t.Completed && t.Deadline.Before(time.Now()) {
+		due += " (overdue)"
+	}
+	return due
+}
+
+// localTimezone returns the IANA name of the local zone so the server
+// resolves "friday" or "at 9am" the way the user means it.
+func localTimezone() string {
+	if tz := os.Getenv("TZ"); tz != "" {
+		return tz
+	}
+	if target, err := os.Readlink("/etc/localtime"); err == nil {
+		if _, name, ok := strings.Cut(target, "zoneinfo/"); ok {
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const bashCompletion = `_todo() {
+    local cur=${COMP_WORDS[COMP_CWORD]}
+    if [ "$COMP_CWORD" -eq 1 ]; then
+        COMPREPLY=($(compgen -W "login logout add ls done undo rm completion" -- "$cur"))
+        return
+    fi
+    case "${COMP_WORDS[1]}" in
+        done|undo|rm)
+            COMPREPLY=($(compgen -W "$(todo __ids 2>/dev/null | cut -f1)" -- "$cur")) ;;
+        ls)
+            COMPREPLY=($(compgen -W "-a -done -overdue -tag -o" -- "$cur")) ;;
+        add)
+            COMPREPLY=($(compgen -W "-due -o" -- "$cur")) ;;
+        login)
+            COMPREPLY=($(compgen -W "-server -u" -- "$cur")) ;;
+        completion)
+            COMPREPLY=($(compgen -W "bash zsh fish" -- "$cur")) ;;
+    esac
+}
+complete -F _todo todo
+`
+
+const zshCompletion = `#compdef todo
+
+_todo() {
+    local -a commands ids
+    commands=(
+        'login:Log in and store the token'
+        'logout:Forget the stored token'
+        'add:Create a todo'
+        'ls:List todos'
+        'done:Mark todos as completed'
+        'undo:Mark todos as not completed'
+        'rm:Delete todos'
+        'completion:Print a shell completion script'
+    )
+    if (( CURRENT == 2 )); then
+        _describe 'command' commands
+        return
+    fi
+    case $words[2] in
+        done|undo|rm)
+            ids=(${(f)"$(todo __ids 2>/dev/null | sed 's/\t/:/')"})
+            _describe 'todo' ids ;;
+        ls)
+            _values 'flag' -a -done -overdue -tag -o ;;
+        add)
+            _values 'flag' -due -o ;;
+        login)
+            _values 'flag' -server -u ;;
+        completion)
+            _values 'shell' bash zsh fish ;;
+    esac
+}
+
+compdef _todo todo
+`
+
+const fishCompletion = `complete -c todo -f
+complete -c todo -n __fish_use_subcommand -a login -d 'Log in and store the token'
+complete -c todo -n __fish_use_subcommand -a logout -d 'Forget the stored token'
+complete -c todo -n __fish_use_subcommand -a add -d 'Create a todo'
+complete -c todo -n __fish_use_subcommand -a ls -d 'List todos'
+complete -c todo -n __fish_use_subcommand -a done -d 'Mark todos as completed'
+complete -c todo -n __fish_use_subcommand -a undo -d 'Mark todos as not completed'
+complete -c todo -n __fish_use_subcommand -a rm -d 'Delete todos'
+complete -c todo -n __fish_use_subcommand -a completion -d 'Print a shell completion script'
+complete -c todo -n '__fish_seen_subcommand_from done undo rm' -a '(todo __ids 2>/dev/null)'
+complete -c todo -n '__fish_seen_subcommand_from ls' -a '-a -done -overdue -tag -o'
+complete -c todo -n '__fish_seen_subcommand_from add' -a '-due -o'
+complete -c todo -n '__fish_seen_subcommand_from login' -a '-server -u'
+complete -c todo -n '__fish_seen_subcommand_from completion' -a 'bash zsh fish'
+`
+
+func runCompletion(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: todo completion bash|zsh|fish")
+	}
+	scripts := map[string]string{
+		"bash": bashCompletion,
+		"zsh":  zshCompletion,
+		"fish": fishCompletion,
+	}
+	script, ok := scripts[args[0]]
+	if !ok {
+		return fmt.Errorf("unsupported shell %q", args[0])
+	}
+	_, err := fmt.Fprint(os.Stdout, script)
+	return err
+}
@@ -0,0 +1,86 @@
+// Command todo is a terminal client for the ToDoList REST API.
+//
+//	todo login
+//	todo add "fix bug #work" --due friday
+//	todo ls --overdue
+//	todo done <id>
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+const usage = `Usage: todo <command> [flags] [args]
+
+Commands:
+  login               Log in and store the token in the config file
+  logout              Forget the stored token
+  add <text>          Create a todo (supports #tags, !priority and dates in text)
+  ls                  List todos
+  done <id>...        Mark todos as completed
+  undo <id>...        Mark todos as not completed
+  rm <id>...          Delete todos
+  completion <shell>  Print a completion script for bash, zsh or fish
+
+IDs may be shortened to any unique prefix, as printed by "todo ls".
+Run "todo <command> -h" for command flags.
+
+Environment:
+  TODO_SERVER  API base URL (overrides the config file)
+  TODO_CONFIG  Config file path
+`
+
+var commands = map[string]func(args []string) error{
+	"login":      runLogin,
+	"logout":     runLogout,
+	"add":        runAdd,
+	"ls":         runList,
+	"done":       func(args []string) error { return runSetCompleted("done", args, true) },
+	"undo":       func(args []string) error { return runSetCompleted("undo", args, false) },
+	"rm":         runRemove,
+	"completion": runCompletion,
+	"__ids":      runIDs,
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "--help" || os.Args[1] == "help" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	run, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "todo: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if err := run(os.Args[2:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		fmt.Fprintln(os.Stderr, "todo:", err)
+		os.Exit(1)
+	}
+}
+
+// parseArgs parses flags that may appear before, between or after positional
+// arguments, so that `todo add "text" --due friday` works.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
@@ -1,4 +1,5 @@
 import { authState, setToken } from './auth.svelte';
+import { createPasskey, getPasskey } from './passkeys';
 
 const API_BASE = '';
 
@@ -11,9 +12,74 @@ export interface Todo {
     created_at: string;
     updated_at: string;
     deadline: string;
+    priority: '' | 'low' | 'medium' | 'high';
+    tags: string[];
+    recurrence: string;
+    shared_by?: string;
+    permission?: 'viewer' | 'editor';
+    comment_count?: number;
 }
 
-async function request(endpoint: string, options: RequestInit = {}) {
+export interface Passkey {
+    id: string;
+    name: string;
+    created_at: string;
+    last_used_at: string | null;
+}
+
+export interface AccessToken {
+    id: string;
+    name: string;
+    scopes: string[];
+    expires_at: string | null;
+    last_used_at: string | null;
+    created_at: string;
+}
+
+export interface UserIdentity {
+    id: string;
+    provider: string;
+    email: string;
+    created_at: string;
+    last_used_at: string | null;
+}
+
+export interface LoginProvider {
+    id: string;
+    name: string;
+}
+
+export interface TodoEvent {
+    type: 'todo.created' | 'todo.updated' | 'todo.completed' | 'todo.deleted';
+    todo: Todo;
+    occurred_at: string;
+}
+
+let refreshing: Promise<boolean> | null = null;
+
+// refreshTokens swaps the refresh token for a new pair. Concurrent callers
+// share one request because every refresh token can be used only once.
+function refreshTokens(): Promise<boolean> {
+    if (!refreshing) {
+        refreshing = (async () => {
+            if (!authState.refreshToken) return false;
+            const response = await fetch(`${API_BASE}/auth/refresh`, {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ refresh_token: authState.refreshToken })
+            });
+            if (!response.ok) return false;
+            const data = await response.json();
+            setToken(data.token, data.refresh_token);
+            return true;
+        })().catch(() => false).finally(() => {
+            refreshing = null;
+        });
+    }
+    return refreshing;
+}
+
+async function request(endpoint: string, options: RequestInit = {}, retry = true): Promise<any> {
     const headers: Record<string, string> = {
         'Content-Type': 'application/json',
         ...(options.headers as Record<string, string> || {})
@@ -28,7 +94,10 @@ async function request(endpoint: string, options: RequestInit = {}) {
         headers,
     });
 
-    if (response.status === 401) {
+    if (response.status === 401 && authState.token) {
+        if (retry && await refreshTokens()) {
+            return request(endpoint, options, false);
+        }
         setToken(null);
         throw new Error('Unauthorized');
     }
@@ -92,6 +161,91 @@ export const api = {
         });
         return res;
     },
+    verifyTwoFactor: async (mfa_token: string, code: string) => {
+        return request('/auth/2fa/verify', {
+            method: 'POST',
+            body: JSON.stringify({ mfa_token, code })
+        }, false);
+    },
+    passkeyLogin: async () => {
+        const begin = await request('/auth/webauthn/login/begin', { method: 'POST' }, false);
+        const credential = await getPasskey(begin.options);
+        return request('/auth/webauthn/login/finish', {
+            method: 'POST',
+            body: JSON.stringify({ challenge_id: begin.challenge_id, credential })
+        }, false);
+    },
+    addPasskey: async (name: string, password: string): Promise<Passkey> => {
+        const begin = await request('/auth/webauthn/register/begin', {
+            method: 'POST',
+            body: JSON.stringify({ password })
+        });
+        const credential = await createPasskey(begin.options);
+        return request('/auth/webauthn/register/finish', {
+            method: 'POST',
+            body: JSON.stringify({ challenge_id: begin.challenge_id, name, credential })
+        });
+    },
+    getPasskeys: async (): Promise<Passkey[]> => {
+        return request('/api/user/me/passkeys');
+    },
+    renamePasskey: async (id: string, name: string) => {
+        return request(`/api/user/me/passkeys/${id}`, {
+            method: 'PATCH',
+            body: JSON.stringify({ name })
+        });
+    },
+    deletePasskey: async (id: string) => {
+        return request(`/api/user/me/passkeys/${id}`, { method: 'DELETE' });
+    },
+    getAccessTokens: async (): Promise<AccessToken[]> => {
+        return request('/api/user/me/tokens');
+    },
+    createAccessToken: async (name: string, scopes: string[], expiresAt: string | null, password: string): Promise<AccessToken & { token: string }> => {
+        return request('/api/user/me/tokens', {
+            method: 'POST',
+            body: JSON.stringify({ name, scopes, expires_at: expiresAt, password })
+        });
+    },
+    revokeAccessToken: async (id: string) => {
+        return request(`/api/user/me/tokens/${id}`, { method: 'DELETE' });
+    },
+    getLoginProviders: async (): Promise<LoginProvider[]> => {
+        return request('/auth/oidc/providers', {}, false);
+    },
+    beginProviderLogin: async (provider: string) => {
+        return request(`/auth/oidc/${encodeURIComponent(provider)}/begin`, { method: 'POST' }, false);
+    },
+    finishProviderLogin: async (code: string, state: string) => {
+        return request('/auth/oidc/finish', {
+            method: 'POST',
+            body: JSON.stringify({ code, state })
+        }, false);
+    },
+    getIdentities: async (): Promise<UserIdentity[]> => {
+        return request('/api/user/me/identities');
+    },
+    beginLinkIdentity: async (provider: string, password: string) => {
+        return request(`/api/user/me/identities/${encodeURIComponent(provider)}/begin`, {
+            method: 'POST',
+            body: JSON.stringify({ password })
+        });
+    },
+    finishLinkIdentity: async (code: string, state: string): Promise<UserIdentity> => {
+        return request('/api/user/me/identities/finish', {
+            method: 'POST',
+            body: JSON.stringify({ code, state })
+        });
+    },
+    unlinkIdentity: async (id: string) => {
+        return request(`/api/user/me/identities/${id}`, { method: 'DELETE' });
+    },
+    removePassword: async (password: string) => {
+        return request('/api/user/me/password', {
+            method: 'DELETE',
+            body: JSON.stringify({ password })
+        });
+    },
     googleLogin: async (token: string) => {
         return request('/auth/google', {
             method: 'POST',
@@ -110,6 +264,15 @@ export const api = {
             body: JSON.stringify({ email, code })
         });
     },
+    resendVerification: async (email: string) => {
+        return request('/auth/verify/resend', {
+            method: 'POST',
+            body: JSON.stringify({ email })
+        });
+    },
+    logout: async () => {
+        return request('/auth/logout', { method: 'POST' }, false);
+    },
     getUser: async () => {
         return request('/api/user/me');
     },
@@ -137,6 +300,9 @@ export const api = {
             body: JSON.stringify({ code })
         });
     },
+    resendEmailUpdate: async () => {
+        return request('/api/user/me/email/resend', { method: 'POST' });
+    },
     requestDeleteUser: async (password: string) => {
         return request('/api/user/me', {
             method: 'DELETE',
@@ -168,5 +334,50 @@ export const api = {
         return request(`/api/todos/${id}`, {
             method: 'DELETE'
         });
+    },
+    // subscribeTodoEvents keeps a live event stream open until the returned
+    // function is called. EventSource can't send headers, so the access token
+    // goes in the URL: it is refreshed before every (re)connect, and a broken
+    // stream is reopened with a fresh token after an exponential backoff,
+    // resuming after the last event received.
+    subscribeTodoEvents: (onEvent: (event: TodoEvent) => void): (() => void) => {
+        let source: EventSource | null = null;
+        let timer: ReturnType<typeof setTimeout> | null = null;
+        let lastEventId = '';
+        let attempt = 0;
+        let stopped = false;
+
+        const connect = async () => {
+            await refreshTokens();
+            if (stopped || !authState.token) return;
+            let url = `${API_BASE}/api/events?access_token=${encodeURIComponent(authState.token)}`;
+            if (lastEventId) url += `&last_event_id=${encodeURIComponent(lastEventId)}`;
+            source = new EventSource(url);
+            for (const type of ['todo.created', 'todo.updated', 'todo.completed', 'todo.deleted']) {
+                source.addEventListener(type, (e) => {
+                    const message = e as MessageEvent;
+                    if (message.lastEventId) lastEventId = message.lastEventId;
+                    onEvent(JSON.parse(message.data));
+                });
+            }
+            source.onopen = () => {
+                attempt = 0;
+            };
+            source.onerror = () => {
+                source?.close();
+                source = null;
+                if (stopped) return;
+                const delay = Math.min(1000 * 2 ** attempt, 30000);
+                attempt++;
+                timer = setTimeout(connect, delay);
+            };
+        };
+        connect();
+
+        return () => {
+            stopped = true;
+            if (timer) clearTimeout(timer);
+            source?.close();
+        };
     }
 };
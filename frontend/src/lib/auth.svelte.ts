@@ -1,9 +1,10 @@
 export const authState = $state({
     token: localStorage.getItem('token') || null,
+    refreshToken: localStorage.getItem('refreshToken') || null,
     isAuthenticated: !!localStorage.getItem('token'),
 });
 
-export function setToken(token: string | null) {
+export function setToken(token: string | null, refreshToken: string | null = null) {
     if (token) {
         localStorage.setItem('token', token);
         authState.token = token;
@@ -13,4 +14,11 @@ export function setToken(token: string | null) {
         authState.token = null;
         authState.isAuthenticated = false;
     }
+    if (token && refreshToken) {
+        localStorage.setItem('refreshToken', refreshToken);
+        authState.refreshToken = refreshToken;
+    } else if (!token) {
+        localStorage.removeItem('refreshToken');
+        authState.refreshToken = null;
+    }
 }
@@ -0,0 +1,64 @@
+// WebAuthn sends binary fields as base64url strings in JSON while the browser
+// API works with ArrayBuffers; these helpers convert between the two.
+
+function fromBase64url(value: string): ArrayBuffer {
+    const base64 = value.replace(/-/g, '+').replace(/_/g, '/');
+    const padded = base64 + '='.repeat((4 - base64.length % 4) % 4);
+    const binary = atob(padded);
+    const bytes = new Uint8Array(binary.length);
+    for (let i = 0; i < binary.length; i++) bytes[i] = binary.charCodeAt(i);
+    return bytes.buffer;
+}
+
+function toBase64url(buffer: ArrayBuffer | null): string | undefined {
+    if (!buffer) return undefined;
+    let binary = '';
+    for (const byte of new Uint8Array(buffer)) binary += String.fromCharCode(byte);
+    return btoa(binary).replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, '');
+}
+
+export const passkeysSupported = () => typeof window !== 'undefined' && !!window.PublicKeyCredential;
+
+// createPasskey runs the browser side of registration for the options from
+// /auth/webauthn/register/begin and returns the credential to send back.
+export async function createPasskey(options: any): Promise<any> {
+    const publicKey = options.publicKey;
+    publicKey.challenge = fromBase64url(publicKey.challenge);
+    publicKey.user.id = fromBase64url(publicKey.user.id);
+    for (const cred of publicKey.excludeCredentials ?? []) cred.id = fromBase64url(cred.id);
+
+    const credential = await navigator.credentials.create({ publicKey }) as PublicKeyCredential;
+    const response = credential.response as AuthenticatorAttestationResponse;
+    return {
+        id: credential.id,
+        rawId: toBase64url(credential.rawId),
+        type: credential.type,
+        response: {
+            clientDataJSON: toBase64url(response.clientDataJSON),
+            attestationObject: toBase64url(response.attestationObject),
+            transports: response.getTransports?.() ?? []
+        }
+    };
+}
+
+// getPasskey runs the browser side of login for the options from
+// /auth/webauthn/login/begin and returns the assertion to send back.
+export async function getPasskey(options: any): Promise<any> {
+    const publicKey = options.publicKey;
+    publicKey.challenge = fromBase64url(publicKey.challenge);
+    for (const cred of publicKey.allowCredentials ?? []) cred.id = fromBase64url(cred.id);
+
+    const credential = await navigator.credentials.get({ publicKey }) as PublicKeyCredential;
+    const response = credential.response as AuthenticatorAssertionResponse;
+    return {
+        id: credential.id,
+        rawId: toBase64url(credential.rawId),
+        type: credential.type,
+        response: {
+            clientDataJSON: toBase64url(response.clientDataJSON),
+            authenticatorData: toBase64url(response.authenticatorData),
+            signature: toBase64url(response.signature),
+            userHandle: toBase64url(response.userHandle)
+        }
+    };
+}
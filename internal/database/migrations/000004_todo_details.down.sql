@@ -0,0 +1,3 @@
+ALTER TABLE todos DROP COLUMN IF EXISTS recurrence;
+ALTER TABLE todos DROP COLUMN IF EXISTS tags;
+ALTER TABLE todos DROP COLUMN IF EXISTS priority;
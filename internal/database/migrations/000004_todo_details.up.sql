@@ -0,0 +1,3 @@
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS priority TEXT NOT NULL DEFAULT '';
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS recurrence TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS chat_link_codes;
+DROP TABLE IF EXISTS chat_links;
\ No newline at end of file
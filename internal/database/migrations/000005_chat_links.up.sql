@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS chat_links (
+    provider TEXT NOT NULL,
+    team_id TEXT NOT NULL,
+    chat_user_id TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider, team_id, chat_user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_chat_links_user_id ON chat_links(user_id);
+
+CREATE TABLE IF NOT EXISTS chat_link_codes (
+    code TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS todo_template_items;
+DROP TABLE IF EXISTS todo_templates;
\ No newline at end of file
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS todo_templates (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_templates_user_id ON todo_templates(user_id);
+
+CREATE TABLE IF NOT EXISTS todo_template_items (
+    id TEXT PRIMARY KEY,
+    template_id TEXT NOT NULL REFERENCES todo_templates(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    deadline_offset TEXT NOT NULL,
+    priority TEXT NOT NULL DEFAULT '',
+    tags TEXT[] NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_template_items_template_id ON todo_template_items(template_id);
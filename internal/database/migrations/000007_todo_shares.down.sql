@@ -0,0 +1 @@
+DROP TABLE IF EXISTS todo_shares;
\ No newline at end of file
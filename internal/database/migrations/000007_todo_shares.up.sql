@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS todo_shares (
+    id TEXT PRIMARY KEY,
+    owner_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    todo_id TEXT REFERENCES todos(id) ON DELETE CASCADE,
+    grantee_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('viewer', 'editor')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- todo_id NULL shares the owner's whole list.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_todo_shares_todo_grantee ON todo_shares(todo_id, grantee_id) WHERE todo_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_todo_shares_list_grantee ON todo_shares(owner_id, grantee_id) WHERE todo_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_todo_shares_grantee_id ON todo_shares(grantee_id);
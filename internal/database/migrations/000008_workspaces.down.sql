@@ -0,0 +1,4 @@
+ALTER TABLE todos DROP COLUMN IF EXISTS workspace_id;
+ALTER TABLE users DROP COLUMN IF EXISTS active_workspace_id;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
\ No newline at end of file
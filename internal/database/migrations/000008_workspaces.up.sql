@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS workspaces (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    -- Set only for the personal workspace every user gets; it cannot be shared or deleted.
+    personal_owner_id TEXT UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace_id TEXT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('owner', 'admin', 'member')),
+    joined_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspace_members_user_id ON workspace_members(user_id);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS active_workspace_id TEXT REFERENCES workspaces(id) ON DELETE SET NULL;
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS workspace_id TEXT REFERENCES workspaces(id) ON DELETE CASCADE;
+
+INSERT INTO workspaces (id, name, personal_owner_id)
+SELECT gen_random_uuid()::text, 'Personal', id FROM users;
+
+INSERT INTO workspace_members (workspace_id, user_id, role)
+SELECT id, personal_owner_id, 'owner' FROM workspaces WHERE personal_owner_id IS NOT NULL;
+
+UPDATE users u SET active_workspace_id = w.id FROM workspaces w WHERE w.personal_owner_id = u.id;
+UPDATE todos t SET workspace_id = w.id FROM workspaces w WHERE w.personal_owner_id = t.user_id;
+
+ALTER TABLE todos ALTER COLUMN workspace_id SET NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_todos_workspace_id ON todos(workspace_id);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS todo_comments;
\ No newline at end of file
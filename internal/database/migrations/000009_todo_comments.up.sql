@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS todo_comments (
+    id TEXT PRIMARY KEY,
+    todo_id TEXT NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_comments_todo_id ON todo_comments(todo_id);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS todo_attachments (
+    id TEXT PRIMARY KEY,
+    todo_id TEXT NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    filename TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_attachments_todo_id ON todo_attachments(todo_id);
+CREATE INDEX IF NOT EXISTS idx_todo_attachments_user_id ON todo_attachments(user_id);
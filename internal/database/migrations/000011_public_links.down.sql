@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public_link_todos;
+DROP TABLE IF EXISTS public_links;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS public_links (
+    id TEXT PRIMARY KEY,
+    owner_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_public_links_owner_id ON public_links(owner_id);
+
+CREATE TABLE IF NOT EXISTS public_link_todos (
+    link_id TEXT NOT NULL REFERENCES public_links(id) ON DELETE CASCADE,
+    todo_id TEXT NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    PRIMARY KEY (link_id, todo_id)
+);
@@ -0,0 +1,10 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS pending_email TEXT DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS verification_code TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS verification_code_expires TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT '1970-01-01';
+
+-- Codes are stored hashed and cannot be restored; only the pending email is.
+UPDATE users u SET pending_email = t.email
+FROM verification_tokens t
+WHERE t.user_id = u.id AND t.purpose = 'email_change' AND t.used_at IS NULL;
+
+DROP TABLE IF EXISTS verification_tokens;
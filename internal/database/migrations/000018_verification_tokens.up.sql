@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS verification_tokens (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose TEXT NOT NULL,
+    code_hash TEXT NOT NULL,
+    email TEXT NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, purpose)
+);
+
+-- Carry over codes that are still valid. The purpose follows from the user's
+-- state: unverified accounts wait for registration, a pending email for an
+-- email change, anything else for account deletion. These rows keep a plain
+-- SHA-256 hash; the service still accepts it for codes until they expire.
+INSERT INTO verification_tokens (id, user_id, purpose, code_hash, email, expires_at)
+SELECT gen_random_uuid()::text,
+       id,
+       CASE
+           WHEN NOT is_verified THEN 'register'
+           WHEN COALESCE(pending_email, '') <> '' THEN 'email_change'
+           ELSE 'account_delete'
+       END,
+       encode(sha256(convert_to(verification_code, 'UTF8')), 'hex'),
+       CASE
+           WHEN is_verified AND COALESCE(pending_email, '') <> '' THEN pending_email
+           ELSE email
+       END,
+       verification_code_expires
+FROM users
+WHERE COALESCE(verification_code, '') <> '' AND verification_code_expires > NOW();
+
+ALTER TABLE users DROP COLUMN IF EXISTS verification_code;
+ALTER TABLE users DROP COLUMN IF EXISTS verification_code_expires;
+ALTER TABLE users DROP COLUMN IF EXISTS pending_email;
@@ -0,0 +1,15 @@
+ALTER TABLE oidc_states DROP COLUMN IF EXISTS user_id;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS oauth_provider TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS oauth_id TEXT;
+
+-- Only one identity per user fits back into users; the oldest is kept.
+UPDATE users u SET oauth_provider = i.provider, oauth_id = i.subject
+FROM (
+    SELECT DISTINCT ON (user_id) user_id, provider, subject
+    FROM user_identities
+    ORDER BY user_id, created_at
+) i
+WHERE i.user_id = u.id;
+
+DROP TABLE IF EXISTS user_identities;
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS user_identities (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    email TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id);
+
+INSERT INTO user_identities (id, user_id, provider, subject, email)
+SELECT gen_random_uuid()::text, id, oauth_provider, oauth_id, email
+FROM users
+WHERE COALESCE(oauth_provider, '') <> '' AND COALESCE(oauth_id, '') <> ''
+ON CONFLICT (provider, subject) DO NOTHING;
+
+ALTER TABLE users DROP COLUMN IF EXISTS oauth_provider;
+ALTER TABLE users DROP COLUMN IF EXISTS oauth_id;
+
+-- Set when a signed-in user links a provider rather than logging in with it.
+ALTER TABLE oidc_states ADD COLUMN IF NOT EXISTS user_id TEXT REFERENCES users(id) ON DELETE CASCADE;
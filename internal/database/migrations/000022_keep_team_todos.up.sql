@@ -0,0 +1,5 @@
+-- Deleting an account must not take the todos it created in team workspaces
+-- with it. The application hands them to another owner first; without the
+-- cascade, a todo that is left behind makes the deletion fail instead.
+ALTER TABLE todos DROP CONSTRAINT IF EXISTS todos_user_id_fkey;
+ALTER TABLE todos ADD CONSTRAINT todos_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id);
@@ -0,0 +1,105 @@
+// Package graph exposes TodoService and UserService through a GraphQL schema
+// so that clients can fetch the user, todos and aggregates in one request.
+package graph
+
+import (
+	"context"
+	"todolist/internal/models"
+	"todolist/internal/service"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+const (
+	MaxDepth       = 8
+	MaxComplexity  = 2000
+	MaxQueryLength = 10000
+)
+
+type contextKey struct{}
+
+type sessionContextKey struct{}
+
+type clientContextKey struct{}
+
+func userID(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+func sessionID(ctx context.Context) string {
+	id, _ := ctx.Value(sessionContextKey{}).(string)
+	return id
+}
+
+func sessionClient(ctx context.Context) models.SessionClient {
+	client, _ := ctx.Value(clientContextKey{}).(models.SessionClient)
+	return client
+}
+
+type API struct {
+	ts     *service.TodoService
+	us     *service.UserService
+	schema graphql.Schema
+}
+
+type Request struct {
+	Query         string         `json:"query" binding:"required"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+func New(ts *service.TodoService, us *service.UserService) (*API, error) {
+	a := &API{ts: ts, us: us}
+	schema, err := a.buildSchema()
+	if err != nil {
+		return nil, err
+	}
+	a.schema = schema
+	return a, nil
+}
+
+// Execute runs a request on behalf of the authenticated user and session
+// after checking its size, depth and complexity.
+func (a *API) Execute(ctx context.Context, userId, sessionId string, client models.SessionClient, req Request) *graphql.Result {
+	if len(req.Query) > MaxQueryLength {
+		return errorResult(gqlerrors.NewFormattedError("query is too long"))
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	validation := graphql.ValidateDocument(&a.schema, doc, nil)
+	if !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}
+	}
+
+	if err := checkLimits(doc, req.OperationName, req.Variables, MaxDepth, MaxComplexity); err != nil {
+		return errorResult(gqlerrors.NewFormattedError(err.Error()))
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        a.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       withCaller(ctx, userId, sessionId, client),
+	})
+}
+
+func withCaller(ctx context.Context, userId, sessionId string, client models.SessionClient) context.Context {
+	ctx = context.WithValue(ctx, contextKey{}, userId)
+	ctx = context.WithValue(ctx, sessionContextKey{}, sessionId)
+	return context.WithValue(ctx, clientContextKey{}, client)
+}
+
+func errorResult(err gqlerrors.FormattedError) *graphql.Result {
+	return &graphql.Result{Errors: []gqlerrors.FormattedError{err}}
+}
@@ -0,0 +1,125 @@
+package graph
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// listFields are fields that return lists; their child cost is multiplied by
+// the requested limit (or defaultListSize when no limit is given).
+var listFields = map[string]bool{
+	"todos": true,
+}
+
+const defaultListSize = 50
+
+type limitChecker struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]any
+	maxDepth  int
+}
+
+// checkLimits rejects operations that nest deeper than maxDepth or whose
+// estimated cost exceeds maxComplexity. Every field costs 1; fields under a
+// list are counted once per expected item.
+func checkLimits(doc *ast.Document, operationName string, variables map[string]any, maxDepth, maxComplexity int) error {
+	lc := &limitChecker{
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+		maxDepth:  maxDepth,
+	}
+	var operations []*ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch d := def.(type) {
+		case *ast.FragmentDefinition:
+			lc.fragments[d.Name.Value] = d
+		case *ast.OperationDefinition:
+			if operationName == "" || (d.Name != nil && d.Name.Value == operationName) {
+				operations = append(operations, d)
+			}
+		}
+	}
+
+	for _, op := range operations {
+		cost, err := lc.selectionCost(op.SelectionSet, 1, map[string]bool{})
+		if err != nil {
+			return err
+		}
+		if cost > maxComplexity {
+			return fmt.Errorf("query complexity %d exceeds limit of %d", cost, maxComplexity)
+		}
+	}
+	return nil
+}
+
+func (lc *limitChecker) selectionCost(set *ast.SelectionSet, depth int, visiting map[string]bool) (int, error) {
+	if set == nil {
+		return 0, nil
+	}
+	if depth > lc.maxDepth {
+		return 0, fmt.Errorf("query depth exceeds limit of %d", lc.maxDepth)
+	}
+
+	total := 0
+	for _, selection := range set.Selections {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			childCost, err := lc.selectionCost(sel.SelectionSet, depth+1, visiting)
+			if err != nil {
+				return 0, err
+			}
+			if listFields[sel.Name.Value] {
+				childCost *= lc.listSize(sel.Arguments)
+			}
+			total += 1 + childCost
+		case *ast.InlineFragment:
+			cost, err := lc.selectionCost(sel.SelectionSet, depth, visiting)
+			if err != nil {
+				return 0, err
+			}
+			total += cost
+		case *ast.FragmentSpread:
+			name := sel.Name.Value
+			fragment, ok := lc.fragments[name]
+			if !ok || visiting[name] {
+				continue
+			}
+			visiting[name] = true
+			cost, err := lc.selectionCost(fragment.SelectionSet, depth, visiting)
+			delete(visiting, name)
+			if err != nil {
+				return 0, err
+			}
+			total += cost
+		}
+	}
+	return total, nil
+}
+
+func (lc *limitChecker) listSize(args []*ast.Argument) int {
+	for _, arg := range args {
+		if arg.Name.Value != "limit" {
+			continue
+		}
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(v.Value); err == nil && n > 0 {
+				return n
+			}
+		case *ast.Variable:
+			switch n := lc.variables[v.Name.Value].(type) {
+			case float64:
+				if n > 0 {
+					return int(n)
+				}
+			case int:
+				if n > 0 {
+					return n
+				}
+			}
+		}
+	}
+	return defaultListSize
+}
@@ -0,0 +1,353 @@
+package graph
+
+import (
+	"errors"
+	"time"
+	"todolist/internal/models"
+	"todolist/internal/service"
+
+	"github.com/graphql-go/graphql"
+)
+
+func (a *API) buildSchema() (graphql.Schema, error) {
+	todoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Todo",
+		Fields: graphql.Fields{
+			"id":          todoField(graphql.NewNonNull(graphql.ID), func(t *models.Todo) any { return t.Id }),
+			"title":       todoField(graphql.NewNonNull(graphql.String), func(t *models.Todo) any { return t.Title }),
+			"description": todoField(graphql.NewNonNull(graphql.String), func(t *models.Todo) any { return t.Description }),
+			"completed":   todoField(graphql.NewNonNull(graphql.Boolean), func(t *models.Todo) any { return t.Completed }),
+			"createdAt":   todoField(graphql.NewNonNull(graphql.DateTime), func(t *models.Todo) any { return t.CreatedAt }),
+			"updatedAt":   todoField(graphql.NewNonNull(graphql.DateTime), func(t *models.Todo) any { return t.UpdatedAt }),
+			"deadline":    todoField(graphql.NewNonNull(graphql.DateTime), func(t *models.Todo) any { return t.Deadline }),
+			"priority":    todoField(graphql.NewNonNull(graphql.String), func(t *models.Todo) any { return t.Priority }),
+			"tags":        todoField(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))), func(t *models.Todo) any { return []string(t.Tags) }),
+			"recurrence":  todoField(graphql.NewNonNull(graphql.String), func(t *models.Todo) any { return t.Recurrence }),
+		},
+	})
+
+	statsType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TodoStats",
+		Fields: graphql.Fields{
+			"total":     statsField(func(s *service.TodoStats) int { return s.Total }),
+			"completed": statsField(func(s *service.TodoStats) int { return s.Completed }),
+			"pending":   statsField(func(s *service.TodoStats) int { return s.Pending }),
+			"overdue":   statsField(func(s *service.TodoStats) int { return s.Overdue }),
+			"dueToday":  statsField(func(s *service.TodoStats) int { return s.DueToday }),
+		},
+	})
+
+	filterType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "TodoFilter",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"completed": &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+			"overdue":   &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+			"tag":       &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"priority":  &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"search":    &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"dueBefore": &graphql.InputObjectFieldConfig{Type: graphql.DateTime},
+			"dueAfter":  &graphql.InputObjectFieldConfig{Type: graphql.DateTime},
+		},
+	})
+
+	todosField := &graphql.Field{
+		Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(todoType))),
+		Args: graphql.FieldConfigArgument{
+			"filter": &graphql.ArgumentConfig{Type: filterType},
+			"limit":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultListSize},
+			"offset": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+		},
+		Resolve: a.resolveTodos,
+	}
+	statsField := &graphql.Field{
+		Type: graphql.NewNonNull(statsType),
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return a.ts.GetStats(userID(p.Context))
+		},
+	}
+
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id":            userField(graphql.NewNonNull(graphql.ID), func(u *models.User) any { return u.Id }),
+			"username":      userField(graphql.NewNonNull(graphql.String), func(u *models.User) any { return u.Username }),
+			"email":         userField(graphql.NewNonNull(graphql.String), func(u *models.User) any { return u.Email }),
+			"pendingEmail":  userField(graphql.NewNonNull(graphql.String), func(u *models.User) any { return u.PendingEmail }),
+			"oauthProvider": userField(graphql.NewNonNull(graphql.String), func(u *models.User) any { return u.OauthProvider }),
+			"hasPassword":   userField(graphql.NewNonNull(graphql.Boolean), func(u *models.User) any { return u.PasswordHash != "" }),
+			"todos":         todosField,
+			"stats":         statsField,
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"me": &graphql.Field{
+				Type: graphql.NewNonNull(userType),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return a.us.GetUserByID(userID(p.Context))
+				},
+			},
+			"todo": &graphql.Field{
+				Type: todoType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					todo, err := a.ts.GetTodo(userID(p.Context), p.Args["id"].(string))
+					if err != nil {
+						return nil, nil
+					}
+					return todo, nil
+				},
+			},
+			"todos": todosField,
+			"stats": statsField,
+		},
+	})
+
+	createTodoInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "CreateTodoInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title":       &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"description": &graphql.InputObjectFieldConfig{Type: graphql.String, DefaultValue: ""},
+			"deadline":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.DateTime)},
+		},
+	})
+	updateTodoInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "UpdateTodoInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title":       &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"description": &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"completed":   &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+			"deadline":    &graphql.InputObjectFieldConfig{Type: graphql.DateTime},
+			"priority":    &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"tags":        &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+			"recurrence":  &graphql.InputObjectFieldConfig{Type: graphql.String},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createTodo": &graphql.Field{
+				Type: graphql.NewNonNull(todoType),
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(createTodoInput)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					input := p.Args["input"].(map[string]any)
+					return a.ts.CreateTodo(userID(p.Context), input["title"].(string), input["description"].(string), input["deadline"].(time.Time))
+				},
+			},
+			"quickAddTodo": &graphql.Field{
+				Type: graphql.NewNonNull(todoType),
+				Args: graphql.FieldConfigArgument{
+					"text":     &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"timezone": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					loc := time.UTC
+					if tz, ok := p.Args["timezone"].(string); ok && tz != "" {
+						var err error
+						if loc, err = time.LoadLocation(tz); err != nil {
+							return nil, errors.New("unknown timezone")
+						}
+					}
+					return a.ts.QuickAddTodo(userID(p.Context), p.Args["text"].(string), loc)
+				},
+			},
+			"updateTodo": &graphql.Field{
+				Type: graphql.NewNonNull(todoType),
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(updateTodoInput)},
+				},
+				Resolve: a.resolveUpdateTodo,
+			},
+			"deleteTodo": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					err := a.ts.DeleteTodo(userID(p.Context), p.Args["id"].(string))
+					return err == nil, err
+				},
+			},
+			"updateUsername": &graphql.Field{
+				Type: graphql.NewNonNull(userType),
+				Args: graphql.FieldConfigArgument{
+					"username": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					username := p.Args["username"].(string)
+					if len(username) < 3 {
+						return nil, errors.New("username must be at least 3 characters long")
+					}
+					if err := a.us.UpdateUsername(userID(p.Context), username); err != nil {
+						return nil, err
+					}
+					return a.us.GetUserByID(userID(p.Context))
+				},
+			},
+			"updatePassword": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"oldPassword": &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: ""},
+					"newPassword": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					newPassword := p.Args["newPassword"].(string)
+					if len(newPassword) < 8 {
+						return nil, errors.New("new password must be at least 8 characters")
+					}
+					err := a.us.UpdatePassword(userID(p.Context), sessionID(p.Context), p.Args["oldPassword"].(string), newPassword, sessionClient(p.Context))
+					return err == nil, err
+				},
+			},
+			"requestEmailUpdate": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"email": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					err := a.us.RequestEmailUpdate(userID(p.Context), p.Args["email"].(string))
+					return err == nil, err
+				},
+			},
+			"verifyEmailUpdate": &graphql.Field{
+				Type: graphql.NewNonNull(userType),
+				Args: graphql.FieldConfigArgument{
+					"code": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					if err := a.us.VerifyEmailUpdate(userID(p.Context), p.Args["code"].(string)); err != nil {
+						return nil, err
+					}
+					return a.us.GetUserByID(userID(p.Context))
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+func todoField(t graphql.Output, get func(*models.Todo) any) *graphql.Field {
+	return &graphql.Field{
+		Type: t,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			switch todo := p.Source.(type) {
+			case *models.Todo:
+				return get(todo), nil
+			case models.Todo:
+				return get(&todo), nil
+			}
+			return nil, nil
+		},
+	}
+}
+
+func userField(t graphql.Output, get func(*models.User) any) *graphql.Field {
+	return &graphql.Field{
+		Type: t,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			if user, ok := p.Source.(*models.User); ok {
+				return get(user), nil
+			}
+			return nil, nil
+		},
+	}
+}
+
+func statsField(get func(*service.TodoStats) int) *graphql.Field {
+	return &graphql.Field{
+		Type: graphql.NewNonNull(graphql.Int),
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			if stats, ok := p.Source.(*service.TodoStats); ok {
+				return get(stats), nil
+			}
+			return nil, nil
+		},
+	}
+}
+
+func (a *API) resolveTodos(p graphql.ResolveParams) (any, error) {
+	var filter service.TodoFilter
+	if raw, ok := p.Args["filter"].(map[string]any); ok {
+		if v, ok := raw["completed"].(bool); ok {
+			filter.Completed = &v
+		}
+		filter.Overdue, _ = raw["overdue"].(bool)
+		filter.Tag, _ = raw["tag"].(string)
+		filter.Priority, _ = raw["priority"].(string)
+		filter.Search, _ = raw["search"].(string)
+		if v, ok := raw["dueBefore"].(time.Time); ok {
+			filter.DueBefore = &v
+		}
+		if v, ok := raw["dueAfter"].(time.Time); ok {
+			filter.DueAfter = &v
+		}
+	}
+
+	todos, err := a.ts.FindTodos(userID(p.Context), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	offset, _ := p.Args["offset"].(int)
+	limit, _ := p.Args["limit"].(int)
+	if offset < 0 || limit < 0 {
+		return nil, errors.New("limit and offset must not be negative")
+	}
+	if offset > len(todos) {
+		offset = len(todos)
+	}
+	todos = todos[offset:]
+	if limit < len(todos) {
+		todos = todos[:limit]
+	}
+	return todos, nil
+}
+
+func (a *API) resolveUpdateTodo(p graphql.ResolveParams) (any, error) {
+	userId := userID(p.Context)
+	todo, err := a.ts.GetTodo(userId, p.Args["id"].(string))
+	if err != nil {
+		return nil, err
+	}
+
+	input := p.Args["input"].(map[string]any)
+	if v, ok := input["title"].(string); ok {
+		todo.Title = v
+	}
+	if v, ok := input["description"].(string); ok {
+		todo.Description = v
+	}
+	if v, ok := input["completed"].(bool); ok {
+		todo.Completed = v
+	}
+	if v, ok := input["deadline"].(time.Time); ok {
+		todo.Deadline = v
+	}
+	if v, ok := input["priority"].(string); ok {
+		todo.Priority = v
+	}
+	if v, ok := input["recurrence"].(string); ok {
+		todo.Recurrence = v
+	}
+	if v, ok := input["tags"].([]any); ok {
+		tags := make([]string, 0, len(v))
+		for _, tag := range v {
+			tags = append(tags, tag.(string))
+		}
+		todo.Tags = tags
+	}
+
+	if err := a.ts.UpdateTodo(userId, todo); err != nil {
+		return nil, err
+	}
+	return todo, nil
+}
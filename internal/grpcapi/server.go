@@ -0,0 +1,138 @@
+// Package grpcapi serves the todolist.v1 gRPC services on top of the same
+// TodoService and UserService used by the REST handlers.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"todolist/internal/models"
+	"todolist/internal/service"
+	"todolist/internal/utils"
+
+	pb "todolist/internal/pb/todolist/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type contextKey struct{}
+
+type sessionContextKey struct{}
+
+type clientContextKey struct{}
+
+func userID(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+func sessionID(ctx context.Context) string {
+	id, _ := ctx.Value(sessionContextKey{}).(string)
+	return id
+}
+
+func sessionClient(ctx context.Context) models.SessionClient {
+	client, _ := ctx.Value(clientContextKey{}).(models.SessionClient)
+	return client
+}
+
+// TokenAuthenticator validates an access token and the session behind it.
+type TokenAuthenticator interface {
+	Authenticate(token, ip string) (*utils.Claims, error)
+}
+
+// NewServer builds a gRPC server with JWT authentication on every call.
+// Extra options such as TLS credentials are passed through.
+func NewServer(ts *service.TodoService, us *service.UserService, tokens TokenAuthenticator, opts ...grpc.ServerOption) *grpc.Server {
+	auth := &authenticator{tokens: tokens}
+	server := grpc.NewServer(append([]grpc.ServerOption{
+		grpc.UnaryInterceptor(auth.unary),
+		grpc.StreamInterceptor(auth.stream),
+	}, opts...)...)
+	pb.RegisterTodoServiceServer(server, &todoServer{ts: ts})
+	pb.RegisterUserServiceServer(server, &userServer{us: us})
+	return server
+}
+
+type authenticator struct {
+	tokens TokenAuthenticator
+}
+
+func (a *authenticator) authenticate(ctx context.Context) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md.Get("authorization")) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata required")
+	}
+	token := strings.TrimPrefix(md.Get("authorization")[0], "Bearer ")
+	ip := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			ip = host
+		}
+	}
+	claims, err := a.tokens.Authenticate(token, ip)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	ctx = context.WithValue(ctx, contextKey{}, claims.UserID)
+	ctx = context.WithValue(ctx, clientContextKey{}, models.SessionClient{IP: ip})
+	return context.WithValue(ctx, sessionContextKey{}, claims.SessionID), nil
+}
+
+func (a *authenticator) unary(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := a.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (a *authenticator) stream(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+// toStatus maps service errors onto gRPC codes. Database errors are hidden
+// behind codes.Internal, everything else is a caller error.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	var s interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &s) {
+		return err
+	}
+	var tooMany *service.TooManyAttemptsError
+	if errors.As(err, &tooMany) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	msg := err.Error()
+	lower := strings.ToLower(msg)
+	switch {
+	case errors.Is(err, service.ErrReauthRequired):
+		return status.Error(codes.PermissionDenied, msg)
+	case strings.Contains(lower, "not found"), strings.Contains(lower, "no rows"):
+		return status.Error(codes.NotFound, msg)
+	case strings.Contains(lower, "pq:"), strings.Contains(lower, "sql:"):
+		return status.Error(codes.Internal, "database request failed")
+	default:
+		return status.Error(codes.InvalidArgument, msg)
+	}
+}
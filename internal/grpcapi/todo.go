@@ -0,0 +1,152 @@
+package grpcapi
+
+import (
+	"context"
+	"time"
+	"todolist/internal/models"
+	"todolist/internal/service"
+
+	pb "todolist/internal/pb/todolist/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type todoServer struct {
+	pb.UnimplementedTodoServiceServer
+	ts *service.TodoService
+}
+
+func toProtoTodo(t *models.Todo) *pb.Todo {
+	return &pb.Todo{
+		Id:          t.Id,
+		Title:       t.Title,
+		Description: t.Description,
+		Completed:   t.Completed,
+		CreatedAt:   timestamppb.New(t.CreatedAt),
+		UpdatedAt:   timestamppb.New(t.UpdatedAt),
+		Deadline:    timestamppb.New(t.Deadline),
+		Priority:    t.Priority,
+		Tags:        t.Tags,
+		Recurrence:  t.Recurrence,
+	}
+}
+
+func (s *todoServer) CreateTodo(ctx context.Context, req *pb.CreateTodoRequest) (*pb.Todo, error) {
+	if req.GetDeadline() == nil {
+		return nil, status.Error(codes.InvalidArgument, "deadline is required")
+	}
+	todo, err := s.ts.CreateTodo(userID(ctx), req.GetTitle(), req.GetDescription(), req.GetDeadline().AsTime())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoTodo(todo), nil
+}
+
+func (s *todoServer) QuickAddTodo(ctx context.Context, req *pb.QuickAddTodoRequest) (*pb.Todo, error) {
+	loc := time.UTC
+	if req.GetTimezone() != "" {
+		var err error
+		if loc, err = time.LoadLocation(req.GetTimezone()); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "unknown timezone")
+		}
+	}
+	todo, err := s.ts.QuickAddTodo(userID(ctx), req.GetText(), loc)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoTodo(todo), nil
+}
+
+func (s *todoServer) GetTodo(ctx context.Context, req *pb.GetTodoRequest) (*pb.Todo, error) {
+	todo, err := s.ts.GetTodo(userID(ctx), req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoTodo(todo), nil
+}
+
+func (s *todoServer) ListTodos(req *pb.ListTodosRequest, stream grpc.ServerStreamingServer[pb.Todo]) error {
+	filter := service.TodoFilter{
+		Completed: req.Completed,
+		Overdue:   req.GetOverdue(),
+		Tag:       req.GetTag(),
+		Priority:  req.GetPriority(),
+		Search:    req.GetSearch(),
+	}
+	if req.GetDueBefore() != nil {
+		t := req.GetDueBefore().AsTime()
+		filter.DueBefore = &t
+	}
+	if req.GetDueAfter() != nil {
+		t := req.GetDueAfter().AsTime()
+		filter.DueAfter = &t
+	}
+
+	var sendErr error
+	err := s.ts.EachTodo(userID(stream.Context()), filter, func(todo models.Todo) error {
+		sendErr = stream.Send(toProtoTodo(&todo))
+		return sendErr
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+	if err != nil {
+		return toStatus(err)
+	}
+	return nil
+}
+
+func (s *todoServer) UpdateTodo(ctx context.Context, req *pb.UpdateTodoRequest) (*pb.Todo, error) {
+	in := req.GetTodo()
+	if in == nil {
+		return nil, status.Error(codes.InvalidArgument, "todo is required")
+	}
+	todo, err := s.ts.GetTodo(userID(ctx), in.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	paths := req.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		paths = []string{"title", "description", "completed", "deadline", "priority", "tags", "recurrence"}
+	}
+	for _, path := range paths {
+		switch path {
+		case "title":
+			todo.Title = in.GetTitle()
+		case "description":
+			todo.Description = in.GetDescription()
+		case "completed":
+			todo.Completed = in.GetCompleted()
+		case "deadline":
+			if in.GetDeadline() == nil {
+				return nil, status.Error(codes.InvalidArgument, "deadline is required")
+			}
+			todo.Deadline = in.GetDeadline().AsTime()
+		case "priority":
+			todo.Priority = in.GetPriority()
+		case "tags":
+			todo.Tags = in.GetTags()
+		case "recurrence":
+			todo.Recurrence = in.GetRecurrence()
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "unknown field in update_mask: %s", path)
+		}
+	}
+
+	if err := s.ts.UpdateTodo(userID(ctx), todo); err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoTodo(todo), nil
+}
+
+func (s *todoServer) DeleteTodo(ctx context.Context, req *pb.DeleteTodoRequest) (*emptypb.Empty, error) {
+	if err := s.ts.DeleteTodo(userID(ctx), req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,75 @@
+package grpcapi
+
+import (
+	"context"
+	"todolist/internal/models"
+	"todolist/internal/service"
+
+	pb "todolist/internal/pb/todolist/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type userServer struct {
+	pb.UnimplementedUserServiceServer
+	us *service.UserService
+}
+
+func toProtoProfile(u *models.User) *pb.UserProfile {
+	return &pb.UserProfile{
+		Id:            u.Id,
+		Username:      u.Username,
+		Email:         u.Email,
+		PendingEmail:  u.PendingEmail,
+		OauthProvider: u.OauthProvider,
+		HasPassword:   u.PasswordHash != "",
+	}
+}
+
+func (s *userServer) profile(ctx context.Context) (*pb.UserProfile, error) {
+	user, err := s.us.GetUserByID(userID(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoProfile(user), nil
+}
+
+func (s *userServer) GetProfile(ctx context.Context, _ *emptypb.Empty) (*pb.UserProfile, error) {
+	return s.profile(ctx)
+}
+
+func (s *userServer) UpdateUsername(ctx context.Context, req *pb.UpdateUsernameRequest) (*pb.UserProfile, error) {
+	if len(req.GetUsername()) < 3 {
+		return nil, status.Error(codes.InvalidArgument, "username must be at least 3 characters long")
+	}
+	if err := s.us.UpdateUsername(userID(ctx), req.GetUsername()); err != nil {
+		return nil, toStatus(err)
+	}
+	return s.profile(ctx)
+}
+
+func (s *userServer) UpdatePassword(ctx context.Context, req *pb.UpdatePasswordRequest) (*emptypb.Empty, error) {
+	if len(req.GetNewPassword()) < 8 {
+		return nil, status.Error(codes.InvalidArgument, "new password must be at least 8 characters")
+	}
+	if err := s.us.UpdatePassword(userID(ctx), sessionID(ctx), req.GetOldPassword(), req.GetNewPassword(), sessionClient(ctx)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *userServer) RequestEmailUpdate(ctx context.Context, req *pb.RequestEmailUpdateRequest) (*emptypb.Empty, error) {
+	if err := s.us.RequestEmailUpdate(userID(ctx), req.GetEmail()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *userServer) VerifyEmailUpdate(ctx context.Context, req *pb.VerifyEmailUpdateRequest) (*pb.UserProfile, error) {
+	if err := s.us.VerifyEmailUpdate(userID(ctx), req.GetCode()); err != nil {
+		return nil, toStatus(err)
+	}
+	return s.profile(ctx)
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Scopes a personal access token can be granted.
+const (
+	ScopeTodosRead   = "todos:read"
+	ScopeTodosWrite  = "todos:write"
+	ScopeProfileRead = "profile:read"
+)
+
+var AccessTokenScopes = []string{ScopeTodosRead, ScopeTodosWrite, ScopeProfileRead}
+
+// AccessToken is a personal access token for scripts and integrations. Only
+// its hash is stored; the token itself is shown once, when it is created.
+type AccessToken struct {
+	Id         string         `json:"id" db:"id"`
+	UserID     string         `json:"-" db:"user_id"`
+	Name       string         `json:"name" db:"name"`
+	TokenHash  string         `json:"-" db:"token_hash"`
+	Scopes     pq.StringArray `json:"scopes" db:"scopes" swaggertype:"array,string"`
+	ExpiresAt  *time.Time     `json:"expires_at" db:"expires_at"`
+	LastUsedAt *time.Time     `json:"last_used_at" db:"last_used_at"`
+	CreatedAt  time.Time      `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// TodoAttachment describes a file attached to a todo. The contents live in
+// blob storage under StorageKey.
+type TodoAttachment struct {
+	Id          string    `json:"id" db:"id"`
+	TodoID      string    `json:"todo_id" db:"todo_id"`
+	UserID      string    `json:"user_id" db:"user_id"`
+	Filename    string    `json:"filename" db:"filename"`
+	ContentType string    `json:"content_type" db:"content_type"`
+	Size        int64     `json:"size" db:"size"`
+	StorageKey  string    `json:"-" db:"storage_key"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
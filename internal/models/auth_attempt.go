@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// AuthAttempt counts recent failed logins or code entries for one key, such
+// as an account or a client IP.
+type AuthAttempt struct {
+	Key         string     `db:"key"`
+	Failures    int        `db:"failures"`
+	LockedUntil *time.Time `db:"locked_until"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+}
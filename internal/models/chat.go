@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+const ChatProviderSlack = "slack"
+
+// ChatLink ties a chat workspace user to a TodoList account.
+type ChatLink struct {
+	Provider   string    `json:"provider" db:"provider"`
+	TeamID     string    `json:"team_id" db:"team_id"`
+	ChatUserID string    `json:"chat_user_id" db:"chat_user_id"`
+	UserID     string    `json:"-" db:"user_id"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// ChatLinkCode is a short-lived one-time code the user types in chat to link accounts.
+type ChatLinkCode struct {
+	Code      string    `json:"code" db:"code"`
+	UserID    string    `json:"-" db:"user_id"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+}
+
+// ChatMessage is a reply in the Slack message format.
+type ChatMessage struct {
+	ResponseType    string      `json:"response_type,omitempty"`
+	ReplaceOriginal bool        `json:"replace_original,omitempty"`
+	Text            string      `json:"text"`
+	Blocks          []ChatBlock `json:"blocks,omitempty"`
+}
+
+type ChatBlock struct {
+	Type      string        `json:"type"`
+	Text      *ChatText     `json:"text,omitempty"`
+	Accessory *ChatElement  `json:"accessory,omitempty"`
+	Elements  []ChatElement `json:"elements,omitempty"`
+}
+
+type ChatText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type ChatElement struct {
+	Type     string    `json:"type"`
+	Text     *ChatText `json:"text,omitempty"`
+	ActionID string    `json:"action_id,omitempty"`
+	Value    string    `json:"value,omitempty"`
+	Style    string    `json:"style,omitempty"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// TodoComment is a Markdown comment on a todo. BodyHTML is rendered by the
+// server with raw HTML and unsafe links stripped.
+type TodoComment struct {
+	Id        string    `json:"id" db:"id"`
+	TodoID    string    `json:"todo_id" db:"todo_id"`
+	UserID    string    `json:"user_id" db:"user_id"`
+	Username  string    `json:"username" db:"username"`
+	Body      string    `json:"body" db:"body"`
+	BodyHTML  string    `json:"body_html" db:"-"`
+	Mentions  []string  `json:"mentions" db:"-"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+type TodoEventType string
+
+const (
+	TodoCreated   TodoEventType = "todo.created"
+	TodoUpdated   TodoEventType = "todo.updated"
+	TodoCompleted TodoEventType = "todo.completed"
+	TodoDeleted   TodoEventType = "todo.deleted"
+)
+
+var TodoEventTypes = []TodoEventType{TodoCreated, TodoUpdated, TodoCompleted, TodoDeleted}
+
+// TodoEvent describes a change to a todo. UserID is the todo's creator, whose
+// webhooks receive the event; Recipients are all users who see the todo and
+// get it on their event stream.
+type TodoEvent struct {
+	Type       TodoEventType `json:"type"`
+	UserID     string        `json:"-"`
+	Recipients []string      `json:"-"`
+	Todo       Todo          `json:"todo"`
+	OccurredAt time.Time     `json:"occurred_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type InboundAddress struct {
+	UserID         string         `json:"-" db:"user_id"`
+	Token          string         `json:"-" db:"token"`
+	Address        string         `json:"address" db:"-"`
+	AllowedSenders pq.StringArray `json:"allowed_senders" db:"allowed_senders" swaggertype:"array,string"`
+	CreatedAt      time.Time      `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// OIDCState keeps a started provider login between the redirect to the
+// provider and the callback: the state sent along, the nonce expected in
+// the ID token and the PKCE code verifier. UserID is set when a signed-in
+// user links the provider.
+type OIDCState struct {
+	State        string    `db:"state"`
+	Provider     string    `db:"provider"`
+	UserID       *string   `db:"user_id"`
+	Nonce        string    `db:"nonce"`
+	CodeVerifier string    `db:"code_verifier"`
+	ExpiresAt    time.Time `db:"expires_at"`
+}
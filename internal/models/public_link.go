@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PublicLink exposes selected todos read-only to anyone who has the link.
+// Only a hash of the token is stored; Token is set once, on creation.
+type PublicLink struct {
+	Id           string         `json:"id" db:"id"`
+	OwnerID      string         `json:"owner_id" db:"owner_id"`
+	Name         string         `json:"name" db:"name"`
+	TodoIDs      pq.StringArray `json:"todo_ids" db:"todo_ids" swaggertype:"array,string"`
+	TokenHash    string         `json:"-" db:"token_hash"`
+	PasswordHash string         `json:"-" db:"password_hash"`
+	HasPassword  bool           `json:"has_password" db:"has_password"`
+	ExpiresAt    *time.Time     `json:"expires_at" db:"expires_at"`
+	CreatedAt    time.Time      `json:"created_at" db:"created_at"`
+	Token        string         `json:"token,omitempty" db:"-"`
+}
+
+// PublicTodo is the part of a todo shown through a public link. It carries no
+// IDs and nothing about the owner.
+type PublicTodo struct {
+	Title       string         `json:"title" db:"title"`
+	Description string         `json:"description" db:"description"`
+	Completed   bool           `json:"completed" db:"completed"`
+	Deadline    time.Time      `json:"deadline" db:"deadline"`
+	Priority    string         `json:"priority" db:"priority"`
+	Tags        pq.StringArray `json:"tags" db:"tags" swaggertype:"array,string"`
+}
+
+type PublicLinkView struct {
+	Name      string       `json:"name"`
+	ExpiresAt *time.Time   `json:"expires_at"`
+	Todos     []PublicTodo `json:"todos"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// RefreshToken is an opaque, single-use token exchanged for a new access
+// token. Every rotation stays in the family of the original login, so reuse
+// of a spent token can revoke the whole chain.
+type RefreshToken struct {
+	Id        string     `db:"id"`
+	UserID    string     `db:"user_id"`
+	FamilyID  string     `db:"family_id"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Session is one login on one device. Access tokens carry its ID and stop
+// working as soon as the session is deleted.
+type Session struct {
+	Id         string    `json:"id" db:"id"`
+	UserID     string    `json:"-" db:"user_id"`
+	Device     string    `json:"device" db:"device"`
+	IP         string    `json:"ip" db:"ip"`
+	UserAgent  string    `json:"user_agent" db:"user_agent"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at" db:"last_seen_at"`
+	Current    bool      `json:"current" db:"-"`
+}
+
+// SessionClient describes the client a session is started from.
+type SessionClient struct {
+	IP        string
+	UserAgent string
+}
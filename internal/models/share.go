@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+const (
+	ShareRoleViewer = "viewer"
+	ShareRoleEditor = "editor"
+)
+
+// TodoShare grants another user access to one todo or, when TodoID is nil,
+// to the owner's whole list.
+type TodoShare struct {
+	Id              string    `json:"id" db:"id"`
+	OwnerID         string    `json:"owner_id" db:"owner_id"`
+	OwnerUsername   string    `json:"owner_username" db:"owner_username"`
+	TodoID          *string   `json:"todo_id" db:"todo_id"`
+	TodoTitle       *string   `json:"todo_title,omitempty" db:"todo_title"`
+	GranteeID       string    `json:"grantee_id" db:"grantee_id"`
+	GranteeUsername string    `json:"grantee_username" db:"grantee_username"`
+	Role            string    `json:"role" db:"role"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// TodoTemplate is a saved checklist that can be turned into todos anchored
+// to a start date.
+type TodoTemplate struct {
+	Id          string             `json:"id" db:"id"`
+	UserID      string             `json:"user_id" db:"user_id"`
+	Name        string             `json:"name" db:"name"`
+	Description string             `json:"description" db:"description"`
+	Items       []TodoTemplateItem `json:"items" db:"-"`
+	CreatedAt   time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+// TodoTemplateItem is one todo of a template. Offset is relative to the start
+// date, e.g. "+3d", "+1w2d", "+4h30m" or "-1d".
+type TodoTemplateItem struct {
+	Id          string         `json:"id" db:"id"`
+	TemplateID  string         `json:"-" db:"template_id"`
+	Position    int            `json:"position" db:"position"`
+	Title       string         `json:"title" db:"title"`
+	Description string         `json:"description" db:"description"`
+	Offset      string         `json:"offset" db:"deadline_offset"`
+	Priority    string         `json:"priority" db:"priority"`
+	Tags        pq.StringArray `json:"tags" db:"tags" swaggertype:"array,string"`
+}
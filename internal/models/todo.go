@@ -1,14 +1,30 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
 
 type Todo struct {
-	Id          string    `json:"id" db:"id"`
-	UserID      string    `json:"user_id" db:"user_id"`
-	Title       string    `json:"title" db:"title"`
-	Description string    `json:"description" db:"description"`
-	Completed   bool      `json:"completed" db:"completed"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
-	Deadline    time.Time `json:"deadline" db:"deadline"`
+	Id          string         `json:"id" db:"id"`
+	UserID      string         `json:"user_id" db:"user_id"`
+	WorkspaceID string         `json:"workspace_id" db:"workspace_id"`
+	Title       string         `json:"title" db:"title"`
+	Description string         `json:"description" db:"description"`
+	Completed   bool           `json:"completed" db:"completed"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at" db:"updated_at"`
+	Deadline    time.Time      `json:"deadline" db:"deadline"`
+	Priority    string         `json:"priority" db:"priority"`
+	Tags        pq.StringArray `json:"tags" db:"tags" swaggertype:"array,string"`
+	Recurrence  string         `json:"recurrence" db:"recurrence"`
+
+	// CommentCount is filled when todos are read, not stored on the row.
+	CommentCount int `json:"comment_count" db:"comment_count"`
+
+	// SharedBy and Permission are set only on todos shared with the caller:
+	// the owner's username and the caller's role (viewer or editor).
+	SharedBy   string `json:"shared_by,omitempty" db:"shared_by"`
+	Permission string `json:"permission,omitempty" db:"permission"`
 }
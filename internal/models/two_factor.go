@@ -0,0 +1,8 @@
+package models
+
+// TOTPSetup is shown once while enrolling an authenticator app. URI is the
+// otpauth:// payload for the QR code; Secret is for manual entry.
+type TOTPSetup struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
@@ -1,16 +1,19 @@
 package models
 
-import "time"
-
 type User struct {
-	Id                      string    `json:"id" db:"id"`
-	Username                string    `json:"username" db:"username"`
-	Email                   string    `json:"email" db:"email"`
-	PendingEmail            string    `json:"pending_email" db:"pending_email"`
-	PasswordHash            string    `json:"-" db:"password_hash"`
-	IsVerified              bool      `json:"is_verified" db:"is_verified"`
-	VerificationCode        string    `json:"-" db:"verification_code"`
-	VerificationCodeExpires time.Time `json:"-" db:"verification_code_expires"`
-	OauthProvider           string    `json:"oauth_provider" db:"oauth_provider"`
-	OauthId                 string    `json:"oauth_id" db:"oauth_id"`
+	Id       string `json:"id" db:"id"`
+	Username string `json:"username" db:"username"`
+	Email    string `json:"email" db:"email"`
+	// PendingEmail is the target of an unconfirmed email change, read from
+	// verification_tokens.
+	PendingEmail string `json:"pending_email" db:"pending_email"`
+	PasswordHash string `json:"-" db:"password_hash"`
+	IsVerified   bool   `json:"is_verified" db:"is_verified"`
+	// OauthProvider is the first linked login provider, read from
+	// user_identities.
+	OauthProvider     string  `json:"oauth_provider" db:"oauth_provider"`
+	ActiveWorkspaceID *string `json:"-" db:"active_workspace_id"`
+	TOTPSecret        string  `json:"-" db:"totp_secret"`
+	TOTPEnabled       bool    `json:"totp_enabled" db:"totp_enabled"`
+	TOTPLastStep      int64   `json:"-" db:"totp_last_step"`
 }
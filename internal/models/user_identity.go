@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// UserIdentity is an external login (Google, an OIDC provider...) linked to
+// an account. Subject is the user's ID at the provider.
+type UserIdentity struct {
+	Id         string     `json:"id" db:"id"`
+	UserID     string     `json:"-" db:"user_id"`
+	Provider   string     `json:"provider" db:"provider"`
+	Subject    string     `json:"-" db:"subject"`
+	Email      string     `json:"email" db:"email"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at" db:"last_used_at"`
+}
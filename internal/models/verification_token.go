@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Purposes of emailed verification codes. Each user has at most one pending
+// code per purpose, so starting one flow doesn't cancel another.
+const (
+	VerificationRegister      = "register"
+	VerificationEmailChange   = "email_change"
+	VerificationAccountDelete = "account_delete"
+)
+
+// VerificationToken is a single-use code emailed to Email. For an email
+// change Email is the new address. Only the code's hash is stored.
+type VerificationToken struct {
+	Id        string     `db:"id"`
+	UserID    string     `db:"user_id"`
+	Purpose   string     `db:"purpose"`
+	CodeHash  string     `db:"code_hash"`
+	Email     string     `db:"email"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// WebAuthnCredential is a passkey registered by a user. Credential holds the
+// verified public key record (key, sign counter, flags) as JSON.
+type WebAuthnCredential struct {
+	Id           string     `json:"id" db:"id"`
+	UserID       string     `json:"-" db:"user_id"`
+	Name         string     `json:"name" db:"name"`
+	CredentialID string     `json:"-" db:"credential_id"`
+	Credential   string     `json:"-" db:"credential"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt   *time.Time `json:"last_used_at" db:"last_used_at"`
+}
+
+// WebAuthnChallenge keeps the state of a registration or login ceremony
+// between its begin and finish requests. UserID is nil for logins.
+type WebAuthnChallenge struct {
+	Id          string    `db:"id"`
+	UserID      *string   `db:"user_id"`
+	SessionData string    `db:"session_data"`
+	ExpiresAt   time.Time `db:"expires_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type Webhook struct {
+	Id           string         `json:"id" db:"id"`
+	UserID       string         `json:"user_id" db:"user_id"`
+	URL          string         `json:"url" db:"url"`
+	Secret       string         `json:"-" db:"secret"`
+	Events       pq.StringArray `json:"events" db:"events" swaggertype:"array,string"`
+	IsActive     bool           `json:"is_active" db:"is_active"`
+	FailureCount int            `json:"failure_count" db:"failure_count"`
+	CreatedAt    time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+type WebhookDelivery struct {
+	Id         string    `json:"id" db:"id"`
+	WebhookID  string    `json:"webhook_id" db:"webhook_id"`
+	Event      string    `json:"event" db:"event"`
+	Payload    string    `json:"payload" db:"payload"`
+	Attempt    int       `json:"attempt" db:"attempt"`
+	StatusCode int       `json:"status_code" db:"status_code"`
+	Error      string    `json:"error" db:"error"`
+	Success    bool      `json:"success" db:"success"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
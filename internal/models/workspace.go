@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+const (
+	WorkspaceRoleOwner  = "owner"
+	WorkspaceRoleAdmin  = "admin"
+	WorkspaceRoleMember = "member"
+)
+
+// Workspace owns todos. Every user has a personal workspace that behaves like
+// the original single-user list; team workspaces have several members.
+type Workspace struct {
+	Id              string    `json:"id" db:"id"`
+	Name            string    `json:"name" db:"name"`
+	PersonalOwnerID *string   `json:"-" db:"personal_owner_id"`
+	IsPersonal      bool      `json:"is_personal" db:"is_personal"`
+	Role            string    `json:"role,omitempty" db:"role"`
+	Active          bool      `json:"active" db:"-"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+type WorkspaceMember struct {
+	WorkspaceID string    `json:"workspace_id" db:"workspace_id"`
+	UserID      string    `json:"user_id" db:"user_id"`
+	Username    string    `json:"username" db:"username"`
+	Email       string    `json:"email" db:"email"`
+	Role        string    `json:"role" db:"role"`
+	JoinedAt    time.Time `json:"joined_at" db:"joined_at"`
+}
@@ -0,0 +1,930 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: todolist/v1/todolist.proto
+
+package todolistv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Todo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Completed     bool                   `protobuf:"varint,4,opt,name=completed,proto3" json:"completed,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Deadline      *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=deadline,proto3" json:"deadline,omitempty"`
+	Priority      string                 `protobuf:"bytes,8,opt,name=priority,proto3" json:"priority,omitempty"`
+	Tags          []string               `protobuf:"bytes,9,rep,name=tags,proto3" json:"tags,omitempty"`
+	Recurrence    string                 `protobuf:"bytes,10,opt,name=recurrence,proto3" json:"recurrence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Todo) Reset() {
+	*x = Todo{}
+	mi := &file_todolist_v1_todolist_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Todo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Todo) ProtoMessage() {}
+
+func (x *Todo) ProtoReflect() protoreflect.Message {
+	mi := &file_todolist_v1_todolist_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Todo.ProtoReflect.Descriptor instead.
+func (*Todo) Descriptor() ([]byte, []int) {
+	return file_todolist_v1_todolist_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Todo) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Todo) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Todo) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Todo) GetCompleted() bool {
+	if x != nil {
+		return x.Completed
+	}
+	return false
+}
+
+func (x *Todo) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Todo) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Todo) GetDeadline() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Deadline
+	}
+	return nil
+}
+
+func (x *Todo) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *Todo) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Todo) GetRecurrence() string {
+	if x != nil {
+		return x.Recurrence
+	}
+	return ""
+}
+
+type CreateTodoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Deadline      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=deadline,proto3" json:"deadline,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTodoRequest) Reset() {
+	*x = CreateTodoRequest{}
+	mi := &file_todolist_v1_todolist_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTodoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTodoRequest) ProtoMessage() {}
+
+func (x *CreateTodoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todolist_v1_todolist_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTodoRequest.ProtoReflect.Descriptor instead.
+func (*CreateTodoRequest) Descriptor() ([]byte, []int) {
+	return file_todolist_v1_todolist_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateTodoRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *CreateTodoRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateTodoRequest) GetDeadline() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Deadline
+	}
+	return nil
+}
+
+type QuickAddTodoRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Text  string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	// IANA time zone used to resolve relative dates; defaults to UTC.
+	Timezone      string `protobuf:"bytes,2,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuickAddTodoRequest) Reset() {
+	*x = QuickAddTodoRequest{}
+	mi := &file_todolist_v1_todolist_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuickAddTodoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuickAddTodoRequest) ProtoMessage() {}
+
+func (x *QuickAddTodoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todolist_v1_todolist_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuickAddTodoRequest.ProtoReflect.Descriptor instead.
+func (*QuickAddTodoRequest) Descriptor() ([]byte, []int) {
+	return file_todolist_v1_todolist_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *QuickAddTodoRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *QuickAddTodoRequest) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+type GetTodoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTodoRequest) Reset() {
+	*x = GetTodoRequest{}
+	mi := &file_todolist_v1_todolist_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTodoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTodoRequest) ProtoMessage() {}
+
+func (x *GetTodoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todolist_v1_todolist_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTodoRequest.ProtoReflect.Descriptor instead.
+func (*GetTodoRequest) Descriptor() ([]byte, []int) {
+	return file_todolist_v1_todolist_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetTodoRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListTodosRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Completed     *bool                  `protobuf:"varint,1,opt,name=completed,proto3,oneof" json:"completed,omitempty"`
+	Overdue       bool                   `protobuf:"varint,2,opt,name=overdue,proto3" json:"overdue,omitempty"`
+	Tag           string                 `protobuf:"bytes,3,opt,name=tag,proto3" json:"tag,omitempty"`
+	Priority      string                 `protobuf:"bytes,4,opt,name=priority,proto3" json:"priority,omitempty"`
+	Search        string                 `protobuf:"bytes,5,opt,name=search,proto3" json:"search,omitempty"`
+	DueBefore     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=due_before,json=dueBefore,proto3" json:"due_before,omitempty"`
+	DueAfter      *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=due_after,json=dueAfter,proto3" json:"due_after,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTodosRequest) Reset() {
+	*x = ListTodosRequest{}
+	mi := &file_todolist_v1_todolist_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTodosRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTodosRequest) ProtoMessage() {}
+
+func (x *ListTodosRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todolist_v1_todolist_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTodosRequest.ProtoReflect.Descriptor instead.
+func (*ListTodosRequest) Descriptor() ([]byte, []int) {
+	return file_todolist_v1_todolist_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListTodosRequest) GetCompleted() bool {
+	if x != nil && x.Completed != nil {
+		return *x.Completed
+	}
+	return false
+}
+
+func (x *ListTodosRequest) GetOverdue() bool {
+	if x != nil {
+		return x.Overdue
+	}
+	return false
+}
+
+func (x *ListTodosRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *ListTodosRequest) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *ListTodosRequest) GetSearch() string {
+	if x != nil {
+		return x.Search
+	}
+	return ""
+}
+
+func (x *ListTodosRequest) GetDueBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DueBefore
+	}
+	return nil
+}
+
+func (x *ListTodosRequest) GetDueAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DueAfter
+	}
+	return nil
+}
+
+type UpdateTodoRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Todo  *Todo                  `protobuf:"bytes,1,opt,name=todo,proto3" json:"todo,omitempty"`
+	// Fields of todo to update, e.g. "title", "completed", "deadline".
+	// An empty mask updates every mutable field.
+	UpdateMask    *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTodoRequest) Reset() {
+	*x = UpdateTodoRequest{}
+	mi := &file_todolist_v1_todolist_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTodoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTodoRequest) ProtoMessage() {}
+
+func (x *UpdateTodoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todolist_v1_todolist_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTodoRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTodoRequest) Descriptor() ([]byte, []int) {
+	return file_todolist_v1_todolist_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UpdateTodoRequest) GetTodo() *Todo {
+	if x != nil {
+		return x.Todo
+	}
+	return nil
+}
+
+func (x *UpdateTodoRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+type DeleteTodoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTodoRequest) Reset() {
+	*x = DeleteTodoRequest{}
+	mi := &file_todolist_v1_todolist_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTodoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTodoRequest) ProtoMessage() {}
+
+func (x *DeleteTodoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todolist_v1_todolist_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTodoRequest.ProtoReflect.Descriptor instead.
+func (*DeleteTodoRequest) Descriptor() ([]byte, []int) {
+	return file_todolist_v1_todolist_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeleteTodoRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type UserProfile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Username      string                 `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	Email         string                 `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	PendingEmail  string                 `protobuf:"bytes,4,opt,name=pending_email,json=pendingEmail,proto3" json:"pending_email,omitempty"`
+	OauthProvider string                 `protobuf:"bytes,5,opt,name=oauth_provider,json=oauthProvider,proto3" json:"oauth_provider,omitempty"`
+	HasPassword   bool                   `protobuf:"varint,6,opt,name=has_password,json=hasPassword,proto3" json:"has_password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserProfile) Reset() {
+	*x = UserProfile{}
+	mi := &file_todolist_v1_todolist_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserProfile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserProfile) ProtoMessage() {}
+
+func (x *UserProfile) ProtoReflect() protoreflect.Message {
+	mi := &file_todolist_v1_todolist_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserProfile.ProtoReflect.Descriptor instead.
+func (*UserProfile) Descriptor() ([]byte, []int) {
+	return file_todolist_v1_todolist_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UserProfile) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UserProfile) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *UserProfile) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *UserProfile) GetPendingEmail() string {
+	if x != nil {
+		return x.PendingEmail
+	}
+	return ""
+}
+
+func (x *UserProfile) GetOauthProvider() string {
+	if x != nil {
+		return x.OauthProvider
+	}
+	return ""
+}
+
+func (x *UserProfile) GetHasPassword() bool {
+	if x != nil {
+		return x.HasPassword
+	}
+	return false
+}
+
+type UpdateUsernameRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateUsernameRequest) Reset() {
+	*x = UpdateUsernameRequest{}
+	mi := &file_todolist_v1_todolist_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateUsernameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateUsernameRequest) ProtoMessage() {}
+
+func (x *UpdateUsernameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todolist_v1_todolist_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateUsernameRequest.ProtoReflect.Descriptor instead.
+func (*UpdateUsernameRequest) Descriptor() ([]byte, []int) {
+	return file_todolist_v1_todolist_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UpdateUsernameRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+type UpdatePasswordRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OldPassword   string                 `protobuf:"bytes,1,opt,name=old_password,json=oldPassword,proto3" json:"old_password,omitempty"`
+	NewPassword   string                 `protobuf:"bytes,2,opt,name=new_password,json=newPassword,proto3" json:"new_password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePasswordRequest) Reset() {
+	*x = UpdatePasswordRequest{}
+	mi := &file_todolist_v1_todolist_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePasswordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePasswordRequest) ProtoMessage() {}
+
+func (x *UpdatePasswordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todolist_v1_todolist_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePasswordRequest.ProtoReflect.Descriptor instead.
+func (*UpdatePasswordRequest) Descriptor() ([]byte, []int) {
+	return file_todolist_v1_todolist_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UpdatePasswordRequest) GetOldPassword() string {
+	if x != nil {
+		return x.OldPassword
+	}
+	return ""
+}
+
+func (x *UpdatePasswordRequest) GetNewPassword() string {
+	if x != nil {
+		return x.NewPassword
+	}
+	return ""
+}
+
+type RequestEmailUpdateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestEmailUpdateRequest) Reset() {
+	*x = RequestEmailUpdateRequest{}
+	mi := &file_todolist_v1_todolist_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestEmailUpdateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestEmailUpdateRequest) ProtoMessage() {}
+
+func (x *RequestEmailUpdateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todolist_v1_todolist_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestEmailUpdateRequest.ProtoReflect.Descriptor instead.
+func (*RequestEmailUpdateRequest) Descriptor() ([]byte, []int) {
+	return file_todolist_v1_todolist_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RequestEmailUpdateRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+type VerifyEmailUpdateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyEmailUpdateRequest) Reset() {
+	*x = VerifyEmailUpdateRequest{}
+	mi := &file_todolist_v1_todolist_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyEmailUpdateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyEmailUpdateRequest) ProtoMessage() {}
+
+func (x *VerifyEmailUpdateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todolist_v1_todolist_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyEmailUpdateRequest.ProtoReflect.Descriptor instead.
+func (*VerifyEmailUpdateRequest) Descriptor() ([]byte, []int) {
+	return file_todolist_v1_todolist_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *VerifyEmailUpdateRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+var File_todolist_v1_todolist_proto protoreflect.FileDescriptor
+
+const file_todolist_v1_todolist_proto_rawDesc = "" +
+	"\n" +
+	"\x1atodolist/v1/todolist.proto\x12\vtodolist.v1\x1a\x1bgoogle/protobuf/empty.proto\x1a google/protobuf/field_mask.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xea\x02\n" +
+	"\x04Todo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x1c\n" +
+	"\tcompleted\x18\x04 \x01(\bR\tcompleted\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x126\n" +
+	"\bdeadline\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\bdeadline\x12\x1a\n" +
+	"\bpriority\x18\b \x01(\tR\bpriority\x12\x12\n" +
+	"\x04tags\x18\t \x03(\tR\x04tags\x12\x1e\n" +
+	"\n" +
+	"recurrence\x18\n" +
+	" \x01(\tR\n" +
+	"recurrence\"\x83\x01\n" +
+	"\x11CreateTodoRequest\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x126\n" +
+	"\bdeadline\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\bdeadline\"E\n" +
+	"\x13QuickAddTodoRequest\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\x1a\n" +
+	"\btimezone\x18\x02 \x01(\tR\btimezone\" \n" +
+	"\x0eGetTodoRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x97\x02\n" +
+	"\x10ListTodosRequest\x12!\n" +
+	"\tcompleted\x18\x01 \x01(\bH\x00R\tcompleted\x88\x01\x01\x12\x18\n" +
+	"\aoverdue\x18\x02 \x01(\bR\aoverdue\x12\x10\n" +
+	"\x03tag\x18\x03 \x01(\tR\x03tag\x12\x1a\n" +
+	"\bpriority\x18\x04 \x01(\tR\bpriority\x12\x16\n" +
+	"\x06search\x18\x05 \x01(\tR\x06search\x129\n" +
+	"\n" +
+	"due_before\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tdueBefore\x127\n" +
+	"\tdue_after\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\bdueAfterB\f\n" +
+	"\n" +
+	"_completed\"w\n" +
+	"\x11UpdateTodoRequest\x12%\n" +
+	"\x04todo\x18\x01 \x01(\v2\x11.todolist.v1.TodoR\x04todo\x12;\n" +
+	"\vupdate_mask\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\"#\n" +
+	"\x11DeleteTodoRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xbe\x01\n" +
+	"\vUserProfile\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\x12\x14\n" +
+	"\x05email\x18\x03 \x01(\tR\x05email\x12#\n" +
+	"\rpending_email\x18\x04 \x01(\tR\fpendingEmail\x12%\n" +
+	"\x0eoauth_provider\x18\x05 \x01(\tR\roauthProvider\x12!\n" +
+	"\fhas_password\x18\x06 \x01(\bR\vhasPassword\"3\n" +
+	"\x15UpdateUsernameRequest\x12\x1a\n" +
+	"\busername\x18\x01 \x01(\tR\busername\"]\n" +
+	"\x15UpdatePasswordRequest\x12!\n" +
+	"\fold_password\x18\x01 \x01(\tR\voldPassword\x12!\n" +
+	"\fnew_password\x18\x02 \x01(\tR\vnewPassword\"1\n" +
+	"\x19RequestEmailUpdateRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\".\n" +
+	"\x18VerifyEmailUpdateRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code2\x96\x03\n" +
+	"\vTodoService\x12?\n" +
+	"\n" +
+	"CreateTodo\x12\x1e.todolist.v1.CreateTodoRequest\x1a\x11.todolist.v1.Todo\x12C\n" +
+	"\fQuickAddTodo\x12 .todolist.v1.QuickAddTodoRequest\x1a\x11.todolist.v1.Todo\x129\n" +
+	"\aGetTodo\x12\x1b.todolist.v1.GetTodoRequest\x1a\x11.todolist.v1.Todo\x12?\n" +
+	"\tListTodos\x12\x1d.todolist.v1.ListTodosRequest\x1a\x11.todolist.v1.Todo0\x01\x12?\n" +
+	"\n" +
+	"UpdateTodo\x12\x1e.todolist.v1.UpdateTodoRequest\x1a\x11.todolist.v1.Todo\x12D\n" +
+	"\n" +
+	"DeleteTodo\x12\x1e.todolist.v1.DeleteTodoRequest\x1a\x16.google.protobuf.Empty2\x97\x03\n" +
+	"\vUserService\x12>\n" +
+	"\n" +
+	"GetProfile\x12\x16.google.protobuf.Empty\x1a\x18.todolist.v1.UserProfile\x12N\n" +
+	"\x0eUpdateUsername\x12\".todolist.v1.UpdateUsernameRequest\x1a\x18.todolist.v1.UserProfile\x12L\n" +
+	"\x0eUpdatePassword\x12\".todolist.v1.UpdatePasswordRequest\x1a\x16.google.protobuf.Empty\x12T\n" +
+	"\x12RequestEmailUpdate\x12&.todolist.v1.RequestEmailUpdateRequest\x1a\x16.google.protobuf.Empty\x12T\n" +
+	"\x11VerifyEmailUpdate\x12%.todolist.v1.VerifyEmailUpdateRequest\x1a\x18.todolist.v1.UserProfileB-Z+todolist/internal/pb/todolist/v1;todolistv1b\x06proto3"
+
+var (
+	file_todolist_v1_todolist_proto_rawDescOnce sync.Once
+	file_todolist_v1_todolist_proto_rawDescData []byte
+)
+
+func file_todolist_v1_todolist_proto_rawDescGZIP() []byte {
+	file_todolist_v1_todolist_proto_rawDescOnce.Do(func() {
+		file_todolist_v1_todolist_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_todolist_v1_todolist_proto_rawDesc), len(file_todolist_v1_todolist_proto_rawDesc)))
+	})
+	return file_todolist_v1_todolist_proto_rawDescData
+}
+
+var file_todolist_v1_todolist_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_todolist_v1_todolist_proto_goTypes = []any{
+	(*Todo)(nil),                      // 0: todolist.v1.Todo
+	(*CreateTodoRequest)(nil),         // 1: todolist.v1.CreateTodoRequest
+	(*QuickAddTodoRequest)(nil),       // 2: todolist.v1.QuickAddTodoRequest
+	(*GetTodoRequest)(nil),            // 3: todolist.v1.GetTodoRequest
+	(*ListTodosRequest)(nil),          // 4: todolist.v1.ListTodosRequest
+	(*UpdateTodoRequest)(nil),         // 5: todolist.v1.UpdateTodoRequest
+	(*DeleteTodoRequest)(nil),         // 6: todolist.v1.DeleteTodoRequest
+	(*UserProfile)(nil),               // 7: todolist.v1.UserProfile
+	(*UpdateUsernameRequest)(nil),     // 8: todolist.v1.UpdateUsernameRequest
+	(*UpdatePasswordRequest)(nil),     // 9: todolist.v1.UpdatePasswordRequest
+	(*RequestEmailUpdateRequest)(nil), // 10: todolist.v1.RequestEmailUpdateRequest
+	(*VerifyEmailUpdateRequest)(nil),  // 11: todolist.v1.VerifyEmailUpdateRequest
+	(*timestamppb.Timestamp)(nil),     // 12: google.protobuf.Timestamp
+	(*fieldmaskpb.FieldMask)(nil),     // 13: google.protobuf.FieldMask
+	(*emptypb.Empty)(nil),             // 14: google.protobuf.Empty
+}
+var file_todolist_v1_todolist_proto_depIdxs = []int32{
+	12, // 0: todolist.v1.Todo.created_at:type_name -> google.protobuf.Timestamp
+	12, // 1: todolist.v1.Todo.updated_at:type_name -> google.protobuf.Timestamp
+	12, // 2: todolist.v1.Todo.deadline:type_name -> google.protobuf.Timestamp
+	12, // 3: todolist.v1.CreateTodoRequest.deadline:type_name -> google.protobuf.Timestamp
+	12, // 4: todolist.v1.ListTodosRequest.due_before:type_name -> google.protobuf.Timestamp
+	12, // 5: todolist.v1.ListTodosRequest.due_after:type_name -> google.protobuf.Timestamp
+	0,  // 6: todolist.v1.UpdateTodoRequest.todo:type_name -> todolist.v1.Todo
+	13, // 7: todolist.v1.UpdateTodoRequest.update_mask:type_name -> google.protobuf.FieldMask
+	1,  // 8: todolist.v1.TodoService.CreateTodo:input_type -> todolist.v1.CreateTodoRequest
+	2,  // 9: todolist.v1.TodoService.QuickAddTodo:input_type -> todolist.v1.QuickAddTodoRequest
+	3,  // 10: todolist.v1.TodoService.GetTodo:input_type -> todolist.v1.GetTodoRequest
+	4,  // 11: todolist.v1.TodoService.ListTodos:input_type -> todolist.v1.ListTodosRequest
+	5,  // 12: todolist.v1.TodoService.UpdateTodo:input_type -> todolist.v1.UpdateTodoRequest
+	6,  // 13: todolist.v1.TodoService.DeleteTodo:input_type -> todolist.v1.DeleteTodoRequest
+	14, // 14: todolist.v1.UserService.GetProfile:input_type -> google.protobuf.Empty
+	8,  // 15: todolist.v1.UserService.UpdateUsername:input_type -> todolist.v1.UpdateUsernameRequest
+	9,  // 16: todolist.v1.UserService.UpdatePassword:input_type -> todolist.v1.UpdatePasswordRequest
+	10, // 17: todolist.v1.UserService.RequestEmailUpdate:input_type -> todolist.v1.RequestEmailUpdateRequest
+	11, // 18: todolist.v1.UserService.VerifyEmailUpdate:input_type -> todolist.v1.VerifyEmailUpdateRequest
+	0,  // 19: todolist.v1.TodoService.CreateTodo:output_type -> todolist.v1.Todo
+	0,  // 20: todolist.v1.TodoService.QuickAddTodo:output_type -> todolist.v1.Todo
+	0,  // 21: todolist.v1.TodoService.GetTodo:output_type -> todolist.v1.Todo
+	0,  // 22: todolist.v1.TodoService.ListTodos:output_type -> todolist.v1.Todo
+	0,  // 23: todolist.v1.TodoService.UpdateTodo:output_type -> todolist.v1.Todo
+	14, // 24: todolist.v1.TodoService.DeleteTodo:output_type -> google.protobuf.Empty
+	7,  // 25: todolist.v1.UserService.GetProfile:output_type -> todolist.v1.UserProfile
+	7,  // 26: todolist.v1.UserService.UpdateUsername:output_type -> todolist.v1.UserProfile
+	14, // 27: todolist.v1.UserService.UpdatePassword:output_type -> google.protobuf.Empty
+	14, // 28: todolist.v1.UserService.RequestEmailUpdate:output_type -> google.protobuf.Empty
+	7,  // 29: todolist.v1.UserService.VerifyEmailUpdate:output_type -> todolist.v1.UserProfile
+	19, // [19:30] is the sub-list for method output_type
+	8,  // [8:19] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_todolist_v1_todolist_proto_init() }
+func file_todolist_v1_todolist_proto_init() {
+	if File_todolist_v1_todolist_proto != nil {
+		return
+	}
+	file_todolist_v1_todolist_proto_msgTypes[4].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_todolist_v1_todolist_proto_rawDesc), len(file_todolist_v1_todolist_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   2,
+		},
+		GoTypes:           file_todolist_v1_todolist_proto_goTypes,
+		DependencyIndexes: file_todolist_v1_todolist_proto_depIdxs,
+		MessageInfos:      file_todolist_v1_todolist_proto_msgTypes,
+	}.Build()
+	File_todolist_v1_todolist_proto = out.File
+	file_todolist_v1_todolist_proto_goTypes = nil
+	file_todolist_v1_todolist_proto_depIdxs = nil
+}
@@ -0,0 +1,744 @@
+// Package quickadd parses free-form todo input such as
+// "Pay rent every 1st at 9am #finance !high" or "Сплатити оренду завтра о 9 #фінанси"
+// into a title, deadline, recurrence rule, tags and priority.
+package quickadd
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+)
+
+// Result is the structured form of a quick-add string. Recurrence is an
+// RFC 5545 RRULE value, e.g. "FREQ=MONTHLY;BYMONTHDAY=1". Unparsed lists
+// schedules that aren't supported, such as "every 2 hours"; they stay in
+// the title.
+type Result struct {
+	Title      string     `json:"title"`
+	Deadline   *time.Time `json:"deadline,omitempty"`
+	Recurrence string     `json:"recurrence,omitempty"`
+	Tags       []string   `json:"tags"`
+	Priority   string     `json:"priority,omitempty"`
+	Unparsed   []string   `json:"unparsed,omitempty"`
+}
+
+type clock struct {
+	hour, minute int
+}
+
+type parser struct {
+	words    []string
+	lower    []string
+	consumed []bool
+
+	now time.Time
+
+	date       *time.Time
+	weekday    *time.Weekday
+	dayOfMonth int
+	offset     time.Duration
+	at         *clock
+
+	freq     string
+	interval int
+	byDay    []string
+
+	tags     []string
+	priority string
+
+	unparsed []string
+}
+
+var (
+	clockRe    = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+	ordinalRe  = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th|-го|-е)$`)
+	isoDateRe  = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+	dotDateRe  = regexp.MustCompile(`^(\d{1,2})\.(\d{1,2})(?:\.(\d{4}))?$`)
+	numberRe   = regexp.MustCompile(`^\d{1,3}$`)
+	trimmedSet = ",;"
+)
+
+// Parse extracts scheduling metadata from input. Relative expressions are
+// resolved against now in now's location.
+func Parse(input string, now time.Time) Result {
+	words := strings.Fields(input)
+	p := &parser{
+		words:    words,
+		lower:    make([]string, len(words)),
+		consumed: make([]bool, len(words)),
+		now:      now,
+	}
+	for i, w := range words {
+		p.lower[i] = normalize(w)
+	}
+
+	rules := []func(int) int{
+		p.parseTag,
+		p.parsePriority,
+		p.parseRecurrence,
+		p.parseRelative,
+		p.parseWeekday,
+		p.parseDate,
+		p.parseTime,
+	}
+	for i := 0; i < len(words); {
+		n := 0
+		for _, rule := range rules {
+			if n = rule(i); n > 0 {
+				break
+			}
+		}
+		if n == 0 {
+			i++
+			continue
+		}
+		for j := i; j < i+n; j++ {
+			p.consumed[j] = true
+		}
+		i += n
+	}
+
+	var title []string
+	for i, w := range words {
+		if !p.consumed[i] {
+			title = append(title, w)
+		}
+	}
+
+	res := Result{
+		Title:      strings.Join(title, " "),
+		Recurrence: p.rrule(),
+		Tags:       p.tags,
+		Priority:   p.priority,
+		Deadline:   p.deadline(),
+		Unparsed:   p.unparsed,
+	}
+	if res.Tags == nil {
+		res.Tags = []string{}
+	}
+	return res
+}
+
+func normalize(w string) string {
+	w = strings.ToLower(strings.TrimRight(w, trimmedSet))
+	w = strings.NewReplacer("’", "'", "ʼ", "'", "`", "'").Replace(w)
+	return strings.TrimSuffix(w, ".")
+}
+
+func (p *parser) word(i int) string {
+	if i < 0 || i >= len(p.lower) {
+		return ""
+	}
+	return p.lower[i]
+}
+
+func (p *parser) parseTag(i int) int {
+	w := strings.TrimRight(p.words[i], trimmedSet+".")
+	if len(w) < 2 || w[0] != '#' {
+		return 0
+	}
+	tag := strings.ToLower(w[1:])
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' && r != '/' {
+			return 0
+		}
+	}
+	for _, existing := range p.tags {
+		if existing == tag {
+			return 1
+		}
+	}
+	p.tags = append(p.tags, tag)
+	return 1
+}
+
+var priorityWords = map[string]string{
+	"!":          PriorityLow,
+	"!!":         PriorityMedium,
+	"!!!":        PriorityHigh,
+	"!low":       PriorityLow,
+	"!l":         PriorityLow,
+	"!3":         PriorityLow,
+	"!medium":    PriorityMedium,
+	"!med":       PriorityMedium,
+	"!normal":    PriorityMedium,
+	"!m":         PriorityMedium,
+	"!2":         PriorityMedium,
+	"!high":      PriorityHigh,
+	"!h":         PriorityHigh,
+	"!urgent":    PriorityHigh,
+	"!1":         PriorityHigh,
+	"!низький":   PriorityLow,
+	"!низька":    PriorityLow,
+	"!середній":  PriorityMedium,
+	"!середня":   PriorityMedium,
+	"!високий":   PriorityHigh,
+	"!висока":    PriorityHigh,
+	"!терміново": PriorityHigh,
+}
+
+func (p *parser) parsePriority(i int) int {
+	if priority, ok := priorityWords[p.word(i)]; ok {
+		p.priority = priority
+		return 1
+	}
+	return 0
+}
+
+var numberWords = map[string]int{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+	"six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10,
+	"один": 1, "одну": 1, "одна": 1, "два": 2, "дві": 2, "три": 3, "чотири": 4,
+	"п'ять": 5, "шість": 6, "сім": 7, "вісім": 8, "дев'ять": 9, "десять": 10,
+}
+
+func parseNumber(w string) (int, bool) {
+	if numberRe.MatchString(w) {
+		n, err := strconv.Atoi(w)
+		return n, err == nil && n > 0
+	}
+	n, ok := numberWords[w]
+	return n, ok
+}
+
+type unit int
+
+const (
+	unitNone unit = iota
+	unitMinute
+	unitHour
+	unitDay
+	unitWeek
+	unitMonth
+	unitYear
+)
+
+var unitWords = map[string]unit{
+	"min": unitMinute, "mins": unitMinute, "minute": unitMinute, "minutes": unitMinute,
+	"хвилину": unitMinute, "хвилини": unitMinute, "хвилин": unitMinute,
+	"h": unitHour, "hour": unitHour, "hours": unitHour,
+	"годину": unitHour, "години": unitHour, "годин": unitHour,
+	"d": unitDay, "day": unitDay, "days": unitDay,
+	"день": unitDay, "дні": unitDay, "днів": unitDay, "дня": unitDay,
+	"w": unitWeek, "week": unitWeek, "weeks": unitWeek,
+	"тиждень": unitWeek, "тижні": unitWeek, "тижнів": unitWeek, "тижня": unitWeek,
+	"month": unitMonth, "months": unitMonth,
+	"місяць": unitMonth, "місяці": unitMonth, "місяців": unitMonth, "місяця": unitMonth,
+	"year": unitYear, "years": unitYear,
+	"рік": unitYear, "роки": unitYear, "років": unitYear,
+}
+
+// parseAmount reads "<n> <unit>" or a bare unit ("тиждень", "week") at i.
+func (p *parser) parseAmount(i int) (int, unit, int) {
+	if u, ok := unitWords[p.word(i)]; ok && len([]rune(p.word(i))) > 1 {
+		return 1, u, 1
+	}
+	n, ok := parseNumber(p.word(i))
+	if !ok {
+		return 0, unitNone, 0
+	}
+	if u, ok := unitWords[p.word(i+1)]; ok {
+		return n, u, 2
+	}
+	return 0, unitNone, 0
+}
+
+var weekdays = map[string]time.Weekday{
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"понеділок": time.Monday, "понеділка": time.Monday, "понеділку": time.Monday, "пн": time.Monday,
+	"вівторок": time.Tuesday, "вівторка": time.Tuesday, "вівторку": time.Tuesday, "вт": time.Tuesday,
+	"середа": time.Wednesday, "середу": time.Wednesday, "середи": time.Wednesday, "ср": time.Wednesday,
+	"четвер": time.Thursday, "четверга": time.Thursday, "четвергу": time.Thursday, "чт": time.Thursday,
+	"п'ятниця": time.Friday, "п'ятницю": time.Friday, "п'ятниці": time.Friday,
+	"пятниця": time.Friday, "пятницю": time.Friday, "пятниці": time.Friday, "пт": time.Friday,
+	"субота": time.Saturday, "суботу": time.Saturday, "суботи": time.Saturday, "сб": time.Saturday,
+	"неділя": time.Sunday, "неділю": time.Sunday, "неділі": time.Sunday, "нд": time.Sunday,
+}
+
+var rruleDays = map[time.Weekday]string{
+	time.Monday: "MO", time.Tuesday: "TU", time.Wednesday: "WE", time.Thursday: "TH",
+	time.Friday: "FR", time.Saturday: "SA", time.Sunday: "SU",
+}
+
+var recurrenceWords = map[string]string{
+	"daily": "DAILY", "щодня": "DAILY", "щоденно": "DAILY",
+	"weekly": "WEEKLY", "щотижня": "WEEKLY", "щотижнево": "WEEKLY",
+	"monthly": "MONTHLY", "щомісяця": "MONTHLY", "щомісячно": "MONTHLY",
+	"yearly": "YEARLY", "annually": "YEARLY", "щороку": "YEARLY", "щорічно": "YEARLY",
+}
+
+var unitFreq = map[unit]string{
+	unitDay: "DAILY", unitWeek: "WEEKLY", unitMonth: "MONTHLY", unitYear: "YEARLY",
+}
+
+var everyWords = map[string]bool{
+	"every": true, "each": true,
+	"кожного": true, "кожен": true, "кожний": true, "кожну": true, "кожне": true, "кожні": true,
+}
+
+// ukrainianEveryWeekday maps "щопонеділка"-style adverbs to their weekday.
+func ukrainianEveryWeekday(w string) (time.Weekday, bool) {
+	rest, ok := strings.CutPrefix(w, "що")
+	if !ok {
+		return 0, false
+	}
+	day, ok := weekdays[rest]
+	return day, ok
+}
+
+func (p *parser) parseRecurrence(i int) int {
+	w := p.word(i)
+	if p.freq != "" {
+		return 0
+	}
+
+	if freq, ok := recurrenceWords[w]; ok {
+		p.freq = freq
+		if freq == "MONTHLY" {
+			if n := p.parseDayOfMonth(i + 1); n > 0 {
+				return 1 + n
+			}
+		}
+		return 1
+	}
+	if day, ok := ukrainianEveryWeekday(w); ok {
+		p.freq = "WEEKLY"
+		p.byDay = []string{rruleDays[day]}
+		return 1
+	}
+	if w == "weekdays" || (w == "по" && p.word(i+1) == "буднях") {
+		p.freq = "WEEKLY"
+		p.byDay = []string{"MO", "TU", "WE", "TH", "FR"}
+		if w == "по" {
+			return 2
+		}
+		return 1
+	}
+
+	if !everyWords[w] {
+		return 0
+	}
+	next := p.word(i + 1)
+
+	if next == "weekday" || (next == "буднього" && p.word(i+2) == "дня") {
+		p.freq = "WEEKLY"
+		p.byDay = []string{"MO", "TU", "WE", "TH", "FR"}
+		if next == "weekday" {
+			return 2
+		}
+		return 3
+	}
+	if day, ok := weekdays[next]; ok {
+		p.freq = "WEEKLY"
+		p.byDay = []string{rruleDays[day]}
+		return 2
+	}
+	if n := p.parseDayOfMonth(i + 1); n > 0 {
+		p.freq = "MONTHLY"
+		return 1 + n
+	}
+	if count, u, n := p.parseAmount(i + 1); n > 0 {
+		freq, ok := unitFreq[u]
+		if !ok {
+			p.unparsed = append(p.unparsed, strings.Join(p.words[i:i+1+n], " "))
+			return 0
+		}
+		p.freq = freq
+		if count > 1 {
+			p.interval = count
+		}
+		return 1 + n
+	}
+	return 0
+}
+
+// parseDayOfMonth reads "1st", "the 1st", "1-го", "1-го числа".
+func (p *parser) parseDayOfMonth(i int) int {
+	consumed := 0
+	if p.word(i) == "the" {
+		consumed = 1
+	}
+	m := ordinalRe.FindStringSubmatch(p.word(i + consumed))
+	if m == nil {
+		return 0
+	}
+	day, _ := strconv.Atoi(m[1])
+	if day < 1 || day > 31 {
+		return 0
+	}
+	p.dayOfMonth = day
+	consumed++
+	if p.word(i+consumed) == "числа" {
+		consumed++
+	}
+	return consumed
+}
+
+func (p *parser) parseRelative(i int) int {
+	w := p.word(i)
+	switch w {
+	case "today", "сьогодні":
+		p.setDate(p.now)
+		return 1
+	case "tonight":
+		p.setDate(p.now)
+		p.at = &clock{20, 0}
+		return 1
+	case "tomorrow", "завтра":
+		p.setDate(p.now.AddDate(0, 0, 1))
+		return 1
+	case "післязавтра":
+		p.setDate(p.now.AddDate(0, 0, 2))
+		return 1
+	case "day", "the":
+		start := i
+		if w == "the" {
+			start++
+		}
+		if p.word(start) == "day" && p.word(start+1) == "after" && p.word(start+2) == "tomorrow" {
+			p.setDate(p.now.AddDate(0, 0, 2))
+			return start - i + 3
+		}
+	case "in", "через":
+		count, u, n := p.parseAmount(i + 1)
+		if n == 0 {
+			return 0
+		}
+		switch u {
+		case unitMinute:
+			p.offset = time.Duration(count) * time.Minute
+		case unitHour:
+			p.offset = time.Duration(count) * time.Hour
+		case unitDay:
+			p.setDate(p.now.AddDate(0, 0, count))
+		case unitWeek:
+			p.setDate(p.now.AddDate(0, 0, 7*count))
+		case unitMonth:
+			p.setDate(p.now.AddDate(0, count, 0))
+		case unitYear:
+			p.setDate(p.now.AddDate(count, 0, 0))
+		}
+		return 1 + n
+	case "next", "наступного", "наступної", "наступний", "наступну":
+		next := p.word(i + 1)
+		if day, ok := weekdays[next]; ok {
+			p.weekday = &day
+			return 2
+		}
+		switch unitWords[next] {
+		case unitWeek:
+			p.setDate(startOfNextWeek(p.now))
+			return 2
+		case unitMonth:
+			p.setDate(time.Date(p.now.Year(), p.now.Month()+1, 1, 0, 0, 0, 0, p.now.Location()))
+			return 2
+		}
+	case "on", "this", "в", "у", "во":
+		if w == "on" {
+			if n := p.parseDate(i + 1); n > 0 {
+				return 1 + n
+			}
+			if n := p.parseDayOfMonth(i + 1); n > 0 {
+				return 1 + n
+			}
+		}
+		if day, ok := weekdays[p.word(i+1)]; ok {
+			p.weekday = &day
+			return 2
+		}
+	}
+	return 0
+}
+
+func startOfNextWeek(now time.Time) time.Time {
+	days := (8 - int(now.Weekday())) % 7
+	if days == 0 {
+		days = 7
+	}
+	return now.AddDate(0, 0, days)
+}
+
+// parseWeekday accepts a bare day name. Abbreviations such as "sat", "sun"
+// or "ср" double as ordinary words, so they only count after "on", "next"
+// or "every".
+func (p *parser) parseWeekday(i int) int {
+	day, ok := weekdays[p.word(i)]
+	if !ok || len([]rune(p.word(i))) < 6 {
+		return 0
+	}
+	p.weekday = &day
+	return 1
+}
+
+var months = map[string]time.Month{
+	"january": time.January, "jan": time.January, "february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March, "april": time.April, "apr": time.April,
+	"may": time.May, "june": time.June, "jun": time.June, "july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August, "september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October, "november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+	"січня": time.January, "лютого": time.February, "березня": time.March, "квітня": time.April,
+	"травня": time.May, "червня": time.June, "липня": time.July, "серпня": time.August,
+	"вересня": time.September, "жовтня": time.October, "листопада": time.November, "грудня": time.December,
+}
+
+// parseDate reads an absolute date, optionally after "the" as in
+// "the 5th of march".
+func (p *parser) parseDate(i int) int {
+	if p.word(i) == "the" {
+		if n := p.parseCalendarDate(i + 1); n > 0 {
+			return 1 + n
+		}
+		return 0
+	}
+	return p.parseCalendarDate(i)
+}
+
+func (p *parser) parseCalendarDate(i int) int {
+	w := p.word(i)
+	if m := isoDateRe.FindStringSubmatch(w); m != nil {
+		y, _ := strconv.Atoi(m[1])
+		mo, _ := strconv.Atoi(m[2])
+		d, _ := strconv.Atoi(m[3])
+		return p.setCalendarDate(y, time.Month(mo), d, 1)
+	}
+	if m := dotDateRe.FindStringSubmatch(w); m != nil {
+		d, _ := strconv.Atoi(m[1])
+		mo, _ := strconv.Atoi(m[2])
+		y := 0
+		if m[3] != "" {
+			y, _ = strconv.Atoi(m[3])
+		}
+		return p.setCalendarDate(y, time.Month(mo), d, 1)
+	}
+
+	// "5 march", "5th of march", "5 березня"
+	if day, ok := dayNumber(w); ok {
+		j := i + 1
+		if p.word(j) == "of" {
+			j++
+		}
+		if month, ok := months[p.word(j)]; ok {
+			return p.setCalendarDate(p.yearAfter(j), month, day, j-i+1+p.yearLen(j))
+		}
+	}
+	// "march 5", "march 5th"
+	if month, ok := months[w]; ok {
+		if day, ok := dayNumber(p.word(i + 1)); ok {
+			return p.setCalendarDate(p.yearAfter(i+1), month, day, 2+p.yearLen(i+1))
+		}
+	}
+	return 0
+}
+
+func dayNumber(w string) (int, bool) {
+	if m := ordinalRe.FindStringSubmatch(w); m != nil {
+		w = m[1]
+	}
+	if len(w) > 2 {
+		return 0, false
+	}
+	d, err := strconv.Atoi(w)
+	return d, err == nil && d >= 1 && d <= 31
+}
+
+func (p *parser) yearLen(i int) int {
+	if y, err := strconv.Atoi(p.word(i + 1)); err == nil && y >= 1970 && y <= 9999 {
+		return 1
+	}
+	return 0
+}
+
+func (p *parser) yearAfter(i int) int {
+	if p.yearLen(i) == 1 {
+		y, _ := strconv.Atoi(p.word(i + 1))
+		return y
+	}
+	return 0
+}
+
+// setCalendarDate sets an absolute date. A zero year means the next
+// occurrence of that month and day.
+func (p *parser) setCalendarDate(year int, month time.Month, day, consumed int) int {
+	if month < time.January || month > time.December || day < 1 || day > 31 {
+		return 0
+	}
+	loc := p.now.Location()
+	if year == 0 {
+		year = p.now.Year()
+		candidate := time.Date(year, month, day, 23, 59, 0, 0, loc)
+		if candidate.Before(p.now) {
+			year++
+		}
+	}
+	date := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	if date.Month() != month {
+		return 0
+	}
+	p.date = &date
+	return consumed
+}
+
+func (p *parser) setDate(t time.Time) {
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	p.date = &date
+}
+
+var timeOfDay = map[string]int{
+	"ранку": 0, "зранку": 0, "дня": 12, "вечора": 12, "ночі": 0,
+}
+
+func (p *parser) parseTime(i int) int {
+	w := p.word(i)
+	prefixed := w == "at" || w == "о" || w == "об" || w == "в" || w == "у" || w == "@"
+	start := i
+	if prefixed {
+		start = i + 1
+	}
+
+	switch p.word(start) {
+	case "noon", "midday", "опівдні":
+		p.at = &clock{12, 0}
+		return start - i + 1
+	case "midnight", "опівночі":
+		p.at = &clock{23, 59}
+		return start - i + 1
+	}
+	m := clockRe.FindStringSubmatch(p.word(start))
+	if m == nil {
+		return 0
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	consumed := start - i + 1
+	suffix := m[3]
+	if suffix == "" && (p.word(start+1) == "am" || p.word(start+1) == "pm") {
+		suffix = p.word(start + 1)
+		consumed++
+	}
+	if !prefixed && suffix == "" && m[2] == "" {
+		return 0
+	}
+	if suffix != "" && (hour == 0 || hour > 12) {
+		return 0
+	}
+	if shift, ok := timeOfDay[p.word(i+consumed)]; ok && suffix == "" {
+		if hour < 12 {
+			hour += shift
+		}
+		consumed++
+	}
+	switch suffix {
+	case "am":
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour < 12 {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0
+	}
+	p.at = &clock{hour, minute}
+	return consumed
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
+
+func (p *parser) rrule() string {
+	if p.freq == "" {
+		return ""
+	}
+	parts := []string{"FREQ=" + p.freq}
+	if p.interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(p.interval))
+	}
+	if len(p.byDay) > 0 {
+		parts = append(parts, "BYDAY="+strings.Join(p.byDay, ","))
+	} else if p.freq == "WEEKLY" && p.weekday != nil {
+		parts = append(parts, "BYDAY="+rruleDays[*p.weekday])
+	}
+	if p.freq == "MONTHLY" && p.dayOfMonth > 0 {
+		parts = append(parts, "BYMONTHDAY="+strconv.Itoa(p.dayOfMonth))
+	}
+	return strings.Join(parts, ";")
+}
+
+// deadline resolves the collected date parts into a single instant. Dates
+// without a time default to the end of the day.
+func (p *parser) deadline() *time.Time {
+	now := p.now
+	loc := now.Location()
+
+	if p.offset > 0 {
+		t := now.Add(p.offset)
+		return &t
+	}
+
+	at := clock{23, 59}
+	if p.at != nil {
+		at = *p.at
+	}
+	on := func(d time.Time) time.Time {
+		return time.Date(d.Year(), d.Month(), d.Day(), at.hour, at.minute, 0, 0, loc)
+	}
+	// nextMatching returns the first day from today on that satisfies match
+	// and is still in the future at the chosen time.
+	nextMatching := func(match func(time.Time) bool) *time.Time {
+		for d := 0; d <= 400; d++ {
+			t := on(now.AddDate(0, 0, d))
+			if match(t) && t.After(now) {
+				return &t
+			}
+		}
+		return nil
+	}
+
+	switch {
+	case p.date != nil:
+		t := on(*p.date)
+		return &t
+	case p.weekday != nil:
+		day := *p.weekday
+		return nextMatching(func(t time.Time) bool {
+			return t.Weekday() == day && !sameDay(t, now)
+		})
+	case p.dayOfMonth > 0:
+		dom := p.dayOfMonth
+		return nextMatching(func(t time.Time) bool { return t.Day() == dom })
+	case len(p.byDay) > 0:
+		days := p.byDay
+		return nextMatching(func(t time.Time) bool {
+			for _, d := range days {
+				if rruleDays[t.Weekday()] == d {
+					return true
+				}
+			}
+			return false
+		})
+	case p.freq != "" || p.at != nil:
+		return nextMatching(func(time.Time) bool { return true })
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package quickadd
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// now is Wednesday, 4 March 2026, 10:00 local time.
+var now = time.Date(2026, time.March, 4, 10, 0, 0, 0, time.FixedZone("EET", 2*60*60))
+
+func at(year int, month time.Month, day, hour, minute int) *time.Time {
+	t := time.Date(year, month, day, hour, minute, 0, 0, now.Location())
+	return &t
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Result
+	}{
+		// Plain titles are left alone.
+		{"Buy milk", Result{Title: "Buy milk"}},
+		{"Call mom on sat", Result{Title: "Call mom", Deadline: at(2026, 3, 7, 23, 59)}},
+		{"Read about the sun", Result{Title: "Read about the sun"}},
+
+		// Tags and priority.
+		{"Pay rent #finance #home !high", Result{Title: "Pay rent", Tags: []string{"finance", "home"}, Priority: PriorityHigh}},
+		{"Fix bug #work #work !!", Result{Title: "Fix bug", Tags: []string{"work"}, Priority: PriorityMedium}},
+		{"Звіт #робота !терміново", Result{Title: "Звіт", Tags: []string{"робота"}, Priority: PriorityHigh}},
+
+		// Relative days.
+		{"Standup today at 11am", Result{Title: "Standup", Deadline: at(2026, 3, 4, 11, 0)}},
+		{"Dentist tomorrow at 9:30", Result{Title: "Dentist", Deadline: at(2026, 3, 5, 9, 30)}},
+		{"Taxes the day after tomorrow", Result{Title: "Taxes", Deadline: at(2026, 3, 6, 23, 59)}},
+		{"Taxes day after tomorrow", Result{Title: "Taxes", Deadline: at(2026, 3, 6, 23, 59)}},
+		{"Movie tonight", Result{Title: "Movie", Deadline: at(2026, 3, 4, 20, 0)}},
+		{"Зателефонувати завтра о 9", Result{Title: "Зателефонувати", Deadline: at(2026, 3, 5, 9, 0)}},
+		{"Прибрати післязавтра", Result{Title: "Прибрати", Deadline: at(2026, 3, 6, 23, 59)}},
+
+		// Offsets.
+		{"Check oven in 20 minutes", Result{Title: "Check oven", Deadline: at(2026, 3, 4, 10, 20)}},
+		{"Renew passport in 2 weeks", Result{Title: "Renew passport", Deadline: at(2026, 3, 18, 23, 59)}},
+		{"Перевірити через годину", Result{Title: "Перевірити", Deadline: at(2026, 3, 4, 11, 0)}},
+
+		// Weekdays.
+		{"Meeting on friday at 3pm", Result{Title: "Meeting", Deadline: at(2026, 3, 6, 15, 0)}},
+		{"Review next monday", Result{Title: "Review", Deadline: at(2026, 3, 9, 23, 59)}},
+		{"Plan wednesday", Result{Title: "Plan", Deadline: at(2026, 3, 11, 23, 59)}},
+		{"Зустріч у п'ятницю о 15:00 #робота", Result{Title: "Зустріч", Deadline: at(2026, 3, 6, 15, 0), Tags: []string{"робота"}}},
+
+		// Calendar dates.
+		{"Party on 5 march", Result{Title: "Party", Deadline: at(2026, 3, 5, 23, 59)}},
+		{"Party on the 5th of march", Result{Title: "Party", Deadline: at(2026, 3, 5, 23, 59)}},
+		{"Party on march 5th at 7pm", Result{Title: "Party", Deadline: at(2026, 3, 5, 19, 0)}},
+		{"Trip 2026-07-01", Result{Title: "Trip", Deadline: at(2026, 7, 1, 23, 59)}},
+		{"Trip 1 march", Result{Title: "Trip", Deadline: at(2027, 3, 1, 23, 59)}},
+		{"Відпустка 12.08", Result{Title: "Відпустка", Deadline: at(2026, 8, 12, 23, 59)}},
+		{"Іспит 5 березня 2027", Result{Title: "Іспит", Deadline: at(2027, 3, 5, 23, 59)}},
+
+		// Recurrence.
+		{"Pay rent every 1st at 9am #finance !high", Result{Title: "Pay rent", Deadline: at(2026, 4, 1, 9, 0), Recurrence: "FREQ=MONTHLY;BYMONTHDAY=1", Tags: []string{"finance"}, Priority: PriorityHigh}},
+		{"Gym every monday", Result{Title: "Gym", Deadline: at(2026, 3, 9, 23, 59), Recurrence: "FREQ=WEEKLY;BYDAY=MO"}},
+		{"Water plants every 3 days", Result{Title: "Water plants", Deadline: at(2026, 3, 4, 23, 59), Recurrence: "FREQ=DAILY;INTERVAL=3"}},
+		{"Backup weekly", Result{Title: "Backup", Deadline: at(2026, 3, 4, 23, 59), Recurrence: "FREQ=WEEKLY"}},
+		{"Standup weekdays at 9:15", Result{Title: "Standup", Deadline: at(2026, 3, 5, 9, 15), Recurrence: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"}},
+		{"Звіт щопонеділка о 10", Result{Title: "Звіт", Deadline: at(2026, 3, 9, 10, 0), Recurrence: "FREQ=WEEKLY;BYDAY=MO"}},
+		{"Оплата кожного 15-го числа", Result{Title: "Оплата", Deadline: at(2026, 3, 15, 23, 59), Recurrence: "FREQ=MONTHLY;BYMONTHDAY=15"}},
+
+		// Recurrences more often than daily aren't supported and stay in the title.
+		{"Drink water every 2 hours", Result{Title: "Drink water every 2 hours", Unparsed: []string{"every 2 hours"}}},
+		{"Stretch every hour #health", Result{Title: "Stretch every hour", Tags: []string{"health"}, Unparsed: []string{"every hour"}}},
+		{"Пити воду кожні 30 хвилин", Result{Title: "Пити воду кожні 30 хвилин", Unparsed: []string{"кожні 30 хвилин"}}},
+		{"Buy 3 apples each", Result{Title: "Buy 3 apples each"}},
+
+		// Times alone.
+		{"Lunch at noon", Result{Title: "Lunch", Deadline: at(2026, 3, 4, 12, 0)}},
+		{"Lunch at midnight", Result{Title: "Lunch", Deadline: at(2026, 3, 4, 23, 59)}},
+		{"Call at 9", Result{Title: "Call", Deadline: at(2026, 3, 5, 9, 0)}},
+		{"Вечеря о 7 вечора", Result{Title: "Вечеря", Deadline: at(2026, 3, 4, 19, 0)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := Parse(tt.input, now)
+			if tt.want.Tags == nil {
+				tt.want.Tags = []string{}
+			}
+			if got.Title != tt.want.Title {
+				t.Errorf("title = %q, want %q", got.Title, tt.want.Title)
+			}
+			if got.Recurrence != tt.want.Recurrence {
+				t.Errorf("recurrence = %q, want %q", got.Recurrence, tt.want.Recurrence)
+			}
+			if got.Priority != tt.want.Priority {
+				t.Errorf("priority = %q, want %q", got.Priority, tt.want.Priority)
+			}
+			if !slices.Equal(got.Tags, tt.want.Tags) {
+				t.Errorf("tags = %q, want %q", got.Tags, tt.want.Tags)
+			}
+			if !slices.Equal(got.Unparsed, tt.want.Unparsed) {
+				t.Errorf("unparsed = %q, want %q", got.Unparsed, tt.want.Unparsed)
+			}
+			switch {
+			case got.Deadline == nil && tt.want.Deadline != nil:
+				t.Errorf("deadline = nil, want %v", tt.want.Deadline)
+			case got.Deadline != nil && tt.want.Deadline == nil:
+				t.Errorf("deadline = %v, want nil", got.Deadline)
+			case got.Deadline != nil && !got.Deadline.Equal(*tt.want.Deadline):
+				t.Errorf("deadline = %v, want %v", got.Deadline, tt.want.Deadline)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"errors"
+	"todolist/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+type AccessTokenRepository struct {
+	db *sqlx.DB
+}
+
+func NewAccessTokenRepository(db *sqlx.DB) *AccessTokenRepository {
+	return &AccessTokenRepository{db: db}
+}
+
+func (r *AccessTokenRepository) Create(t *models.AccessToken) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO access_tokens (id, user_id, name, token_hash, scopes, expires_at, created_at)
+		VALUES (:id, :user_id, :name, :token_hash, :scopes, :expires_at, :created_at)`, t)
+	return err
+}
+
+func (r *AccessTokenRepository) GetByHash(tokenHash string) (*models.AccessToken, error) {
+	var t models.AccessToken
+	err := r.db.Get(&t, `SELECT * FROM access_tokens WHERE token_hash = $1`, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *AccessTokenRepository) GetByUserID(userId string) ([]models.AccessToken, error) {
+	tokens := []models.AccessToken{}
+	err := r.db.Select(&tokens, `SELECT * FROM access_tokens WHERE user_id = $1 ORDER BY created_at DESC`, userId)
+	return tokens, err
+}
+
+func (r *AccessTokenRepository) Touch(id string) error {
+	_, err := r.db.Exec(`UPDATE access_tokens SET last_used_at = NOW() WHERE id = $1`, id)
+	return err
+}
+
+// DeleteByUserID removes all of the user's tokens.
+func (r *AccessTokenRepository) DeleteByUserID(userId string) error {
+	_, err := r.db.Exec(`DELETE FROM access_tokens WHERE user_id = $1`, userId)
+	return err
+}
+
+func (r *AccessTokenRepository) Delete(id, userId string) error {
+	res, err := r.db.Exec(`DELETE FROM access_tokens WHERE id = $1 AND user_id = $2`, id, userId)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("no rows affected")
+	}
+	return nil
+}
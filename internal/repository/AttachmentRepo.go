@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"errors"
+	"todolist/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+type AttachmentRepository struct {
+	db *sqlx.DB
+}
+
+func NewAttachmentRepository(db *sqlx.DB) *AttachmentRepository {
+	return &AttachmentRepository{db: db}
+}
+
+func (r *AttachmentRepository) Create(a *models.TodoAttachment) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO todo_attachments (id, todo_id, user_id, filename, content_type, size, storage_key, created_at)
+		VALUES (:id, :todo_id, :user_id, :filename, :content_type, :size, :storage_key, :created_at)`, a)
+	return err
+}
+
+func (r *AttachmentRepository) GetByID(id, todoId string) (*models.TodoAttachment, error) {
+	var a models.TodoAttachment
+	err := r.db.Get(&a, `SELECT * FROM todo_attachments WHERE id = $1 AND todo_id = $2`, id, todoId)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *AttachmentRepository) GetListByTodoID(todoId string) ([]models.TodoAttachment, error) {
+	attachments := []models.TodoAttachment{}
+	err := r.db.Select(&attachments, `SELECT * FROM todo_attachments WHERE todo_id = $1 ORDER BY created_at`, todoId)
+	return attachments, err
+}
+
+// GetKeysRemovedWithUser returns the storage keys of every attachment that is
+// deleted together with the user: their own uploads and all files in their
+// personal workspace and in team workspaces they are the last member of.
+func (r *AttachmentRepository) GetKeysRemovedWithUser(userId string) ([]string, error) {
+	keys := []string{}
+	err := r.db.Select(&keys, `
+		SELECT a.storage_key
+		FROM todo_attachments a
+		JOIN todos t ON t.id = a.todo_id
+		LEFT JOIN workspaces w ON w.id = t.workspace_id
+		WHERE a.user_id = $1 OR w.personal_owner_id = $1
+		   OR (w.personal_owner_id IS NULL
+		       AND EXISTS (SELECT 1 FROM workspace_members m WHERE m.workspace_id = w.id AND m.user_id = $1)
+		       AND NOT EXISTS (SELECT 1 FROM workspace_members m WHERE m.workspace_id = w.id AND m.user_id <> $1))`, userId)
+	return keys, err
+}
+
+// GetKeysByWorkspaceID returns the storage keys of every attachment on the
+// workspace's todos.
+func (r *AttachmentRepository) GetKeysByWorkspaceID(workspaceId string) ([]string, error) {
+	keys := []string{}
+	err := r.db.Select(&keys, `
+		SELECT a.storage_key
+		FROM todo_attachments a
+		JOIN todos t ON t.id = a.todo_id
+		WHERE t.workspace_id = $1`, workspaceId)
+	return keys, err
+}
+
+func (r *AttachmentRepository) Delete(id, todoId string) error {
+	res, err := r.db.Exec(`DELETE FROM todo_attachments WHERE id = $1 AND todo_id = $2`, id, todoId)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("no rows affected")
+	}
+	return nil
+}
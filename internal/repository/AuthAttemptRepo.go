@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"time"
+	"todolist/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+type AuthAttemptRepository struct {
+	db *sqlx.DB
+}
+
+func NewAuthAttemptRepository(db *sqlx.DB) *AuthAttemptRepository {
+	return &AuthAttemptRepository{db: db}
+}
+
+func (r *AuthAttemptRepository) Get(key string) (*models.AuthAttempt, error) {
+	var a models.AuthAttempt
+	err := r.db.Get(&a, `SELECT * FROM auth_attempts WHERE key = $1`, key)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// RecordFailure increments the failure counter and returns the new value.
+// Counters idle for longer than window start over, and stale rows of other
+// keys are dropped on the way.
+func (r *AuthAttemptRepository) RecordFailure(key string, window time.Duration) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM auth_attempts WHERE updated_at < NOW() - INTERVAL '1 day' AND key <> $1`, key); err != nil {
+		return 0, err
+	}
+	var failures int
+	err = tx.Get(&failures, `
+		INSERT INTO auth_attempts (key, failures, updated_at) VALUES ($1, 1, NOW())
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN auth_attempts.updated_at < NOW() - $2 * INTERVAL '1 second' THEN 1 ELSE auth_attempts.failures + 1 END,
+			updated_at = NOW()
+		RETURNING failures`, key, window.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return failures, tx.Commit()
+}
+
+func (r *AuthAttemptRepository) Lock(key string, until time.Time) error {
+	_, err := r.db.Exec(`UPDATE auth_attempts SET locked_until = $2 WHERE key = $1`, key, until)
+	return err
+}
+
+func (r *AuthAttemptRepository) Reset(key string) error {
+	_, err := r.db.Exec(`DELETE FROM auth_attempts WHERE key = $1`, key)
+	return err
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"todolist/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+type ChatRepository struct {
+	db *sqlx.DB
+}
+
+func NewChatRepository(db *sqlx.DB) *ChatRepository {
+	return &ChatRepository{db: db}
+}
+
+func (r *ChatRepository) CreateLinkCode(c *models.ChatLinkCode) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO chat_link_codes (code, user_id, expires_at)
+		VALUES (:code, :user_id, :expires_at)`, c)
+	return err
+}
+
+// ConsumeLinkCode deletes an unexpired code and returns its owner, so each
+// code can be used only once.
+func (r *ChatRepository) ConsumeLinkCode(code string) (string, error) {
+	var userId string
+	err := r.db.Get(&userId, `
+		DELETE FROM chat_link_codes WHERE code = $1 AND expires_at > NOW()
+		RETURNING user_id`, code)
+	return userId, err
+}
+
+func (r *ChatRepository) DeleteExpiredLinkCodes() error {
+	_, err := r.db.Exec(`DELETE FROM chat_link_codes WHERE expires_at <= NOW()`)
+	return err
+}
+
+func (r *ChatRepository) UpsertLink(l *models.ChatLink) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO chat_links (provider, team_id, chat_user_id, user_id, created_at)
+		VALUES (:provider, :team_id, :chat_user_id, :user_id, :created_at)
+		ON CONFLICT (provider, team_id, chat_user_id)
+		DO UPDATE SET user_id = EXCLUDED.user_id, created_at = EXCLUDED.created_at`, l)
+	return err
+}
+
+func (r *ChatRepository) GetLink(provider, teamId, chatUserId string) (*models.ChatLink, error) {
+	var l models.ChatLink
+	err := r.db.Get(&l, `
+		SELECT * FROM chat_links WHERE provider = $1 AND team_id = $2 AND chat_user_id = $3`,
+		provider, teamId, chatUserId)
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *ChatRepository) GetLinksByUserID(userId string) ([]models.ChatLink, error) {
+	links := []models.ChatLink{}
+	err := r.db.Select(&links, `SELECT * FROM chat_links WHERE user_id = $1 ORDER BY created_at`, userId)
+	return links, err
+}
+
+func (r *ChatRepository) DeleteLink(provider, teamId, chatUserId string) error {
+	_, err := r.db.Exec(`
+		DELETE FROM chat_links WHERE provider = $1 AND team_id = $2 AND chat_user_id = $3`,
+		provider, teamId, chatUserId)
+	return err
+}
+
+func (r *ChatRepository) DeleteLinksByUserID(userId string) error {
+	_, err := r.db.Exec(`DELETE FROM chat_links WHERE user_id = $1`, userId)
+	return err
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"errors"
+	"todolist/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+type CommentRepository struct {
+	db *sqlx.DB
+}
+
+func NewCommentRepository(db *sqlx.DB) *CommentRepository {
+	return &CommentRepository{db: db}
+}
+
+const selectCommentQuery = `
+	SELECT c.*, u.username
+	FROM todo_comments c
+	JOIN users u ON u.id = c.user_id
+`
+
+func (r *CommentRepository) Create(comment *models.TodoComment) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO todo_comments (id, todo_id, user_id, body, created_at, updated_at)
+		VALUES (:id, :todo_id, :user_id, :body, :created_at, :updated_at)`, comment)
+	return err
+}
+
+func (r *CommentRepository) GetByID(id, todoId string) (*models.TodoComment, error) {
+	var comment models.TodoComment
+	err := r.db.Get(&comment, selectCommentQuery+` WHERE c.id = $1 AND c.todo_id = $2`, id, todoId)
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (r *CommentRepository) GetListByTodoID(todoId string) ([]models.TodoComment, error) {
+	comments := []models.TodoComment{}
+	err := r.db.Select(&comments, selectCommentQuery+` WHERE c.todo_id = $1 ORDER BY c.created_at`, todoId)
+	return comments, err
+}
+
+func (r *CommentRepository) Update(comment *models.TodoComment) error {
+	_, err := r.db.NamedExec(`
+		UPDATE todo_comments SET body = :body, updated_at = :updated_at
+		WHERE id = :id AND todo_id = :todo_id`, comment)
+	return err
+}
+
+func (r *CommentRepository) Delete(id, todoId string) error {
+	res, err := r.db.Exec(`DELETE FROM todo_comments WHERE id = $1 AND todo_id = $2`, id, todoId)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("no rows affected")
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"todolist/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+type InboundRepository struct {
+	db *sqlx.DB
+}
+
+func NewInboundRepository(db *sqlx.DB) *InboundRepository {
+	return &InboundRepository{db: db}
+}
+
+func (r *InboundRepository) Create(a *models.InboundAddress) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO inbound_addresses (user_id, token, allowed_senders, created_at)
+		VALUES (:user_id, :token, :allowed_senders, :created_at)`, a)
+	return err
+}
+
+func (r *InboundRepository) GetByUserID(userId string) (*models.InboundAddress, error) {
+	var a models.InboundAddress
+	err := r.db.Get(&a, `SELECT * FROM inbound_addresses WHERE user_id = $1`, userId)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *InboundRepository) GetByToken(token string) (*models.InboundAddress, error) {
+	var a models.InboundAddress
+	err := r.db.Get(&a, `SELECT * FROM inbound_addresses WHERE token = $1`, token)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *InboundRepository) Update(a *models.InboundAddress) error {
+	_, err := r.db.NamedExec(`
+		UPDATE inbound_addresses SET token = :token, allowed_senders = :allowed_senders
+		WHERE user_id = :user_id`, a)
+	return err
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"todolist/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+type OIDCStateRepository struct {
+	db *sqlx.DB
+}
+
+func NewOIDCStateRepository(db *sqlx.DB) *OIDCStateRepository {
+	return &OIDCStateRepository{db: db}
+}
+
+// Create stores a started login and drops expired ones.
+func (r *OIDCStateRepository) Create(s *models.OIDCState) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM oidc_states WHERE expires_at < NOW()`); err != nil {
+		return err
+	}
+	_, err = tx.NamedExec(`
+		INSERT INTO oidc_states (state, provider, user_id, nonce, code_verifier, expires_at)
+		VALUES (:state, :provider, :user_id, :nonce, :code_verifier, :expires_at)`, s)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Take returns a started login and deletes it, so every state works once.
+func (r *OIDCStateRepository) Take(state string) (*models.OIDCState, error) {
+	var s models.OIDCState
+	err := r.db.Get(&s, `DELETE FROM oidc_states WHERE state = $1 RETURNING *`, state)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"todolist/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+type PasswordResetRepository struct {
+	db *sqlx.DB
+}
+
+func NewPasswordResetRepository(db *sqlx.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{db: db}
+}
+
+// Create stores a new token and drops the user's older ones, so only the
+// latest email works.
+func (r *PasswordResetRepository) Create(t *models.PasswordResetToken) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`DELETE FROM password_reset_tokens WHERE user_id = $1`, t.UserID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.NamedExec(`
+		INSERT INTO password_reset_tokens (id, user_id, token_hash, expires_at, created_at)
+		VALUES (:id, :user_id, :token_hash, :expires_at, :created_at)`, t)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *PasswordResetRepository) GetByHash(tokenHash string) (*models.PasswordResetToken, error) {
+	var t models.PasswordResetToken
+	err := r.db.Get(&t, `SELECT * FROM password_reset_tokens WHERE token_hash = $1`, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// MarkUsed spends a token. It reports false when it was already used.
+func (r *PasswordResetRepository) MarkUsed(id string) (bool, error) {
+	res, err := r.db.Exec(`UPDATE password_reset_tokens SET used_at = NOW() WHERE id = $1 AND used_at IS NULL`, id)
+	if err != nil {
+		return false, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return count == 1, nil
+}
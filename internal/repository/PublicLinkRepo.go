@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"errors"
+	"todolist/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+type PublicLinkRepository struct {
+	db *sqlx.DB
+}
+
+func NewPublicLinkRepository(db *sqlx.DB) *PublicLinkRepository {
+	return &PublicLinkRepository{db: db}
+}
+
+const selectPublicLinkQuery = `
+	SELECT l.*, l.password_hash <> '' AS has_password,
+		ARRAY(SELECT lt.todo_id FROM public_link_todos lt WHERE lt.link_id = l.id) AS todo_ids
+	FROM public_links l
+`
+
+func (r *PublicLinkRepository) Create(link *models.PublicLink) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.NamedExec(`
+		INSERT INTO public_links (id, owner_id, name, token_hash, password_hash, expires_at, created_at)
+		VALUES (:id, :owner_id, :name, :token_hash, :password_hash, :expires_at, :created_at)`, link)
+	if err != nil {
+		return err
+	}
+	for _, todoId := range link.TodoIDs {
+		_, err = tx.Exec(`INSERT INTO public_link_todos (link_id, todo_id) VALUES ($1, $2)`, link.Id, todoId)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *PublicLinkRepository) GetListByOwnerID(ownerId string) ([]models.PublicLink, error) {
+	links := []models.PublicLink{}
+	err := r.db.Select(&links, selectPublicLinkQuery+` WHERE l.owner_id = $1 ORDER BY l.created_at`, ownerId)
+	return links, err
+}
+
+func (r *PublicLinkRepository) GetByTokenHash(tokenHash string) (*models.PublicLink, error) {
+	var link models.PublicLink
+	err := r.db.Get(&link, selectPublicLinkQuery+` WHERE l.token_hash = $1`, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// GetTodos returns the link's todos that still belong to its owner.
+func (r *PublicLinkRepository) GetTodos(link *models.PublicLink) ([]models.PublicTodo, error) {
+	todos := []models.PublicTodo{}
+	err := r.db.Select(&todos, `
+		SELECT t.title, t.description, t.completed, t.deadline, t.priority, t.tags
+		FROM public_link_todos lt
+		JOIN todos t ON t.id = lt.todo_id
+		WHERE lt.link_id = $1 AND t.user_id = $2
+		ORDER BY t.deadline`, link.Id, link.OwnerID)
+	return todos, err
+}
+
+func (r *PublicLinkRepository) Delete(id, ownerId string) error {
+	res, err := r.db.Exec(`DELETE FROM public_links WHERE id = $1 AND owner_id = $2`, id, ownerId)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("no rows affected")
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+type RecoveryCodeRepository struct {
+	db *sqlx.DB
+}
+
+func NewRecoveryCodeRepository(db *sqlx.DB) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{db: db}
+}
+
+// Replace swaps the user's recovery codes for new ones in one transaction.
+func (r *RecoveryCodeRepository) Replace(userId string, codeHashes []string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM recovery_codes WHERE user_id = $1`, userId); err != nil {
+		return err
+	}
+	for _, hash := range codeHashes {
+		_, err := tx.Exec(`INSERT INTO recovery_codes (id, user_id, code_hash) VALUES ($1, $2, $3)`, uuid.New().String(), userId, hash)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Use spends a recovery code. It reports false for unknown or used codes.
+func (r *RecoveryCodeRepository) Use(userId, codeHash string) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE recovery_codes SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`, userId, codeHash)
+	if err != nil {
+		return false, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return count == 1, nil
+}
+
+func (r *RecoveryCodeRepository) DeleteAll(userId string) error {
+	_, err := r.db.Exec(`DELETE FROM recovery_codes WHERE user_id = $1`, userId)
+	return err
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"todolist/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+type RefreshTokenRepository struct {
+	db *sqlx.DB
+}
+
+func NewRefreshTokenRepository(db *sqlx.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+// Create stores a new token and drops the user's expired ones.
+func (r *RefreshTokenRepository) Create(t *models.RefreshToken) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`DELETE FROM refresh_tokens WHERE user_id = $1 AND expires_at < NOW()`, t.UserID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.NamedExec(`
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at, created_at)
+		VALUES (:id, :user_id, :family_id, :token_hash, :expires_at, :created_at)`, t)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *RefreshTokenRepository) GetByHash(tokenHash string) (*models.RefreshToken, error) {
+	var t models.RefreshToken
+	err := r.db.Get(&t, `SELECT * FROM refresh_tokens WHERE token_hash = $1`, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// MarkUsed spends a token. It reports false when the token was already used
+// or revoked, so two concurrent refreshes cannot both succeed.
+func (r *RefreshTokenRepository) MarkUsed(id string) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE refresh_tokens SET used_at = NOW()
+		WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL`, id)
+	if err != nil {
+		return false, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return count == 1, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"errors"
+	"todolist/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+type SessionRepository struct {
+	db *sqlx.DB
+}
+
+func NewSessionRepository(db *sqlx.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+func (r *SessionRepository) Create(session *models.Session) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO sessions (id, user_id, device, ip, user_agent, created_at, last_seen_at)
+		VALUES (:id, :user_id, :device, :ip, :user_agent, :created_at, :last_seen_at)`, session)
+	return err
+}
+
+func (r *SessionRepository) GetByID(id, userId string) (*models.Session, error) {
+	var session models.Session
+	err := r.db.Get(&session, `SELECT * FROM sessions WHERE id = $1 AND user_id = $2`, id, userId)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *SessionRepository) GetListByUserID(userId string) ([]models.Session, error) {
+	sessions := []models.Session{}
+	err := r.db.Select(&sessions, `SELECT * FROM sessions WHERE user_id = $1 ORDER BY last_seen_at DESC`, userId)
+	return sessions, err
+}
+
+func (r *SessionRepository) Touch(id, ip string) error {
+	_, err := r.db.Exec(`UPDATE sessions SET last_seen_at = NOW(), ip = $2 WHERE id = $1`, id, ip)
+	return err
+}
+
+func (r *SessionRepository) Delete(id, userId string) error {
+	res, err := r.db.Exec(`DELETE FROM sessions WHERE id = $1 AND user_id = $2`, id, userId)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("no rows affected")
+	}
+	return nil
+}
+
+// DeleteAllExcept removes the user's sessions other than keepId. An empty
+// keepId removes all of them.
+func (r *SessionRepository) DeleteAllExcept(userId, keepId string) error {
+	_, err := r.db.Exec(`DELETE FROM sessions WHERE user_id = $1 AND id <> $2`, userId, keepId)
+	return err
+}
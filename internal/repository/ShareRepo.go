@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"errors"
+	"time"
+	"todolist/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+type ShareRepository struct {
+	db *sqlx.DB
+}
+
+func NewShareRepository(db *sqlx.DB) *ShareRepository {
+	return &ShareRepository{db: db}
+}
+
+const selectShareQuery = `
+	SELECT s.*, o.username AS owner_username, g.username AS grantee_username, t.title AS todo_title
+	FROM todo_shares s
+	JOIN users o ON o.id = s.owner_id
+	JOIN users g ON g.id = s.grantee_id
+	LEFT JOIN todos t ON t.id = s.todo_id
+`
+
+// sharedTodoQuery selects todos visible to a grantee through either a
+// single-todo share or a whole-list share, which covers the owner's personal
+// workspace. When both exist the editor role wins.
+const sharedTodoQuery = `
+	SELECT DISTINCT ON (t.id) t.*, o.username AS shared_by, s.role AS permission,
+		(SELECT COUNT(*) FROM todo_comments c WHERE c.todo_id = t.id) AS comment_count
+	FROM todo_shares s
+	JOIN todos t ON t.id = s.todo_id OR (s.todo_id IS NULL AND t.workspace_id =
+		(SELECT w.id FROM workspaces w WHERE w.personal_owner_id = s.owner_id))
+	JOIN users o ON o.id = t.user_id
+	WHERE s.grantee_id = $1
+`
+
+func (r *ShareRepository) Create(share *models.TodoShare) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO todo_shares (id, owner_id, todo_id, grantee_id, role, created_at)
+		VALUES (:id, :owner_id, :todo_id, :grantee_id, :role, :created_at)`, share)
+	return err
+}
+
+func (r *ShareRepository) GetByID(id string) (*models.TodoShare, error) {
+	var share models.TodoShare
+	err := r.db.Get(&share, selectShareQuery+` WHERE s.id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+func (r *ShareRepository) GetListByOwnerID(ownerId string) ([]models.TodoShare, error) {
+	shares := []models.TodoShare{}
+	err := r.db.Select(&shares, selectShareQuery+` WHERE s.owner_id = $1 ORDER BY s.created_at`, ownerId)
+	return shares, err
+}
+
+func (r *ShareRepository) GetListByGranteeID(granteeId string) ([]models.TodoShare, error) {
+	shares := []models.TodoShare{}
+	err := r.db.Select(&shares, selectShareQuery+` WHERE s.grantee_id = $1 ORDER BY s.created_at`, granteeId)
+	return shares, err
+}
+
+func (r *ShareRepository) UpdateRole(id, role string) error {
+	_, err := r.db.Exec(`UPDATE todo_shares SET role = $2 WHERE id = $1`, id, role)
+	return err
+}
+
+func (r *ShareRepository) Delete(id string) error {
+	res, err := r.db.Exec(`DELETE FROM todo_shares WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("no rows affected")
+	}
+	return nil
+}
+
+// GetGranteeIDs returns the users a todo is shared with, on its own or with
+// its owner's whole personal list.
+func (r *ShareRepository) GetGranteeIDs(todoId string) ([]string, error) {
+	ids := []string{}
+	err := r.db.Select(&ids, `
+		SELECT DISTINCT s.grantee_id
+		FROM todos t
+		JOIN todo_shares s ON s.todo_id = t.id OR (s.todo_id IS NULL AND t.workspace_id =
+			(SELECT w.id FROM workspaces w WHERE w.personal_owner_id = s.owner_id))
+		WHERE t.id = $1`, todoId)
+	return ids, err
+}
+
+func (r *ShareRepository) GetSharedTodos(granteeId string) ([]models.Todo, error) {
+	todos := []models.Todo{}
+	err := r.db.Select(&todos, sharedTodoQuery+` ORDER BY t.id, s.role = 'editor' DESC`, granteeId)
+	return todos, err
+}
+
+// GetSharedTodosPage pages through GetSharedTodos ordered by deadline and id.
+func (r *ShareRepository) GetSharedTodosPage(granteeId string, afterDeadline time.Time, afterId string, limit int) ([]models.Todo, error) {
+	todos := []models.Todo{}
+	err := r.db.Select(&todos, `
+		SELECT * FROM (`+sharedTodoQuery+` ORDER BY t.id, s.role = 'editor' DESC) shared
+		WHERE (shared.deadline, shared.id) > ($2, $3)
+		ORDER BY shared.deadline, shared.id
+		LIMIT $4`, granteeId, afterDeadline, afterId, limit)
+	return todos, err
+}
+
+func (r *ShareRepository) GetSharedTodo(todoId, granteeId string) (*models.Todo, error) {
+	var todo models.Todo
+	err := r.db.Get(&todo, sharedTodoQuery+` AND t.id = $2 ORDER BY t.id, s.role = 'editor' DESC`, granteeId, todoId)
+	if err != nil {
+		return nil, err
+	}
+	return &todo, nil
+}
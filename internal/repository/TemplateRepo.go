@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"errors"
+	"todolist/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+type TemplateRepository struct {
+	db *sqlx.DB
+}
+
+func NewTemplateRepository(db *sqlx.DB) *TemplateRepository {
+	return &TemplateRepository{db: db}
+}
+
+const insertTemplateItemQuery = `
+	INSERT INTO todo_template_items (id, template_id, position, title, description, deadline_offset, priority, tags)
+	VALUES (:id, :template_id, :position, :title, :description, :deadline_offset, :priority, :tags)
+`
+
+func insertTemplateItems(tx *sqlx.Tx, items []models.TodoTemplateItem) error {
+	for i := range items {
+		if _, err := tx.NamedExec(insertTemplateItemQuery, &items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *TemplateRepository) Create(t *models.TodoTemplate) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.NamedExec(`
+		INSERT INTO todo_templates (id, user_id, name, description, created_at, updated_at)
+		VALUES (:id, :user_id, :name, :description, :created_at, :updated_at)`, t)
+	if err != nil {
+		return err
+	}
+	if err := insertTemplateItems(tx, t.Items); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *TemplateRepository) getItems(templateId string) ([]models.TodoTemplateItem, error) {
+	items := []models.TodoTemplateItem{}
+	err := r.db.Select(&items, `SELECT * FROM todo_template_items WHERE template_id = $1 ORDER BY position`, templateId)
+	return items, err
+}
+
+func (r *TemplateRepository) GetByID(id, userId string) (*models.TodoTemplate, error) {
+	var t models.TodoTemplate
+	err := r.db.Get(&t, `SELECT * FROM todo_templates WHERE id = $1 AND user_id = $2`, id, userId)
+	if err != nil {
+		return nil, err
+	}
+	if t.Items, err = r.getItems(t.Id); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *TemplateRepository) GetListByUserID(userId string) ([]models.TodoTemplate, error) {
+	templates := []models.TodoTemplate{}
+	err := r.db.Select(&templates, `SELECT * FROM todo_templates WHERE user_id = $1 ORDER BY name`, userId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range templates {
+		if templates[i].Items, err = r.getItems(templates[i].Id); err != nil {
+			return nil, err
+		}
+	}
+	return templates, nil
+}
+
+// Update replaces the template fields and its whole item list.
+func (r *TemplateRepository) Update(t *models.TodoTemplate) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.NamedExec(`
+		UPDATE todo_templates SET name = :name, description = :description, updated_at = :updated_at
+		WHERE id = :id AND user_id = :user_id`, t)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM todo_template_items WHERE template_id = $1`, t.Id); err != nil {
+		return err
+	}
+	if err := insertTemplateItems(tx, t.Items); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *TemplateRepository) Delete(id, userId string) error {
+	res, err := r.db.Exec(`DELETE FROM todo_templates WHERE id = $1 AND user_id = $2`, id, userId)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("no rows affected")
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"errors"
+	"time"
 	"todolist/internal/models"
 
 	"github.com/jmoiron/sqlx"
@@ -16,31 +17,62 @@ func NewTodoRepository(db *sqlx.DB) *TodoRepository {
 	return &TodoRepository{db: db}
 }
 
+const insertTodoQuery = `
+	INSERT INTO todos (id, user_id, workspace_id, title, description, completed, created_at, updated_at, deadline, priority, tags, recurrence)
+	VALUES (:id, :user_id, :workspace_id, :title, :description, :completed, :created_at, :updated_at, :deadline, :priority, :tags, :recurrence)
+`
+
+// selectTodoQuery adds the number of comments to every todo row.
+const selectTodoQuery = `
+	SELECT t.*, (SELECT COUNT(*) FROM todo_comments c WHERE c.todo_id = t.id) AS comment_count
+	FROM todos t
+`
+
 func (r *TodoRepository) Create(todo *models.Todo) error {
-	query := `
-		INSERT INTO todos (id, user_id, title, description, completed, created_at, updated_at, deadline)
-		VALUES (:id, :user_id, :title, :description, :completed, :created_at, :updated_at, :deadline)
-	`
-	_, err := r.db.NamedExec(query, todo)
+	_, err := r.db.NamedExec(insertTodoQuery, todo)
 	return err
 }
 
-func (r *TodoRepository) GetListByUserID(userId string) ([]models.Todo, error) {
-	query := `
-	SELECT * FROM todos WHERE user_id = $1
-	`
+// CreateMany inserts all todos in one transaction: either every todo is
+// created or none is.
+func (r *TodoRepository) CreateMany(todos []*models.Todo) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, todo := range todos {
+		if _, err := tx.NamedExec(insertTodoQuery, todo); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *TodoRepository) GetListByWorkspaceID(workspaceId string) ([]models.Todo, error) {
+	query := selectTodoQuery + ` WHERE t.workspace_id = $1`
 	var todos []models.Todo
-	err := r.db.Select(&todos, query, userId)
+	err := r.db.Select(&todos, query, workspaceId)
 	if err != nil {
 		return nil, err
 	}
 	return todos, nil
 }
 
-func (r *TodoRepository) GetByID(id, userId string) (*models.Todo, error) {
-	query := `SELECT * FROM todos WHERE id = $1 AND user_id = $2`
+// GetPageByWorkspaceID returns up to limit todos ordered by deadline and id,
+// starting after the given deadline and id.
+func (r *TodoRepository) GetPageByWorkspaceID(workspaceId string, afterDeadline time.Time, afterId string, limit int) ([]models.Todo, error) {
+	query := selectTodoQuery + ` WHERE t.workspace_id = $1 AND (t.deadline, t.id) > ($2, $3) ORDER BY t.deadline, t.id LIMIT $4`
+	todos := []models.Todo{}
+	err := r.db.Select(&todos, query, workspaceId, afterDeadline, afterId, limit)
+	return todos, err
+}
+
+func (r *TodoRepository) GetByID(id, workspaceId string) (*models.Todo, error) {
+	query := selectTodoQuery + ` WHERE t.id = $1 AND t.workspace_id = $2`
 	var todo models.Todo
-	err := r.db.Get(&todo, query, id, userId)
+	err := r.db.Get(&todo, query, id, workspaceId)
 	if err != nil {
 		return nil, err
 	}
@@ -50,8 +82,9 @@ func (r *TodoRepository) GetByID(id, userId string) (*models.Todo, error) {
 func (r *TodoRepository) Update(todo *models.Todo) error {
 	_, err := r.db.NamedExec(`
 		UPDATE todos 
-		SET title = :title, description = :description, completed = :completed, updated_at = CURRENT_TIMESTAMP, deadline = :deadline
-		WHERE id = :id`, todo)
+		SET title = :title, description = :description, completed = :completed, updated_at = CURRENT_TIMESTAMP, deadline = :deadline,
+			priority = :priority, tags = :tags, recurrence = :recurrence
+		WHERE id = :id AND workspace_id = :workspace_id`, todo)
 
 	if err != nil {
 		return err
@@ -60,8 +93,8 @@ func (r *TodoRepository) Update(todo *models.Todo) error {
 	return nil
 }
 
-func (r *TodoRepository) Delete(id, userId string) error {
-	res, err := r.db.Exec("DELETE FROM todos WHERE id = $1 AND user_id = $2", id, userId)
+func (r *TodoRepository) Delete(id, workspaceId string) error {
+	res, err := r.db.Exec("DELETE FROM todos WHERE id = $1 AND workspace_id = $2", id, workspaceId)
 	if err != nil {
 		return err
 	}
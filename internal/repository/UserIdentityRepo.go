@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"errors"
+	"todolist/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+type UserIdentityRepository struct {
+	db *sqlx.DB
+}
+
+func NewUserIdentityRepository(db *sqlx.DB) *UserIdentityRepository {
+	return &UserIdentityRepository{db: db}
+}
+
+func (r *UserIdentityRepository) Create(i *models.UserIdentity) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO user_identities (id, user_id, provider, subject, email, created_at)
+		VALUES (:id, :user_id, :provider, :subject, :email, :created_at)`, i)
+	return err
+}
+
+func (r *UserIdentityRepository) GetByProviderSubject(provider, subject string) (*models.UserIdentity, error) {
+	var i models.UserIdentity
+	err := r.db.Get(&i, `SELECT * FROM user_identities WHERE provider = $1 AND subject = $2`, provider, subject)
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+func (r *UserIdentityRepository) GetByUserID(userId string) ([]models.UserIdentity, error) {
+	identities := []models.UserIdentity{}
+	err := r.db.Select(&identities, `SELECT * FROM user_identities WHERE user_id = $1 ORDER BY created_at`, userId)
+	return identities, err
+}
+
+func (r *UserIdentityRepository) Touch(id string) error {
+	_, err := r.db.Exec(`UPDATE user_identities SET last_used_at = NOW() WHERE id = $1`, id)
+	return err
+}
+
+func (r *UserIdentityRepository) Delete(id, userId string) error {
+	res, err := r.db.Exec(`DELETE FROM user_identities WHERE id = $1 AND user_id = $2`, id, userId)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("no rows affected")
+	}
+	return nil
+}
@@ -7,6 +7,18 @@ import (
 	_ "github.com/lib/pq"
 )
 
+// selectUserQuery adds the address of a pending email change and the first
+// linked login provider to the user's columns.
+const selectUserQuery = `
+	SELECT u.*, COALESCE((
+		SELECT v.email FROM verification_tokens v
+		WHERE v.user_id = u.id AND v.purpose = 'email_change' AND v.used_at IS NULL AND v.expires_at > NOW()
+	), '') AS pending_email, COALESCE((
+		SELECT i.provider FROM user_identities i
+		WHERE i.user_id = u.id ORDER BY i.created_at LIMIT 1
+	), '') AS oauth_provider
+	FROM users u`
+
 type UsersRepository struct {
 	db *sqlx.DB
 }
@@ -17,7 +29,7 @@ func NewUsersRepository(db *sqlx.DB) *UsersRepository {
 
 func (r *UsersRepository) GetByID(id string) (*models.User, error) {
 	var u models.User
-	err := r.db.Get(&u, "SELECT * FROM users WHERE id = $1", id)
+	err := r.db.Get(&u, selectUserQuery+" WHERE u.id = $1", id)
 	if err != nil {
 		return nil, err
 	}
@@ -26,7 +38,7 @@ func (r *UsersRepository) GetByID(id string) (*models.User, error) {
 
 func (r *UsersRepository) GetByEmail(email string) (*models.User, error) {
 	var u models.User
-	err := r.db.Get(&u, "SELECT * FROM users WHERE email = $1", email)
+	err := r.db.Get(&u, selectUserQuery+" WHERE u.email = $1", email)
 	if err != nil {
 		return nil, err
 	}
@@ -35,14 +47,17 @@ func (r *UsersRepository) GetByEmail(email string) (*models.User, error) {
 
 func (r *UsersRepository) GetByUsername(username string) (*models.User, error) {
 	var u models.User
-	err := r.db.Get(&u, "SELECT * FROM users WHERE username = $1", username)
+	err := r.db.Get(&u, selectUserQuery+" WHERE u.username = $1", username)
 	if err != nil {
 		return nil, err
 	}
 	return &u, nil
 }
 
-func (r *UsersRepository) CreateWithDefaultTodo(u *models.User, todo *models.Todo) error {
+// CreateWithPersonalWorkspace stores a new user together with their personal
+// workspace, which becomes active, and a welcome todo in it. Users signing up
+// with a provider also get their identity; it is nil otherwise.
+func (r *UsersRepository) CreateWithPersonalWorkspace(u *models.User, ws *models.Workspace, todo *models.Todo, identity *models.UserIdentity) error {
 	tx, err := r.db.Beginx()
 	if err != nil {
 		return err
@@ -59,14 +74,42 @@ func (r *UsersRepository) CreateWithDefaultTodo(u *models.User, todo *models.Tod
 		}
 	}()
 
-	_, err = tx.NamedExec(`INSERT INTO users (id, username, email, password_hash, is_verified, verification_code, verification_code_expires, oauth_provider, oauth_id) VALUES (:id, :username, :email, :password_hash, :is_verified, :verification_code, :verification_code_expires, :oauth_provider, :oauth_id)`, u)
+	_, err = tx.NamedExec(`INSERT INTO users (id, username, email, password_hash, is_verified) VALUES (:id, :username, :email, :password_hash, :is_verified)`, u)
+	if err != nil {
+		return err
+	}
+
+	if identity != nil {
+		_, err = tx.NamedExec(`
+			INSERT INTO user_identities (id, user_id, provider, subject, email, created_at)
+			VALUES (:id, :user_id, :provider, :subject, :email, :created_at)
+		`, identity)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.NamedExec(`
+		INSERT INTO workspaces (id, name, personal_owner_id, created_at)
+		VALUES (:id, :name, :personal_owner_id, :created_at)
+	`, ws)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`INSERT INTO workspace_members (workspace_id, user_id, role, joined_at) VALUES ($1, $2, 'owner', $3)`, ws.Id, u.Id, ws.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE users SET active_workspace_id = $2 WHERE id = $1`, u.Id, ws.Id)
 	if err != nil {
 		return err
 	}
 
 	_, err = tx.NamedExec(`
-		INSERT INTO todos (id, user_id, title, description, completed, created_at, updated_at, deadline)
-		VALUES (:id, :user_id, :title, :description, :completed, :created_at, :updated_at, :deadline)
+		INSERT INTO todos (id, user_id, workspace_id, title, description, completed, created_at, updated_at, deadline)
+		VALUES (:id, :user_id, :workspace_id, :title, :description, :completed, :created_at, :updated_at, :deadline)
 	`, todo)
 	if err != nil {
 		return err
@@ -77,28 +120,75 @@ func (r *UsersRepository) CreateWithDefaultTodo(u *models.User, todo *models.Tod
 
 func (r UsersRepository) Update(u *models.User) error {
 	query := `UPDATE users 
-			SET username = :username, email = :email, pending_email = :pending_email, password_hash = :password_hash, is_verified = :is_verified, verification_code = :verification_code, verification_code_expires = :verification_code_expires
+			SET username = :username, email = :email, password_hash = :password_hash, is_verified = :is_verified
 			WHERE id = :id`
 	_, err := r.db.NamedExec(query, &u)
 	return err
 }
 
-func (r *UsersRepository) Delete(id string) error {
-	_, err := r.db.Exec(`DELETE FROM users WHERE id = $1`, id)
+// SetTOTP stores a (pending or confirmed) TOTP secret. An empty secret
+// turns two-factor authentication off.
+func (r *UsersRepository) SetTOTP(id, secret string, enabled bool) error {
+	_, err := r.db.Exec(`UPDATE users SET totp_secret = $2, totp_enabled = $3, totp_last_step = 0 WHERE id = $1`, id, secret, enabled)
+	return err
+}
+
+// UseTOTPStep records the time step of an accepted code. It reports false
+// when that step (or a later one) was already used, so a code works once.
+func (r *UsersRepository) UseTOTPStep(id string, step int64) (bool, error) {
+	res, err := r.db.Exec(`UPDATE users SET totp_last_step = $2 WHERE id = $1 AND totp_last_step < $2`, id, step)
 	if err != nil {
-		return err
+		return false, err
 	}
-	return nil
+	count, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return count == 1, nil
 }
 
-func (r *UsersRepository) GetByOAuth(provider, oauthID string) (*models.User, error) {
-	query := `
-	SELECT * FROM users WHERE oauth_id = $1 AND provider = $2
-	`
-	var user models.User
-	err := r.db.Get(&user, query, oauthID, provider)
+// Delete removes the user. Todos they created in team workspaces go to the
+// longest-standing remaining owner, and team workspaces they were the last
+// member of are removed; everything else of theirs goes by cascade.
+func (r *UsersRepository) Delete(id string) (err error) {
+	tx, err := r.db.Beginx()
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		} else if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	_, err = tx.Exec(`
+		UPDATE todos t SET user_id = o.user_id
+		FROM (
+			SELECT DISTINCT ON (workspace_id) workspace_id, user_id
+			FROM workspace_members
+			WHERE role = 'owner' AND user_id <> $1
+			ORDER BY workspace_id, joined_at
+		) o
+		WHERE t.workspace_id = o.workspace_id AND t.user_id = $1`, id)
+	if err != nil {
+		return err
 	}
-	return &user, nil
+
+	_, err = tx.Exec(`
+		DELETE FROM workspaces w
+		WHERE w.personal_owner_id IS NULL
+		  AND EXISTS (SELECT 1 FROM workspace_members m WHERE m.workspace_id = w.id AND m.user_id = $1)
+		  AND NOT EXISTS (SELECT 1 FROM workspace_members m WHERE m.workspace_id = w.id AND m.user_id <> $1)`, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM users WHERE id = $1`, id)
+	return err
 }
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"todolist/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+type VerificationTokenRepository struct {
+	db *sqlx.DB
+}
+
+func NewVerificationTokenRepository(db *sqlx.DB) *VerificationTokenRepository {
+	return &VerificationTokenRepository{db: db}
+}
+
+// Create stores a new token and drops the user's earlier one for the same
+// purpose, so only the latest code works.
+func (r *VerificationTokenRepository) Create(t *models.VerificationToken) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`DELETE FROM verification_tokens WHERE user_id = $1 AND purpose = $2`, t.UserID, t.Purpose)
+	if err != nil {
+		return err
+	}
+	_, err = tx.NamedExec(`
+		INSERT INTO verification_tokens (id, user_id, purpose, code_hash, email, expires_at, created_at)
+		VALUES (:id, :user_id, :purpose, :code_hash, :email, :expires_at, :created_at)`, t)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetPending returns the user's unused token for a purpose.
+func (r *VerificationTokenRepository) GetPending(userId, purpose string) (*models.VerificationToken, error) {
+	var t models.VerificationToken
+	err := r.db.Get(&t, `SELECT * FROM verification_tokens WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`, userId, purpose)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// MarkUsed spends a token. It reports false when it was already used.
+func (r *VerificationTokenRepository) MarkUsed(id string) (bool, error) {
+	res, err := r.db.Exec(`UPDATE verification_tokens SET used_at = NOW() WHERE id = $1 AND used_at IS NULL`, id)
+	if err != nil {
+		return false, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return count == 1, nil
+}
+
+func (r *VerificationTokenRepository) Delete(userId, purpose string) error {
+	_, err := r.db.Exec(`DELETE FROM verification_tokens WHERE user_id = $1 AND purpose = $2`, userId, purpose)
+	return err
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"errors"
+	"todolist/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+type WebAuthnRepository struct {
+	db *sqlx.DB
+}
+
+func NewWebAuthnRepository(db *sqlx.DB) *WebAuthnRepository {
+	return &WebAuthnRepository{db: db}
+}
+
+func (r *WebAuthnRepository) CreateCredential(c *models.WebAuthnCredential) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO webauthn_credentials (id, user_id, name, credential_id, credential, created_at)
+		VALUES (:id, :user_id, :name, :credential_id, :credential, :created_at)`, c)
+	return err
+}
+
+func (r *WebAuthnRepository) GetCredentialsByUserID(userId string) ([]models.WebAuthnCredential, error) {
+	credentials := []models.WebAuthnCredential{}
+	err := r.db.Select(&credentials, `SELECT * FROM webauthn_credentials WHERE user_id = $1 ORDER BY created_at`, userId)
+	return credentials, err
+}
+
+// UpdateCredentialUse stores the record returned by a successful login (new
+// sign counter and flags).
+func (r *WebAuthnRepository) UpdateCredentialUse(id, credential string) error {
+	_, err := r.db.Exec(`UPDATE webauthn_credentials SET credential = $2, last_used_at = NOW() WHERE id = $1`, id, credential)
+	return err
+}
+
+func (r *WebAuthnRepository) RenameCredential(id, userId, name string) error {
+	res, err := r.db.Exec(`UPDATE webauthn_credentials SET name = $3 WHERE id = $1 AND user_id = $2`, id, userId, name)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("no rows affected")
+	}
+	return nil
+}
+
+func (r *WebAuthnRepository) DeleteCredential(id, userId string) error {
+	res, err := r.db.Exec(`DELETE FROM webauthn_credentials WHERE id = $1 AND user_id = $2`, id, userId)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("no rows affected")
+	}
+	return nil
+}
+
+// CreateChallenge stores a ceremony and drops expired ones.
+func (r *WebAuthnRepository) CreateChallenge(c *models.WebAuthnChallenge) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM webauthn_challenges WHERE expires_at < NOW()`); err != nil {
+		return err
+	}
+	_, err = tx.NamedExec(`
+		INSERT INTO webauthn_challenges (id, user_id, session_data, expires_at)
+		VALUES (:id, :user_id, :session_data, :expires_at)`, c)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// TakeChallenge returns a ceremony and deletes it, so every challenge can be
+// answered once.
+func (r *WebAuthnRepository) TakeChallenge(id string) (*models.WebAuthnChallenge, error) {
+	var c models.WebAuthnChallenge
+	err := r.db.Get(&c, `DELETE FROM webauthn_challenges WHERE id = $1 RETURNING *`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
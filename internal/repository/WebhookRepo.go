@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"errors"
+	"todolist/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+type WebhookRepository struct {
+	db *sqlx.DB
+}
+
+func NewWebhookRepository(db *sqlx.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) Create(hook *models.Webhook) error {
+	query := `
+		INSERT INTO webhooks (id, user_id, url, secret, events, is_active, failure_count, created_at, updated_at)
+		VALUES (:id, :user_id, :url, :secret, :events, :is_active, :failure_count, :created_at, :updated_at)
+	`
+	_, err := r.db.NamedExec(query, hook)
+	return err
+}
+
+func (r *WebhookRepository) GetByID(id, userId string) (*models.Webhook, error) {
+	var hook models.Webhook
+	err := r.db.Get(&hook, `SELECT * FROM webhooks WHERE id = $1 AND user_id = $2`, id, userId)
+	if err != nil {
+		return nil, err
+	}
+	return &hook, nil
+}
+
+func (r *WebhookRepository) GetListByUserID(userId string) ([]models.Webhook, error) {
+	hooks := []models.Webhook{}
+	err := r.db.Select(&hooks, `SELECT * FROM webhooks WHERE user_id = $1 ORDER BY created_at`, userId)
+	if err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+func (r *WebhookRepository) GetActiveForEvent(userId, event string) ([]models.Webhook, error) {
+	query := `
+	SELECT * FROM webhooks WHERE user_id = $1 AND is_active AND $2 = ANY(events)
+	`
+	var hooks []models.Webhook
+	err := r.db.Select(&hooks, query, userId, event)
+	if err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+func (r *WebhookRepository) Update(hook *models.Webhook) error {
+	_, err := r.db.NamedExec(`
+		UPDATE webhooks
+		SET url = :url, events = :events, is_active = :is_active, failure_count = :failure_count, updated_at = CURRENT_TIMESTAMP
+		WHERE id = :id AND user_id = :user_id`, hook)
+	return err
+}
+
+func (r *WebhookRepository) Delete(id, userId string) error {
+	res, err := r.db.Exec("DELETE FROM webhooks WHERE id = $1 AND user_id = $2", id, userId)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("no rows affected")
+	}
+	return nil
+}
+
+// RecordFailure bumps the consecutive failure counter and disables the webhook
+// once it reaches maxFailures. It reports whether the webhook was disabled.
+func (r *WebhookRepository) RecordFailure(id string, maxFailures int) (bool, error) {
+	var isActive bool
+	err := r.db.Get(&isActive, `
+		UPDATE webhooks
+		SET failure_count = failure_count + 1,
+			is_active = is_active AND failure_count + 1 < $2,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING is_active`, id, maxFailures)
+	if err != nil {
+		return false, err
+	}
+	return !isActive, nil
+}
+
+func (r *WebhookRepository) ResetFailures(id string) error {
+	_, err := r.db.Exec(`UPDATE webhooks SET failure_count = 0 WHERE id = $1 AND failure_count > 0`, id)
+	return err
+}
+
+func (r *WebhookRepository) CreateDelivery(d *models.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (id, webhook_id, event, payload, attempt, status_code, error, success, created_at)
+		VALUES (:id, :webhook_id, :event, :payload, :attempt, :status_code, :error, :success, :created_at)
+	`
+	_, err := r.db.NamedExec(query, d)
+	return err
+}
+
+func (r *WebhookRepository) GetDeliveries(webhookId string, limit int) ([]models.WebhookDelivery, error) {
+	deliveries := []models.WebhookDelivery{}
+	err := r.db.Select(&deliveries, `
+		SELECT * FROM webhook_deliveries WHERE webhook_id = $1 ORDER BY created_at DESC LIMIT $2`, webhookId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"errors"
+	"todolist/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+type WorkspaceRepository struct {
+	db *sqlx.DB
+}
+
+func NewWorkspaceRepository(db *sqlx.DB) *WorkspaceRepository {
+	return &WorkspaceRepository{db: db}
+}
+
+const selectWorkspaceQuery = `
+	SELECT w.*, w.personal_owner_id IS NOT NULL AS is_personal, m.role
+	FROM workspaces w
+	JOIN workspace_members m ON m.workspace_id = w.id
+`
+
+const insertWorkspaceQuery = `
+	INSERT INTO workspaces (id, name, personal_owner_id, created_at)
+	VALUES (:id, :name, :personal_owner_id, :created_at)
+`
+
+const insertWorkspaceMemberQuery = `
+	INSERT INTO workspace_members (workspace_id, user_id, role, joined_at)
+	VALUES (:workspace_id, :user_id, :role, :joined_at)
+`
+
+// Create stores a workspace together with its first member.
+func (r *WorkspaceRepository) Create(ws *models.Workspace, owner *models.WorkspaceMember) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.NamedExec(insertWorkspaceQuery, ws); err != nil {
+		return err
+	}
+	if _, err := tx.NamedExec(insertWorkspaceMemberQuery, owner); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetForMember returns the workspace with the member's role, or sql.ErrNoRows
+// when userId is not a member.
+func (r *WorkspaceRepository) GetForMember(id, userId string) (*models.Workspace, error) {
+	var ws models.Workspace
+	err := r.db.Get(&ws, selectWorkspaceQuery+` WHERE w.id = $1 AND m.user_id = $2`, id, userId)
+	if err != nil {
+		return nil, err
+	}
+	return &ws, nil
+}
+
+func (r *WorkspaceRepository) GetListByUserID(userId string) ([]models.Workspace, error) {
+	workspaces := []models.Workspace{}
+	err := r.db.Select(&workspaces, selectWorkspaceQuery+`
+		WHERE m.user_id = $1 ORDER BY w.personal_owner_id IS NULL, w.name`, userId)
+	return workspaces, err
+}
+
+// GetActiveID returns the user's active workspace, falling back to the
+// personal one if none is set or the user is no longer a member.
+func (r *WorkspaceRepository) GetActiveID(userId string) (string, error) {
+	var id string
+	err := r.db.Get(&id, `
+		SELECT COALESCE(
+			(SELECT m.workspace_id FROM users u
+			 JOIN workspace_members m ON m.workspace_id = u.active_workspace_id AND m.user_id = u.id
+			 WHERE u.id = $1),
+			(SELECT w.id FROM workspaces w WHERE w.personal_owner_id = $1),
+			''
+		)`, userId)
+	if err != nil {
+		return "", err
+	}
+	if id == "" {
+		return "", errors.New("user has no workspace")
+	}
+	return id, nil
+}
+
+func (r *WorkspaceRepository) SetActive(userId, workspaceId string) error {
+	_, err := r.db.Exec(`UPDATE users SET active_workspace_id = $2 WHERE id = $1`, userId, workspaceId)
+	return err
+}
+
+func (r *WorkspaceRepository) Rename(id, name string) error {
+	_, err := r.db.Exec(`UPDATE workspaces SET name = $2 WHERE id = $1`, id, name)
+	return err
+}
+
+func (r *WorkspaceRepository) Delete(id string) error {
+	_, err := r.db.Exec(`DELETE FROM workspaces WHERE id = $1 AND personal_owner_id IS NULL`, id)
+	return err
+}
+
+func (r *WorkspaceRepository) GetMembers(workspaceId string) ([]models.WorkspaceMember, error) {
+	members := []models.WorkspaceMember{}
+	err := r.db.Select(&members, `
+		SELECT m.*, u.username, u.email
+		FROM workspace_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.workspace_id = $1
+		ORDER BY m.joined_at`, workspaceId)
+	return members, err
+}
+
+func (r *WorkspaceRepository) GetMemberIDs(workspaceId string) ([]string, error) {
+	ids := []string{}
+	err := r.db.Select(&ids, `SELECT user_id FROM workspace_members WHERE workspace_id = $1`, workspaceId)
+	return ids, err
+}
+
+func (r *WorkspaceRepository) AddMember(m *models.WorkspaceMember) error {
+	_, err := r.db.NamedExec(insertWorkspaceMemberQuery, m)
+	return err
+}
+
+func (r *WorkspaceRepository) UpdateMemberRole(workspaceId, userId, role string) error {
+	_, err := r.db.Exec(`
+		UPDATE workspace_members SET role = $3 WHERE workspace_id = $1 AND user_id = $2`,
+		workspaceId, userId, role)
+	return err
+}
+
+func (r *WorkspaceRepository) RemoveMember(workspaceId, userId string) error {
+	res, err := r.db.Exec(`DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`, workspaceId, userId)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("no rows affected")
+	}
+	return nil
+}
+
+func (r *WorkspaceRepository) CountOwners(workspaceId string) (int, error) {
+	var count int
+	err := r.db.Get(&count, `
+		SELECT COUNT(*) FROM workspace_members WHERE workspace_id = $1 AND role = 'owner'`, workspaceId)
+	return count, err
+}
@@ -0,0 +1,102 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"time"
+	"todolist/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccessTokenHandler struct {
+	ts *service.AccessTokenService
+}
+
+func NewAccessTokenHandler(ts *service.AccessTokenService) *AccessTokenHandler {
+	return &AccessTokenHandler{ts: ts}
+}
+
+type CreateAccessTokenInput struct {
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	Password  string     `json:"password"`
+}
+
+// Create godoc
+// @Summary Create a personal access token
+// @Description Creates a token for scripts and integrations, sent as "Authorization: Bearer tdl_...". Scopes are todos:read, todos:write and profile:read; expires_at is optional. The token is only returned here. Needs the password, or a login within the last 10 minutes on accounts without one. Tokens are revoked by a password change or reset and by logging out other sessions
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param input body CreateAccessTokenInput true "Token payload"
+// @Success 201 {object} service.CreatedAccessToken
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse "Log in again first"
+// @Failure 429 {object} ErrorResponse "Too many failed attempts; see Retry-After"
+// @Router /api/user/me/tokens [post]
+func (h *AccessTokenHandler) Create(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+	sessionID := c.MustGet("session").(string)
+
+	var input CreateAccessTokenInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	token, err := h.ts.CreateToken(userID, sessionID, input.Password, input.Name, input.Scopes, input.ExpiresAt, sessionClient(c))
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, service.ErrReauthRequired) {
+			status = http.StatusForbidden
+		}
+		writeError(c, status, err)
+		return
+	}
+	c.JSON(http.StatusCreated, token)
+}
+
+// GetAll godoc
+// @Summary List personal access tokens
+// @Description Lists the user's personal access tokens with their scopes, expiry and last use
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.AccessToken
+// @Failure 500 {object} ErrorResponse
+// @Router /api/user/me/tokens [get]
+func (h *AccessTokenHandler) GetAll(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	tokens, err := h.ts.GetTokens(userID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Delete godoc
+// @Summary Revoke a personal access token
+// @Description Revokes a token; requests using it are rejected from then on
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Token ID"
+// @Success 200 {object} SuccessResponce
+// @Failure 404 {object} ErrorResponse
+// @Router /api/user/me/tokens/{id} [delete]
+func (h *AccessTokenHandler) Delete(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	if err := h.ts.RevokeToken(userID, c.Param("id")); err != nil {
+		writeError(c, http.StatusNotFound, err)
+		return
+	}
+	writeOK(c, "Token revoked successfully")
+}
@@ -0,0 +1,140 @@
+package routes
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"todolist/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead leaves room for the multipart headers around the file.
+const multipartOverhead = 1 << 20
+
+type AttachmentHandler struct {
+	as *service.AttachmentService
+}
+
+func NewAttachmentHandler(as *service.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{as: as}
+}
+
+// GetAll godoc
+// @Summary List attachments of a todo
+// @Description Returns metadata of the files attached to a todo
+// @Tags attachments
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Todo ID"
+// @Success 200 {array} models.TodoAttachment
+// @Failure 404 {object} ErrorResponse
+// @Router /api/todos/{id}/attachments [get]
+func (h *AttachmentHandler) GetAll(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	attachments, err := h.as.GetAttachments(userID, c.Param("id"))
+	if err != nil {
+		writeError(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, attachments)
+}
+
+// Upload godoc
+// @Summary Attach a file to a todo
+// @Description Uploads a PNG, JPEG, GIF, WebP image or a PDF as multipart field "file". The type is detected from the contents
+// @Tags attachments
+// @Accept mpfd
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Todo ID"
+// @Param file formData file true "File"
+// @Success 201 {object} models.TodoAttachment
+// @Failure 400 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 415 {object} ErrorResponse
+// @Router /api/todos/{id}/attachments [post]
+func (h *AttachmentHandler) Upload(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.as.MaxBytes()+multipartOverhead)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(c, http.StatusRequestEntityTooLarge, service.ErrAttachmentTooLarge)
+			return
+		}
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.as.Upload(c.Request.Context(), userID, c.Param("id"), header.Filename, header.Size, file)
+	switch {
+	case errors.Is(err, service.ErrAttachmentTooLarge):
+		writeError(c, http.StatusRequestEntityTooLarge, err)
+	case errors.Is(err, service.ErrAttachmentType):
+		writeError(c, http.StatusUnsupportedMediaType, err)
+	case err != nil:
+		writeError(c, http.StatusBadRequest, err)
+	default:
+		c.JSON(http.StatusCreated, attachment)
+	}
+}
+
+// Download godoc
+// @Summary Download an attachment
+// @Description Streams the attached file
+// @Tags attachments
+// @Produce octet-stream
+// @Security ApiKeyAuth
+// @Param id path string true "Todo ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Success 200 {file} file
+// @Failure 404 {object} ErrorResponse
+// @Router /api/todos/{id}/attachments/{attachmentId} [get]
+func (h *AttachmentHandler) Download(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	attachment, contents, err := h.as.Open(c.Request.Context(), userID, c.Param("id"), c.Param("attachmentId"))
+	if err != nil {
+		writeError(c, http.StatusNotFound, err)
+		return
+	}
+	defer contents.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, contents, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// Delete godoc
+// @Summary Delete an attachment
+// @Description Removes an attachment and its file
+// @Tags attachments
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Todo ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Success 200 {object} SuccessResponce
+// @Failure 400 {object} ErrorResponse
+// @Router /api/todos/{id}/attachments/{attachmentId} [delete]
+func (h *AttachmentHandler) Delete(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	if err := h.as.DeleteAttachment(userID, c.Param("id"), c.Param("attachmentId")); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	writeOK(c, "Attachment deleted successfully")
+}
@@ -0,0 +1,121 @@
+package routes
+
+import (
+	"net/http"
+	"todolist/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CommentHandler struct {
+	cs *service.CommentService
+}
+
+func NewCommentHandler(cs *service.CommentService) *CommentHandler {
+	return &CommentHandler{cs: cs}
+}
+
+type CommentInput struct {
+	Body string `json:"body" binding:"required" example:"Looks good, @alice please review"`
+}
+
+// GetAll godoc
+// @Summary List comments on a todo
+// @Description Returns the comments on a todo, oldest first, with the Markdown body rendered to HTML
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Todo ID"
+// @Success 200 {array} models.TodoComment
+// @Failure 404 {object} ErrorResponse
+// @Router /api/todos/{id}/comments [get]
+func (h *CommentHandler) GetAll(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	comments, err := h.cs.GetComments(userID, c.Param("id"))
+	if err != nil {
+		writeError(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, comments)
+}
+
+// Create godoc
+// @Summary Comment on a todo
+// @Description Adds a Markdown comment. Mentioned users (@username) who can see the todo are notified by email
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Todo ID"
+// @Param input body CommentInput true "Comment"
+// @Success 201 {object} models.TodoComment
+// @Failure 400 {object} ErrorResponse
+// @Router /api/todos/{id}/comments [post]
+func (h *CommentHandler) Create(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	var input CommentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	comment, err := h.cs.AddComment(userID, c.Param("id"), input.Body)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusCreated, comment)
+}
+
+// Update godoc
+// @Summary Edit a comment
+// @Description Replaces the body of a comment written by the authenticated user. Only newly mentioned users are notified
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Todo ID"
+// @Param commentId path string true "Comment ID"
+// @Param input body CommentInput true "Comment"
+// @Success 200 {object} models.TodoComment
+// @Failure 400 {object} ErrorResponse
+// @Router /api/todos/{id}/comments/{commentId} [put]
+func (h *CommentHandler) Update(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	var input CommentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	comment, err := h.cs.UpdateComment(userID, c.Param("id"), c.Param("commentId"), input.Body)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusOK, comment)
+}
+
+// Delete godoc
+// @Summary Delete a comment
+// @Description Deletes a comment written by the authenticated user
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Todo ID"
+// @Param commentId path string true "Comment ID"
+// @Success 200 {object} SuccessResponce
+// @Failure 400 {object} ErrorResponse
+// @Router /api/todos/{id}/comments/{commentId} [delete]
+func (h *CommentHandler) Delete(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	if err := h.cs.DeleteComment(userID, c.Param("id"), c.Param("commentId")); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	writeOK(c, "Comment deleted successfully")
+}
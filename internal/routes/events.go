@@ -0,0 +1,90 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"todolist/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const heartbeatInterval = 15 * time.Second
+
+type EventHandler struct {
+	broker *service.EventBroker
+}
+
+func NewEventHandler(broker *service.EventBroker) *EventHandler {
+	return &EventHandler{broker: broker}
+}
+
+// Stream godoc
+// @Summary Stream todo events
+// @Description Server-Sent Events stream of todo.created, todo.updated, todo.completed and todo.deleted events. The token may be passed as the access_token query parameter because EventSource cannot send headers. Send Last-Event-ID to resume
+// @Tags events
+// @Produce text/event-stream
+// @Security ApiKeyAuth
+// @Param access_token query string false "JWT for clients that cannot set the Authorization header"
+// @Param Last-Event-ID header string false "Resume after this event ID"
+// @Success 200 {string} string "event stream"
+// @Failure 401 {object} ErrorResponse
+// @Router /api/events [get]
+func (h *EventHandler) Stream(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	resumeFrom, _ := strconv.ParseUint(lastEventID, 10, 64)
+
+	events, missed, unsubscribe := h.broker.Subscribe(userID, resumeFrom)
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	for _, event := range missed {
+		if err := writeStreamEvent(c, event); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeStreamEvent(c, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+func writeStreamEvent(c *gin.Context, event service.StreamEvent) error {
+	data, err := json.Marshal(event.Event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Event.Type, data)
+	return err
+}
@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"net/http"
+	"todolist/internal/graph"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GraphQLHandler struct {
+	api *graph.API
+}
+
+func NewGraphQLHandler(api *graph.API) *GraphQLHandler {
+	return &GraphQLHandler{api: api}
+}
+
+// Serve godoc
+// @Summary GraphQL endpoint
+// @Description Executes a GraphQL query or mutation over User and Todo. Queries are limited in length, depth and complexity
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param input body graph.Request true "GraphQL request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Router /graphql [post]
+func (h *GraphQLHandler) Serve(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+	sessionID := c.MustGet("session").(string)
+
+	var req graph.Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.api.Execute(c.Request.Context(), userID, sessionID, sessionClient(c), req))
+}
@@ -0,0 +1,99 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"todolist/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IdentityHandler struct {
+	is *service.IdentityService
+}
+
+func NewIdentityHandler(is *service.IdentityService) *IdentityHandler {
+	return &IdentityHandler{is: is}
+}
+
+type RemovePasswordInput struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// loginMethodStatus answers 409 when a removal would leave the user without
+// a way to log in.
+func loginMethodStatus(err error, fallback int) int {
+	if errors.Is(err, service.ErrLastLoginMethod) {
+		return http.StatusConflict
+	}
+	return fallback
+}
+
+// GetAll godoc
+// @Summary List linked identities
+// @Description Lists the login providers linked to the account
+// @Tags users
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.UserIdentity
+// @Failure 500 {object} ErrorResponse
+// @Router /api/user/me/identities [get]
+func (h *IdentityHandler) GetAll(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	identities, err := h.is.GetIdentities(userID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, identities)
+}
+
+// Delete godoc
+// @Summary Unlink an identity
+// @Description Removes a linked login provider. The last login method (password, identity or passkey) cannot be removed
+// @Tags users
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Identity ID"
+// @Success 200 {object} SuccessResponce
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "It is the last login method"
+// @Router /api/user/me/identities/{id} [delete]
+func (h *IdentityHandler) Delete(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	if err := h.is.Unlink(userID, c.Param("id")); err != nil {
+		writeError(c, loginMethodStatus(err, http.StatusNotFound), err)
+		return
+	}
+	writeOK(c, "Identity unlinked successfully")
+}
+
+// RemovePassword godoc
+// @Summary Remove password
+// @Description Removes the password so the account logs in with linked providers or passkeys only. Needs the current password and another login method
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param input body RemovePasswordInput true "Current password"
+// @Success 200 {object} SuccessResponce
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "It is the last login method"
+// @Failure 429 {object} ErrorResponse "Too many failed attempts; see Retry-After"
+// @Router /api/user/me/password [delete]
+func (h *IdentityHandler) RemovePassword(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	var input RemovePasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := h.is.RemovePassword(userID, input.Password, sessionClient(c)); err != nil {
+		writeError(c, loginMethodStatus(err, http.StatusBadRequest), err)
+		return
+	}
+	writeOK(c, "Password removed successfully")
+}
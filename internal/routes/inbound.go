@@ -0,0 +1,87 @@
+package routes
+
+import (
+	"net/http"
+	"todolist/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InboundHandler struct {
+	is *service.InboundMailService
+}
+
+func NewInboundHandler(is *service.InboundMailService) *InboundHandler {
+	return &InboundHandler{is: is}
+}
+
+type UpdateAllowedSendersInput struct {
+	AllowedSenders []string `json:"allowed_senders"`
+}
+
+// GetAddress godoc
+// @Summary Get inbound email address
+// @Description Returns the secret address that turns forwarded emails into todos, creating it on first use
+// @Tags inbound
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.InboundAddress
+// @Failure 500 {object} ErrorResponse
+// @Router /api/user/me/inbound [get]
+func (h *InboundHandler) GetAddress(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+	address, err := h.is.GetAddress(userID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, address)
+}
+
+// UpdateAllowedSenders godoc
+// @Summary Update inbound sender allow-list
+// @Description Sets extra sender addresses allowed to create todos by email. The account email is always allowed
+// @Tags inbound
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param input body UpdateAllowedSendersInput true "Allowed senders"
+// @Success 200 {object} models.InboundAddress
+// @Failure 400 {object} ErrorResponse
+// @Router /api/user/me/inbound [put]
+func (h *InboundHandler) UpdateAllowedSenders(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	var input UpdateAllowedSendersInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	address, err := h.is.UpdateAllowedSenders(userID, input.AllowedSenders)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusOK, address)
+}
+
+// RotateAddress godoc
+// @Summary Rotate inbound email address
+// @Description Replaces the secret address; mail to the old one is rejected
+// @Tags inbound
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.InboundAddress
+// @Failure 500 {object} ErrorResponse
+// @Router /api/user/me/inbound/rotate [post]
+func (h *InboundHandler) RotateAddress(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+	address, err := h.is.RotateAddress(userID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, address)
+}
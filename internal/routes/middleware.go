@@ -1,8 +1,12 @@
 package routes
 
 import (
+	"bytes"
+	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"slices"
 	"strings"
 	"time"
 	"todolist/internal/utils"
@@ -14,7 +18,7 @@ func LoggerMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
-		query := c.Request.URL.RawQuery
+		query := redactQuery(c.Request.URL.Query())
 
 		c.Next()
 
@@ -39,9 +43,34 @@ func LoggerMiddleware() gin.HandlerFunc {
 	}
 }
 
-func AuthMiddleware(manager *utils.JWTManager) gin.HandlerFunc {
+func redactQuery(values url.Values) string {
+	if values.Has("access_token") {
+		values.Set("access_token", "REDACTED")
+	}
+	return values.Encode()
+}
+
+// TokenAuthenticator validates an access token and the session behind it.
+type TokenAuthenticator interface {
+	Authenticate(token, ip string) (*utils.Claims, error)
+}
+
+func AuthMiddleware(auth TokenAuthenticator) gin.HandlerFunc {
+	return authMiddleware(auth, false)
+}
+
+// StreamAuthMiddleware also accepts the token from the access_token query
+// parameter, because browser EventSource connections cannot set headers.
+func StreamAuthMiddleware(auth TokenAuthenticator) gin.HandlerFunc {
+	return authMiddleware(auth, true)
+}
+
+func authMiddleware(auth TokenAuthenticator, allowQueryToken bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString := c.GetHeader("Authorization")
+		if tokenString == "" && allowQueryToken {
+			tokenString = c.Query("access_token")
+		}
 		if tokenString == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
 			c.Abort()
@@ -50,13 +79,64 @@ func AuthMiddleware(manager *utils.JWTManager) gin.HandlerFunc {
 
 		tokenString = strings.Replace(tokenString, "Bearer ", "", 1)
 
-		claims, err := manager.Validate(tokenString)
+		claims, err := auth.Authenticate(tokenString, c.ClientIP())
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token", "details": err.Error()})
 			c.Abort()
 			return
 		}
 		c.Set("user", claims.UserID)
+		c.Set("session", claims.SessionID)
+		c.Set("scopes", claims.Scopes)
+		c.Next()
+	}
+}
+
+// RequireScope lets personal access tokens through only if they were granted
+// scope. Session tokens have full access.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scopes := c.MustGet("scopes").([]string)
+		if scopes != nil && !slices.Contains(scopes, scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "The access token lacks the " + scope + " scope"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSession rejects personal access tokens, so they can't manage the
+// account they belong to.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.MustGet("scopes").([]string) != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This endpoint cannot be used with an access token"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// SlackSignatureMiddleware verifies signed requests from Slack and restores
+// the body so handlers can still parse the form.
+func SlackSignatureMiddleware(signingSecret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<16))
+		if err != nil {
+			writeError(c, http.StatusBadRequest, err)
+			c.Abort()
+			return
+		}
+		err = utils.VerifySlackSignature(signingSecret,
+			c.GetHeader("X-Slack-Request-Timestamp"), c.GetHeader("X-Slack-Signature"), body, time.Now())
+		if err != nil {
+			writeError(c, http.StatusUnauthorized, err)
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
 		c.Next()
 	}
 }
@@ -0,0 +1,157 @@
+package routes
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"todolist/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OIDCHandler struct {
+	oidc *service.OIDCService
+}
+
+func NewOIDCHandler(oidc *service.OIDCService) *OIDCHandler {
+	return &OIDCHandler{oidc: oidc}
+}
+
+type BeginOIDCLinkInput struct {
+	Password string `json:"password"`
+}
+
+type FinishOIDCLoginInput struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+// Providers godoc
+// @Summary List login providers
+// @Description Returns the configured OpenID Connect and OAuth 2.0 providers for login buttons
+// @Tags auth
+// @Produce json
+// @Success 200 {array} service.OIDCProvider
+// @Router /auth/oidc/providers [get]
+func (h *OIDCHandler) Providers(c *gin.Context) {
+	c.JSON(http.StatusOK, h.oidc.Providers())
+}
+
+// Begin godoc
+// @Summary Start provider login
+// @Description Returns the provider's authorization URL. The browser keeps the state, goes to the URL and, after the provider redirects back to OIDC_REDIRECT_URL, sends the code and state to /auth/oidc/finish
+// @Tags auth
+// @Produce json
+// @Param provider path string true "Provider ID"
+// @Success 200 {object} service.OIDCAuthorization
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /auth/oidc/{provider}/begin [post]
+func (h *OIDCHandler) Begin(c *gin.Context) {
+	authorization, err := h.oidc.Begin(c.Param("provider"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrUnknownOIDCProvider) {
+			status = http.StatusNotFound
+		}
+		writeError(c, status, err)
+		return
+	}
+	c.JSON(http.StatusOK, authorization)
+}
+
+// BeginLink godoc
+// @Summary Start linking a provider
+// @Description Like /auth/oidc/{provider}/begin, but the provider identity is added to the signed-in account. Needs the password, or a login within the last 10 minutes on accounts without one
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param provider path string true "Provider ID"
+// @Param input body BeginOIDCLinkInput false "Current password"
+// @Success 200 {object} service.OIDCAuthorization
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse "Log in again first"
+// @Failure 404 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse "Too many failed attempts; see Retry-After"
+// @Router /api/user/me/identities/{provider}/begin [post]
+func (h *OIDCHandler) BeginLink(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+	sessionID := c.MustGet("session").(string)
+
+	var input BeginOIDCLinkInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	authorization, err := h.oidc.BeginLink(userID, sessionID, input.Password, c.Param("provider"), sessionClient(c))
+	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, service.ErrUnknownOIDCProvider):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrReauthRequired):
+			status = http.StatusForbidden
+		}
+		writeError(c, status, err)
+		return
+	}
+	c.JSON(http.StatusOK, authorization)
+}
+
+// FinishLink godoc
+// @Summary Finish linking a provider
+// @Description Exchanges the authorization code from the provider's redirect and links the identity to the signed-in account
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param input body FinishOIDCLoginInput true "Code and state from the redirect"
+// @Success 201 {object} models.UserIdentity
+// @Failure 400 {object} ErrorResponse
+// @Router /api/user/me/identities/finish [post]
+func (h *OIDCHandler) FinishLink(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	var input FinishOIDCLoginInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	identity, err := h.oidc.FinishLink(c.Request.Context(), userID, input.Code, input.State)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusCreated, identity)
+}
+
+// Finish godoc
+// @Summary Finish provider login
+// @Description Exchanges the authorization code from the provider's redirect and logs in or creates the user, like /auth/google. An email that belongs to an unlinked account is refused with 409
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body FinishOIDCLoginInput true "Code and state from the redirect"
+// @Success 200 {object} service.LoginResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /auth/oidc/finish [post]
+func (h *OIDCHandler) Finish(c *gin.Context) {
+	var input FinishOIDCLoginInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	resp, err := h.oidc.Finish(c.Request.Context(), input.Code, input.State, sessionClient(c))
+	if err != nil {
+		status := http.StatusUnauthorized
+		if errors.Is(err, service.ErrOAuthEmailTaken) {
+			status = http.StatusConflict
+		}
+		writeError(c, status, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,216 @@
+package routes
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+	"todolist/internal/models"
+	"todolist/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+var publicLinkPage = template.Must(template.New("public_link").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{if .View}}{{.View.Name}}{{else}}Shared todos{{end}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 720px; margin: 2rem auto; padding: 0 1rem; color: #1f2937; }
+li { list-style: none; padding: .75rem 0; border-bottom: 1px solid #e5e7eb; }
+ul { padding: 0; }
+.done { text-decoration: line-through; color: #9ca3af; }
+.meta { font-size: .85rem; color: #6b7280; }
+.error { color: #b91c1c; }
+</style>
+</head>
+<body>
+{{if .View}}
+<h1>{{.View.Name}}</h1>
+{{with .View.ExpiresAt}}<p class="meta">Available until {{.Format "2006-01-02 15:04 MST"}}</p>{{end}}
+<ul>
+{{range .View.Todos}}
+<li>
+<div class="{{if .Completed}}done{{end}}"><strong>{{.Title}}</strong></div>
+{{if .Description}}<div>{{.Description}}</div>{{end}}
+<div class="meta">Due {{.Deadline.Format "2006-01-02 15:04"}}{{if .Priority}} · {{.Priority}} priority{{end}}{{range .Tags}} · #{{.}}{{end}}</div>
+</li>
+{{else}}
+<li class="meta">Nothing here yet.</li>
+{{end}}
+</ul>
+{{else if .PasswordForm}}
+<h1>Password required</h1>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+<form method="post">
+<input type="password" name="password" autofocus required>
+<button type="submit">Open</button>
+</form>
+{{else}}
+<h1>{{.Error}}</h1>
+{{end}}
+</body>
+</html>
+`))
+
+type publicLinkPageData struct {
+	View         *models.PublicLinkView
+	PasswordForm bool
+	Error        string
+}
+
+type PublicLinkHandler struct {
+	ps *service.PublicLinkService
+}
+
+func NewPublicLinkHandler(ps *service.PublicLinkService) *PublicLinkHandler {
+	return &PublicLinkHandler{ps: ps}
+}
+
+type CreatePublicLinkInput struct {
+	Name      string     `json:"name" binding:"required" example:"Client report"`
+	TodoIDs   []string   `json:"todo_ids" binding:"required"`
+	Password  string     `json:"password"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// Create godoc
+// @Summary Create a public link
+// @Description Publishes selected todos read-only to anyone with the link. The token is returned only once; the view is served at /public/links/{token}
+// @Tags public-links
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param input body CreatePublicLinkInput true "Link"
+// @Success 201 {object} models.PublicLink
+// @Failure 400 {object} ErrorResponse
+// @Router /api/public-links [post]
+func (h *PublicLinkHandler) Create(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	var input CreatePublicLinkInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	link, err := h.ps.CreateLink(userID, input.Name, input.TodoIDs, input.Password, input.ExpiresAt)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusCreated, link)
+}
+
+// GetAll godoc
+// @Summary List my public links
+// @Description Lists public links created by the authenticated user, without tokens
+// @Tags public-links
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.PublicLink
+// @Failure 500 {object} ErrorResponse
+// @Router /api/public-links [get]
+func (h *PublicLinkHandler) GetAll(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	links, err := h.ps.GetLinks(userID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, links)
+}
+
+// Delete godoc
+// @Summary Revoke a public link
+// @Description Deletes a public link; it stops working immediately
+// @Tags public-links
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Link ID"
+// @Success 200 {object} SuccessResponce
+// @Failure 404 {object} ErrorResponse
+// @Router /api/public-links/{id} [delete]
+func (h *PublicLinkHandler) Delete(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	if err := h.ps.RevokeLink(userID, c.Param("id")); err != nil {
+		writeError(c, http.StatusNotFound, err)
+		return
+	}
+	writeOK(c, "Link revoked successfully")
+}
+
+// View godoc
+// @Summary Open a public link
+// @Description Shows the linked todos as JSON or, for browsers (Accept: text/html), as a page. Password-protected links take the password in the X-Link-Password header or a "password" form field (POST)
+// @Tags public-links
+// @Produce json
+// @Produce html
+// @Param token path string true "Link token"
+// @Param X-Link-Password header string false "Link password"
+// @Success 200 {object} models.PublicLinkView
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /public/links/{token} [get]
+func (h *PublicLinkHandler) View(c *gin.Context) {
+	password := c.GetHeader("X-Link-Password")
+	if password == "" {
+		password = c.PostForm("password")
+	}
+	view, err := h.ps.View(c.Param("token"), password, c.ClientIP())
+
+	// The token is in the URL: keep it out of caches, referrers and indexes.
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("X-Robots-Tag", "noindex")
+
+	status := http.StatusOK
+	var tooMany *service.TooManyAttemptsError
+	switch {
+	case errors.As(err, &tooMany):
+		c.Header("Retry-After", strconv.Itoa(tooMany.RetryAfterSeconds()))
+		status = http.StatusTooManyRequests
+	case errors.Is(err, service.ErrPublicLinkNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrPublicLinkPassword):
+		status = http.StatusUnauthorized
+	case err != nil:
+		status = http.StatusInternalServerError
+	}
+
+	if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) != gin.MIMEHTML {
+		if err != nil {
+			writeError(c, status, err)
+			return
+		}
+		c.JSON(status, view)
+		return
+	}
+
+	data := publicLinkPageData{View: view}
+	if errors.Is(err, service.ErrPublicLinkPassword) || tooMany != nil {
+		data.PasswordForm = true
+		if tooMany != nil {
+			data.Error = presentableErrorMessage(err)
+		} else if password != "" {
+			data.Error = "Wrong password"
+		}
+	} else if err != nil {
+		data.Error = presentableErrorMessage(err)
+	}
+	var buf bytes.Buffer
+	if err := publicLinkPage.Execute(&buf, data); err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Data(status, "text/html; charset=utf-8", buf.Bytes())
+}
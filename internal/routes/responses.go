@@ -3,7 +3,9 @@ package routes
 import (
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
+	"todolist/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -17,7 +19,14 @@ type SuccessResponce struct {
 	Message string `json:"message" binding:"required"`
 }
 
+// writeError answers with status, or with 429 and Retry-After when err is a
+// lockout after too many failed attempts.
 func writeError(c *gin.Context, status int, err error) {
+	var tooMany *service.TooManyAttemptsError
+	if errors.As(err, &tooMany) {
+		c.Header("Retry-After", strconv.Itoa(tooMany.RetryAfterSeconds()))
+		status = http.StatusTooManyRequests
+	}
 	c.JSON(status, ErrorResponse{Err: presentableErrorMessage(err)})
 }
 
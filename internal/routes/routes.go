@@ -2,7 +2,7 @@ package routes
 
 import (
 	_ "todolist/docs"
-	"todolist/internal/utils"
+	"todolist/internal/models"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -10,7 +10,31 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func SetupRoutes(router *gin.Engine, userHandler *UserHandler, todoHandler *TodoHandler, jwtManager *utils.JWTManager) {
+// Handlers groups the HTTP handlers mounted by SetupRoutes.
+// Optional features leave their handler nil and their routes are skipped.
+type Handlers struct {
+	User        *UserHandler
+	Todo        *TodoHandler
+	Webhook     *WebhookHandler
+	Event       *EventHandler
+	Inbound     *InboundHandler
+	GraphQL     *GraphQLHandler
+	Slack       *SlackHandler
+	Template    *TemplateHandler
+	Share       *ShareHandler
+	Workspace   *WorkspaceHandler
+	Comment     *CommentHandler
+	Attachment  *AttachmentHandler
+	PublicLink  *PublicLinkHandler
+	Session     *SessionHandler
+	TwoFactor   *TwoFactorHandler
+	WebAuthn    *WebAuthnHandler
+	OIDC        *OIDCHandler
+	Identity    *IdentityHandler
+	AccessToken *AccessTokenHandler
+}
+
+func SetupRoutes(router *gin.Engine, h Handlers, authenticator TokenAuthenticator) {
 	router.Use(LoggerMiddleware())
 
 	corsConfig := cors.DefaultConfig()
@@ -26,26 +50,135 @@ func SetupRoutes(router *gin.Engine, userHandler *UserHandler, todoHandler *Todo
 	auth := router.Group("/auth")
 
 	{
-		auth.POST("/register", userHandler.Register)
-		auth.POST("/login", userHandler.Login)
-		auth.POST("/google", userHandler.GoogleLogin)
-		auth.POST("/verify", userHandler.VerifyEmail)
+		auth.POST("/register", h.User.Register)
+		auth.POST("/login", h.User.Login)
+		auth.POST("/google", h.User.GoogleLogin)
+		auth.POST("/verify", h.User.VerifyEmail)
+		auth.POST("/verify/resend", h.User.ResendVerification)
+		auth.POST("/refresh", h.User.Refresh)
+		auth.POST("/logout", AuthMiddleware(authenticator), RequireSession(), h.Session.Logout)
+		auth.POST("/logout/all", AuthMiddleware(authenticator), RequireSession(), h.Session.LogoutAll)
+		auth.POST("/password/forgot", h.User.ForgotPassword)
+		auth.POST("/password/reset", h.User.ResetPassword)
+		auth.POST("/2fa/verify", h.TwoFactor.Verify)
+		auth.POST("/webauthn/register/begin", AuthMiddleware(authenticator), RequireSession(), h.WebAuthn.BeginRegistration)
+		auth.POST("/webauthn/register/finish", AuthMiddleware(authenticator), RequireSession(), h.WebAuthn.FinishRegistration)
+		auth.POST("/webauthn/login/begin", h.WebAuthn.BeginLogin)
+		auth.POST("/webauthn/login/finish", h.WebAuthn.FinishLogin)
+		auth.GET("/oidc/providers", h.OIDC.Providers)
+		auth.POST("/oidc/:provider/begin", h.OIDC.Begin)
+		auth.POST("/oidc/finish", h.OIDC.Finish)
+	}
+	router.GET("/public/links/:token", h.PublicLink.View)
+	router.POST("/public/links/:token", h.PublicLink.View)
+	router.GET("/api/events", StreamAuthMiddleware(authenticator), RequireScope(models.ScopeTodosRead), h.Event.Stream)
+	router.POST("/graphql", AuthMiddleware(authenticator), RequireSession(), h.GraphQL.Serve)
+
+	if h.Slack != nil {
+		slack := router.Group("/integrations/slack")
+		slack.Use(SlackSignatureMiddleware(h.Slack.signingSecret))
+		slack.POST("/commands", h.Slack.Command)
+		slack.POST("/interactions", h.Slack.Interaction)
 	}
+
+	// Personal access tokens only reach the routes that name their scope;
+	// everything else in the API needs a session.
 	protected := router.Group("/api")
-	protected.Use(AuthMiddleware(jwtManager))
+	protected.Use(AuthMiddleware(authenticator))
+	{
+		read := RequireScope(models.ScopeTodosRead)
+		write := RequireScope(models.ScopeTodosWrite)
+		protected.POST("/todos", write, h.Todo.Create)
+		protected.POST("/todos/quick", write, h.Todo.QuickAdd)
+		protected.POST("/todos/quick/preview", read, h.Todo.PreviewQuickAdd)
+		protected.PUT("/todos/:id", write, h.Todo.Update)
+		protected.GET("/todos", read, h.Todo.GetAll)
+		protected.DELETE("/todos/:id", write, h.Todo.Delete)
+		protected.GET("/todos/:id/comments", read, h.Comment.GetAll)
+		protected.POST("/todos/:id/comments", write, h.Comment.Create)
+		protected.PUT("/todos/:id/comments/:commentId", write, h.Comment.Update)
+		protected.DELETE("/todos/:id/comments/:commentId", write, h.Comment.Delete)
+		protected.GET("/todos/:id/attachments", read, h.Attachment.GetAll)
+		protected.POST("/todos/:id/attachments", write, h.Attachment.Upload)
+		protected.GET("/todos/:id/attachments/:attachmentId", read, h.Attachment.Download)
+		protected.DELETE("/todos/:id/attachments/:attachmentId", write, h.Attachment.Delete)
+
+		protected.GET("/user/me", RequireScope(models.ScopeProfileRead), h.User.GetUser)
+	}
+
+	sessionOnly := router.Group("/api")
+	sessionOnly.Use(AuthMiddleware(authenticator), RequireSession())
 	{
-		protected.POST("/todos", todoHandler.Create)
-		protected.PUT("/todos/:id", todoHandler.Update)
-		protected.GET("/todos", todoHandler.GetAll)
-		protected.DELETE("/todos/:id", todoHandler.Delete)
-
-		protected.GET("/user/me", userHandler.GetUser)
-		protected.DELETE("/user/me", userHandler.DeleteUser)
-		protected.PUT("/user/me/delete", userHandler.VerifyEmailDelete)
-		protected.PATCH("/user/me", userHandler.UpdateUsername)
-		protected.PUT("/user/me/password", userHandler.UpdatePassword)
-		protected.POST("/user/me/email", userHandler.RequestEmailUpdate)
-		protected.PUT("/user/me/email", userHandler.VerifyEmailUpdate)
+		sessionOnly.POST("/webhooks", h.Webhook.Create)
+		sessionOnly.GET("/webhooks", h.Webhook.GetAll)
+		sessionOnly.PUT("/webhooks/:id", h.Webhook.Update)
+		sessionOnly.DELETE("/webhooks/:id", h.Webhook.Delete)
+		sessionOnly.GET("/webhooks/:id/deliveries", h.Webhook.Deliveries)
+
+		sessionOnly.POST("/workspaces", h.Workspace.Create)
+		sessionOnly.GET("/workspaces", h.Workspace.GetAll)
+		sessionOnly.GET("/workspaces/:id", h.Workspace.Get)
+		sessionOnly.PATCH("/workspaces/:id", h.Workspace.Rename)
+		sessionOnly.DELETE("/workspaces/:id", h.Workspace.Delete)
+		sessionOnly.POST("/workspaces/:id/switch", h.Workspace.Switch)
+		sessionOnly.GET("/workspaces/:id/members", h.Workspace.Members)
+		sessionOnly.POST("/workspaces/:id/members", h.Workspace.AddMember)
+		sessionOnly.PUT("/workspaces/:id/members/:userId", h.Workspace.UpdateMember)
+		sessionOnly.DELETE("/workspaces/:id/members/:userId", h.Workspace.RemoveMember)
+
+		sessionOnly.POST("/shares", h.Share.Create)
+		sessionOnly.GET("/shares", h.Share.GetOutgoing)
+		sessionOnly.GET("/shares/incoming", h.Share.GetIncoming)
+		sessionOnly.PUT("/shares/:id", h.Share.Update)
+		sessionOnly.DELETE("/shares/:id", h.Share.Delete)
+
+		sessionOnly.POST("/public-links", h.PublicLink.Create)
+		sessionOnly.GET("/public-links", h.PublicLink.GetAll)
+		sessionOnly.DELETE("/public-links/:id", h.PublicLink.Delete)
+
+		sessionOnly.POST("/templates", h.Template.Create)
+		sessionOnly.GET("/templates", h.Template.GetAll)
+		sessionOnly.GET("/templates/:id", h.Template.Get)
+		sessionOnly.PUT("/templates/:id", h.Template.Update)
+		sessionOnly.DELETE("/templates/:id", h.Template.Delete)
+		sessionOnly.POST("/templates/:id/instantiate", h.Template.Instantiate)
+
+		sessionOnly.DELETE("/user/me", h.User.DeleteUser)
+		sessionOnly.PUT("/user/me/delete", h.User.VerifyEmailDelete)
+		sessionOnly.PATCH("/user/me", h.User.UpdateUsername)
+		sessionOnly.PUT("/user/me/password", h.User.UpdatePassword)
+		sessionOnly.POST("/user/me/email", h.User.RequestEmailUpdate)
+		sessionOnly.PUT("/user/me/email", h.User.VerifyEmailUpdate)
+		sessionOnly.POST("/user/me/email/resend", h.User.ResendEmailUpdate)
+		sessionOnly.GET("/user/me/sessions", h.Session.GetAll)
+		sessionOnly.DELETE("/user/me/sessions", h.Session.DeleteOthers)
+		sessionOnly.DELETE("/user/me/sessions/:id", h.Session.Delete)
+		sessionOnly.POST("/user/me/2fa/setup", h.TwoFactor.Setup)
+		sessionOnly.POST("/user/me/2fa/confirm", h.TwoFactor.Confirm)
+		sessionOnly.DELETE("/user/me/2fa", h.TwoFactor.Disable)
+		sessionOnly.GET("/user/me/passkeys", h.WebAuthn.GetAll)
+		sessionOnly.PATCH("/user/me/passkeys/:id", h.WebAuthn.Rename)
+		sessionOnly.DELETE("/user/me/passkeys/:id", h.WebAuthn.Delete)
+		sessionOnly.GET("/user/me/identities", h.Identity.GetAll)
+		sessionOnly.POST("/user/me/identities/:provider/begin", h.OIDC.BeginLink)
+		sessionOnly.POST("/user/me/identities/finish", h.OIDC.FinishLink)
+		sessionOnly.DELETE("/user/me/identities/:id", h.Identity.Delete)
+		sessionOnly.DELETE("/user/me/password", h.Identity.RemovePassword)
+		sessionOnly.GET("/user/me/tokens", h.AccessToken.GetAll)
+		sessionOnly.POST("/user/me/tokens", h.AccessToken.Create)
+		sessionOnly.DELETE("/user/me/tokens/:id", h.AccessToken.Delete)
+
+		if h.Inbound != nil {
+			sessionOnly.GET("/user/me/inbound", h.Inbound.GetAddress)
+			sessionOnly.PUT("/user/me/inbound", h.Inbound.UpdateAllowedSenders)
+			sessionOnly.POST("/user/me/inbound/rotate", h.Inbound.RotateAddress)
+		}
+
+		if h.Slack != nil {
+			sessionOnly.POST("/integrations/slack/link-code", h.Slack.CreateLinkCode)
+			sessionOnly.GET("/integrations/slack/links", h.Slack.GetLinks)
+			sessionOnly.DELETE("/integrations/slack/links", h.Slack.DeleteLinks)
+		}
 	}
 	router.NoRoute(func(c *gin.Context) {
 		c.File("./frontend/dist/index.html")
@@ -0,0 +1,121 @@
+package routes
+
+import (
+	"net/http"
+	"todolist/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionHandler struct {
+	ss *service.SessionService
+}
+
+func NewSessionHandler(ss *service.SessionService) *SessionHandler {
+	return &SessionHandler{ss: ss}
+}
+
+// Logout godoc
+// @Summary Logout
+// @Description Ends the current session; its access and refresh tokens stop working
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} SuccessResponce
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/logout [post]
+func (h *SessionHandler) Logout(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+	sessionID := c.MustGet("session").(string)
+
+	if err := h.ss.Revoke(userID, sessionID); err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	writeOK(c, "Logged out successfully")
+}
+
+// LogoutAll godoc
+// @Summary Logout everywhere
+// @Description Ends every session, including the current one, and revokes all personal access tokens
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} SuccessResponce
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/logout/all [post]
+func (h *SessionHandler) LogoutAll(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	if err := h.ss.RevokeAll(userID); err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	writeOK(c, "Logged out everywhere")
+}
+
+// GetAll godoc
+// @Summary List sessions
+// @Description Lists devices the user is logged in on; the caller's session has current=true
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.Session
+// @Failure 500 {object} ErrorResponse
+// @Router /api/user/me/sessions [get]
+func (h *SessionHandler) GetAll(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+	sessionID := c.MustGet("session").(string)
+
+	sessions, err := h.ss.GetSessions(userID, sessionID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+// Delete godoc
+// @Summary Revoke a session
+// @Description Logs out one device
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Session ID"
+// @Success 200 {object} SuccessResponce
+// @Failure 404 {object} ErrorResponse
+// @Router /api/user/me/sessions/{id} [delete]
+func (h *SessionHandler) Delete(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	if err := h.ss.Revoke(userID, c.Param("id")); err != nil {
+		writeError(c, http.StatusNotFound, err)
+		return
+	}
+	writeOK(c, "Session revoked successfully")
+}
+
+// DeleteOthers godoc
+// @Summary Revoke all other sessions
+// @Description Logs out every device except the current one
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} SuccessResponce
+// @Failure 500 {object} ErrorResponse
+// @Router /api/user/me/sessions [delete]
+func (h *SessionHandler) DeleteOthers(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+	sessionID := c.MustGet("session").(string)
+
+	if err := h.ss.RevokeOthers(userID, sessionID); err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	writeOK(c, "Other sessions revoked successfully")
+}
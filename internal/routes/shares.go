@@ -0,0 +1,144 @@
+package routes
+
+import (
+	"net/http"
+	"todolist/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ShareHandler struct {
+	ss *service.ShareService
+}
+
+func NewShareHandler(ss *service.ShareService) *ShareHandler {
+	return &ShareHandler{ss: ss}
+}
+
+type CreateShareInput struct {
+	Recipient string `json:"recipient" binding:"required" example:"alice"`
+	TodoID    string `json:"todo_id"`
+	Role      string `json:"role" binding:"required" example:"viewer"`
+}
+
+type UpdateShareInput struct {
+	Role string `json:"role" binding:"required" example:"editor"`
+}
+
+// Create godoc
+// @Summary Share a todo or the whole list
+// @Description Shares one todo (todo_id) or, when todo_id is empty, the whole list with a user found by username or email. Role is viewer or editor
+// @Tags shares
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param input body CreateShareInput true "Share"
+// @Success 201 {object} models.TodoShare
+// @Failure 400 {object} ErrorResponse
+// @Router /api/shares [post]
+func (h *ShareHandler) Create(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	var input CreateShareInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	share, err := h.ss.Share(userID, input.Recipient, input.TodoID, input.Role)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusCreated, share)
+}
+
+// GetOutgoing godoc
+// @Summary List shares created by me
+// @Description Lists todos and lists the authenticated user has shared with others
+// @Tags shares
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.TodoShare
+// @Failure 500 {object} ErrorResponse
+// @Router /api/shares [get]
+func (h *ShareHandler) GetOutgoing(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	shares, err := h.ss.GetOutgoing(userID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, shares)
+}
+
+// GetIncoming godoc
+// @Summary List shares with me
+// @Description Lists todos and lists other users have shared with the authenticated user
+// @Tags shares
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.TodoShare
+// @Failure 500 {object} ErrorResponse
+// @Router /api/shares/incoming [get]
+func (h *ShareHandler) GetIncoming(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	shares, err := h.ss.GetIncoming(userID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, shares)
+}
+
+// Update godoc
+// @Summary Change a share role
+// @Description Changes the recipient's role. Only the owner can do this
+// @Tags shares
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Share ID"
+// @Param input body UpdateShareInput true "Role"
+// @Success 200 {object} models.TodoShare
+// @Failure 400 {object} ErrorResponse
+// @Router /api/shares/{id} [put]
+func (h *ShareHandler) Update(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	var input UpdateShareInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	share, err := h.ss.UpdateRole(userID, c.Param("id"), input.Role)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusOK, share)
+}
+
+// Delete godoc
+// @Summary Revoke or leave a share
+// @Description The owner revokes the share, or the recipient removes it from their list
+// @Tags shares
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Share ID"
+// @Success 200 {object} SuccessResponce
+// @Failure 400 {object} ErrorResponse
+// @Router /api/shares/{id} [delete]
+func (h *ShareHandler) Delete(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	if err := h.ss.Revoke(userID, c.Param("id")); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	writeOK(c, "Share removed successfully")
+}
@@ -0,0 +1,157 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"todolist/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SlackHandler struct {
+	cs            *service.ChatService
+	signingSecret string
+}
+
+func NewSlackHandler(cs *service.ChatService, signingSecret string) *SlackHandler {
+	return &SlackHandler{cs: cs, signingSecret: signingSecret}
+}
+
+type slackInteraction struct {
+	Type string `json:"type"`
+	User struct {
+		ID string `json:"id"`
+	} `json:"user"`
+	Team struct {
+		ID string `json:"id"`
+	} `json:"team"`
+	Actions []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+}
+
+// Command godoc
+// @Summary Slack slash command
+// @Description Handles `/todo add|list|done|link|unlink|help`. Requests must be signed with the Slack signing secret
+// @Tags integrations
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param X-Slack-Request-Timestamp header string true "Request timestamp"
+// @Param X-Slack-Signature header string true "v0=<hex HMAC-SHA256>"
+// @Param team_id formData string true "Slack team ID"
+// @Param user_id formData string true "Slack user ID"
+// @Param text formData string false "Command text"
+// @Success 200 {object} models.ChatMessage
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /integrations/slack/commands [post]
+func (h *SlackHandler) Command(c *gin.Context) {
+	cmd := service.ChatCommand{
+		TeamID:     c.PostForm("team_id"),
+		ChatUserID: c.PostForm("user_id"),
+		Text:       c.PostForm("text"),
+	}
+	if cmd.TeamID == "" || cmd.ChatUserID == "" {
+		writeError(c, http.StatusBadRequest, errors.New("team_id and user_id are required"))
+		return
+	}
+	msg, err := h.cs.HandleCommand(cmd)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, msg)
+}
+
+// Interaction godoc
+// @Summary Slack interactive action
+// @Description Handles button clicks on messages sent by the slash command, e.g. "Complete". Requests must be signed with the Slack signing secret
+// @Tags integrations
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param X-Slack-Request-Timestamp header string true "Request timestamp"
+// @Param X-Slack-Signature header string true "v0=<hex HMAC-SHA256>"
+// @Param payload formData string true "Interaction payload JSON"
+// @Success 200 {object} models.ChatMessage
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /integrations/slack/interactions [post]
+func (h *SlackHandler) Interaction(c *gin.Context) {
+	var payload slackInteraction
+	if err := json.Unmarshal([]byte(c.PostForm("payload")), &payload); err != nil {
+		writeError(c, http.StatusBadRequest, errors.New("invalid payload"))
+		return
+	}
+	if payload.Type != "block_actions" || len(payload.Actions) == 0 {
+		c.Status(http.StatusOK)
+		return
+	}
+	action := payload.Actions[0]
+	msg, err := h.cs.HandleAction(payload.Team.ID, payload.User.ID, action.ActionID, action.Value)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, msg)
+}
+
+// CreateLinkCode godoc
+// @Summary Create chat link code
+// @Description Issues a one-time code, valid for 10 minutes, to link a Slack account with `/todo link <code>`
+// @Tags integrations
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.ChatLinkCode
+// @Failure 500 {object} ErrorResponse
+// @Router /api/integrations/slack/link-code [post]
+func (h *SlackHandler) CreateLinkCode(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+	code, err := h.cs.CreateLinkCode(userID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, code)
+}
+
+// GetLinks godoc
+// @Summary List linked chat accounts
+// @Description Returns the Slack accounts linked to the current user
+// @Tags integrations
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.ChatLink
+// @Failure 500 {object} ErrorResponse
+// @Router /api/integrations/slack/links [get]
+func (h *SlackHandler) GetLinks(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+	links, err := h.cs.GetLinks(userID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, links)
+}
+
+// DeleteLinks godoc
+// @Summary Unlink chat accounts
+// @Description Removes all Slack accounts linked to the current user
+// @Tags integrations
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} SuccessResponce
+// @Failure 500 {object} ErrorResponse
+// @Router /api/integrations/slack/links [delete]
+func (h *SlackHandler) DeleteLinks(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+	if err := h.cs.DeleteLinks(userID); err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	writeOK(c, "Chat accounts unlinked")
+}
@@ -0,0 +1,224 @@
+package routes
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"todolist/internal/models"
+	"todolist/internal/service"
+	"todolist/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	slackTestSecret = "test-signing-secret"
+	slackTestTeam   = "T1DC2JH3J"
+	slackTestUser   = "U2CERLKJA"
+	slackLinkCode   = "ABCD1234"
+)
+
+// slackRequest is a recorded Slack request body and the expected reply.
+type slackRequest struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Body     string `json:"body"`
+	Unsigned bool   `json:"unsigned"`
+	Status   int    `json:"status"`
+	Text     string `json:"text"`
+}
+
+type fakeChatRepo struct {
+	links map[string]string
+	codes map[string]string
+}
+
+func (r *fakeChatRepo) CreateLinkCode(c *models.ChatLinkCode) error {
+	r.codes[c.Code] = c.UserID
+	return nil
+}
+
+func (r *fakeChatRepo) ConsumeLinkCode(code string) (string, error) {
+	userId, ok := r.codes[code]
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+	delete(r.codes, code)
+	return userId, nil
+}
+
+func (r *fakeChatRepo) DeleteExpiredLinkCodes() error { return nil }
+
+func (r *fakeChatRepo) UpsertLink(l *models.ChatLink) error {
+	r.links[l.TeamID+"/"+l.ChatUserID] = l.UserID
+	return nil
+}
+
+func (r *fakeChatRepo) GetLink(provider, teamId, chatUserId string) (*models.ChatLink, error) {
+	userId, ok := r.links[teamId+"/"+chatUserId]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &models.ChatLink{Provider: provider, TeamID: teamId, ChatUserID: chatUserId, UserID: userId}, nil
+}
+
+func (r *fakeChatRepo) GetLinksByUserID(userId string) ([]models.ChatLink, error) { return nil, nil }
+
+func (r *fakeChatRepo) DeleteLink(provider, teamId, chatUserId string) error {
+	delete(r.links, teamId+"/"+chatUserId)
+	return nil
+}
+
+func (r *fakeChatRepo) DeleteLinksByUserID(userId string) error { return nil }
+
+type fakeTodoRepo struct {
+	todos map[string]models.Todo
+}
+
+func (r *fakeTodoRepo) Create(todo *models.Todo) error {
+	r.todos[todo.Id] = *todo
+	return nil
+}
+
+func (r *fakeTodoRepo) CreateMany(todos []*models.Todo) error {
+	for _, todo := range todos {
+		r.todos[todo.Id] = *todo
+	}
+	return nil
+}
+
+func (r *fakeTodoRepo) GetListByWorkspaceID(workspaceId string) ([]models.Todo, error) {
+	todos := []models.Todo{}
+	for _, todo := range r.todos {
+		if todo.WorkspaceID == workspaceId {
+			todos = append(todos, todo)
+		}
+	}
+	return todos, nil
+}
+
+func (r *fakeTodoRepo) GetPageByWorkspaceID(workspaceId string, _ time.Time, afterId string, _ int) ([]models.Todo, error) {
+	if afterId != "" {
+		return nil, nil
+	}
+	return r.GetListByWorkspaceID(workspaceId)
+}
+
+func (r *fakeTodoRepo) GetByID(id, workspaceId string) (*models.Todo, error) {
+	todo, ok := r.todos[id]
+	if !ok || todo.WorkspaceID != workspaceId {
+		return nil, sql.ErrNoRows
+	}
+	return &todo, nil
+}
+
+func (r *fakeTodoRepo) Update(todo *models.Todo) error {
+	r.todos[todo.Id] = *todo
+	return nil
+}
+
+func (r *fakeTodoRepo) Delete(id, workspaceId string) error {
+	delete(r.todos, id)
+	return nil
+}
+
+type noShares struct{}
+
+func (noShares) GetSharedTodos(string) ([]models.Todo, error) { return nil, nil }
+
+func (noShares) GetSharedTodosPage(string, time.Time, string, int) ([]models.Todo, error) {
+	return nil, nil
+}
+
+func (noShares) GetSharedTodo(string, string) (*models.Todo, error) {
+	return nil, errors.New("not shared")
+}
+
+func (noShares) GetGranteeIDs(string) ([]string, error) { return nil, nil }
+
+type personalWorkspace struct{}
+
+func (personalWorkspace) GetActiveID(userId string) (string, error) { return "ws-" + userId, nil }
+
+func (personalWorkspace) GetMemberIDs(workspaceId string) ([]string, error) {
+	return []string{strings.TrimPrefix(workspaceId, "ws-")}, nil
+}
+
+// newSlackTestRouter serves the Slack routes for user-1, who is linked as
+// slackTestUser and has one pending todo. slackLinkCode links user-2.
+func newSlackTestRouter() *gin.Engine {
+	todos := &fakeTodoRepo{todos: map[string]models.Todo{
+		"5b0e7d3c-6c1f-4f7e-9a53-2d3b8c1e9f10": {
+			Id:          "5b0e7d3c-6c1f-4f7e-9a53-2d3b8c1e9f10",
+			UserID:      "user-1",
+			WorkspaceID: "ws-user-1",
+			Title:       "Water plants",
+			Deadline:    time.Now().Add(24 * time.Hour),
+		},
+	}}
+	chats := &fakeChatRepo{
+		links: map[string]string{slackTestTeam + "/" + slackTestUser: "user-1"},
+		codes: map[string]string{slackLinkCode: "user-2"},
+	}
+	todoService := service.NewTodoService(todos, noShares{}, personalWorkspace{})
+	handler := NewSlackHandler(service.NewChatService(chats, todoService), slackTestSecret)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	slack := r.Group("/integrations/slack")
+	slack.Use(SlackSignatureMiddleware(handler.signingSecret))
+	slack.POST("/commands", handler.Command)
+	slack.POST("/interactions", handler.Interaction)
+	return r
+}
+
+func TestSlackRequests(t *testing.T) {
+	data, err := os.ReadFile("testdata/slack_requests.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requests []slackRequest
+	if err := json.Unmarshal(data, &requests); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range requests {
+		t.Run(tt.Name, func(t *testing.T) {
+			router := newSlackTestRouter()
+
+			// Recorded bodies are re-signed, since the signature covers the time.
+			timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+			signature := utils.SignSlackRequest(slackTestSecret, timestamp, []byte(tt.Body))
+			if tt.Unsigned {
+				signature = ""
+			}
+			req := httptest.NewRequest(http.MethodPost, tt.Path, strings.NewReader(tt.Body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+			req.Header.Set("X-Slack-Signature", signature)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.Status {
+				t.Errorf("status = %d, want %d (%s)", w.Code, tt.Status, w.Body)
+			}
+			var reply struct {
+				Text string `json:"text"`
+				Err  string `json:"err"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &reply); err != nil {
+				t.Fatalf("body = %s: %v", w.Body, err)
+			}
+			if got := reply.Text + reply.Err; !strings.Contains(got, tt.Text) {
+				t.Errorf("reply = %q, want it to contain %q", got, tt.Text)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package routes
+
+import (
+	"net/http"
+	"time"
+	"todolist/internal/models"
+	"todolist/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TemplateHandler struct {
+	ts *service.TemplateService
+}
+
+func NewTemplateHandler(ts *service.TemplateService) *TemplateHandler {
+	return &TemplateHandler{ts: ts}
+}
+
+type TemplateItemInput struct {
+	Title       string   `json:"title" binding:"required"`
+	Description string   `json:"description"`
+	Offset      string   `json:"offset" example:"+3d"`
+	Priority    string   `json:"priority"`
+	Tags        []string `json:"tags"`
+}
+
+type TemplateInput struct {
+	Name        string              `json:"name" binding:"required"`
+	Description string              `json:"description"`
+	Items       []TemplateItemInput `json:"items" binding:"required,min=1,dive"`
+}
+
+type InstantiateTemplateInput struct {
+	Start *time.Time `json:"start"`
+}
+
+func (input TemplateInput) toModel() *models.TodoTemplate {
+	t := &models.TodoTemplate{Name: input.Name, Description: input.Description}
+	for _, item := range input.Items {
+		t.Items = append(t.Items, models.TodoTemplateItem{
+			Title:       item.Title,
+			Description: item.Description,
+			Offset:      item.Offset,
+			Priority:    item.Priority,
+			Tags:        item.Tags,
+		})
+	}
+	return t
+}
+
+// Create godoc
+// @Summary Create a template
+// @Description Saves a checklist of todos with deadlines relative to a start date, e.g. "+3d", "+1w2d", "+4h" or "-1d"
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param input body TemplateInput true "Template"
+// @Success 201 {object} models.TodoTemplate
+// @Failure 400 {object} ErrorResponse
+// @Router /api/templates [post]
+func (h *TemplateHandler) Create(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	var input TemplateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	template, err := h.ts.CreateTemplate(userID, input.toModel())
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusCreated, template)
+}
+
+// GetAll godoc
+// @Summary List templates
+// @Description Lists the authenticated user's templates with their items
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.TodoTemplate
+// @Failure 500 {object} ErrorResponse
+// @Router /api/templates [get]
+func (h *TemplateHandler) GetAll(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	templates, err := h.ts.GetTemplates(userID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, templates)
+}
+
+// Get godoc
+// @Summary Get a template
+// @Description Returns a template with its items
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Template ID"
+// @Success 200 {object} models.TodoTemplate
+// @Failure 404 {object} ErrorResponse
+// @Router /api/templates/{id} [get]
+func (h *TemplateHandler) Get(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	template, err := h.ts.GetTemplate(userID, c.Param("id"))
+	if err != nil {
+		writeError(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+// Update godoc
+// @Summary Update a template
+// @Description Replaces the template name, description and items
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Template ID"
+// @Param input body TemplateInput true "Template"
+// @Success 200 {object} models.TodoTemplate
+// @Failure 400 {object} ErrorResponse
+// @Router /api/templates/{id} [put]
+func (h *TemplateHandler) Update(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	var input TemplateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	template, err := h.ts.UpdateTemplate(userID, c.Param("id"), input.toModel())
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+// Delete godoc
+// @Summary Delete a template
+// @Description Deletes a template; todos created from it are kept
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Template ID"
+// @Success 200 {object} SuccessResponce
+// @Failure 500 {object} ErrorResponse
+// @Router /api/templates/{id} [delete]
+func (h *TemplateHandler) Delete(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	if err := h.ts.DeleteTemplate(userID, c.Param("id")); err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	writeOK(c, "Template deleted successfully")
+}
+
+// Instantiate godoc
+// @Summary Create todos from a template
+// @Description Creates every item of the template as a todo in one transaction. Deadlines are the item offsets applied to start (defaults to now); items whose deadline has already passed are created overdue
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Template ID"
+// @Param input body InstantiateTemplateInput false "Start date"
+// @Success 201 {array} models.Todo
+// @Failure 400 {object} ErrorResponse
+// @Router /api/templates/{id}/instantiate [post]
+func (h *TemplateHandler) Instantiate(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	var input InstantiateTemplateInput
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			writeError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+	start := time.Now()
+	if input.Start != nil {
+		start = *input.Start
+	}
+
+	todos, err := h.ts.Instantiate(userID, c.Param("id"), start)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusCreated, todos)
+}
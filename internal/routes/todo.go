@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"errors"
 	"net/http"
 	"time"
 	"todolist/internal/models"
@@ -23,6 +24,11 @@ type CreateTodoInput struct {
 	Deadline    time.Time `json:"deadline"`
 }
 
+type QuickAddInput struct {
+	Text     string `json:"text" binding:"required"`
+	Timezone string `json:"timezone"`
+}
+
 // Create godoc
 // @Summary Create a new todo
 // @Description Create a new todo for the authenticated user
@@ -129,3 +135,73 @@ func (h *TodoHandler) Update(c *gin.Context) {
 
 	writeOK(c, "Todo updated successfully")
 }
+
+func (input QuickAddInput) location() (*time.Location, error) {
+	if input.Timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(input.Timezone)
+	if err != nil {
+		return nil, errors.New("unknown timezone")
+	}
+	return loc, nil
+}
+
+// PreviewQuickAdd godoc
+// @Summary Preview quick-add parsing
+// @Description Parses a string like "Pay rent every 1st at 9am #finance !high" (English or Ukrainian) without creating a todo. Timezone is an IANA name used to resolve relative dates
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param input body QuickAddInput true "Quick-add text"
+// @Success 200 {object} quickadd.Result
+// @Failure 400 {object} ErrorResponse
+// @Router /api/todos/quick/preview [post]
+func (h *TodoHandler) PreviewQuickAdd(c *gin.Context) {
+	var input QuickAddInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	loc, err := input.location()
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.ts.ParseQuickAdd(input.Text, loc))
+}
+
+// QuickAdd godoc
+// @Summary Quick-add a todo
+// @Description Parses deadline, recurrence, #tags and !priority out of the text and creates the todo
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param input body QuickAddInput true "Quick-add text"
+// @Success 200 {object} models.Todo
+// @Failure 400 {object} ErrorResponse
+// @Router /api/todos/quick [post]
+func (h *TodoHandler) QuickAdd(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	var input QuickAddInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	loc, err := input.location()
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	todo, err := h.ts.QuickAddTodo(userID, input.Text, loc)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusOK, todo)
+}
@@ -0,0 +1,151 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"todolist/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorHandler struct {
+	tfs *service.TwoFactorService
+}
+
+func NewTwoFactorHandler(tfs *service.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{tfs: tfs}
+}
+
+type TwoFactorCodeInput struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type DisableTwoFactorInput struct {
+	Password string `json:"password"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type VerifyTwoFactorInput struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type RecoveryCodesResponse struct {
+	Message       string   `json:"message"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// Setup godoc
+// @Summary Start two-factor setup
+// @Description Creates a TOTP secret. Show the URI as a QR code for an authenticator app, then confirm with a generated code
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.TOTPSetup
+// @Failure 400 {object} ErrorResponse
+// @Router /api/user/me/2fa/setup [post]
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	setup, err := h.tfs.Setup(userID)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusOK, setup)
+}
+
+// Confirm godoc
+// @Summary Enable two-factor authentication
+// @Description Checks a code from the authenticator app and enables 2FA. Returns one-time recovery codes, which are shown only once
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param input body TwoFactorCodeInput true "Authenticator code"
+// @Success 200 {object} RecoveryCodesResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/user/me/2fa/confirm [post]
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	var input TwoFactorCodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	codes, err := h.tfs.Confirm(userID, input.Code)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusOK, RecoveryCodesResponse{
+		Message:       "Two-factor authentication enabled",
+		RecoveryCodes: codes,
+	})
+}
+
+// Disable godoc
+// @Summary Disable two-factor authentication
+// @Description Requires the password (for accounts without one, a login within the last 10 minutes) and an authenticator or recovery code
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param input body DisableTwoFactorInput true "Re-authentication"
+// @Success 200 {object} SuccessResponce
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse "Log in again to confirm"
+// @Failure 429 {object} ErrorResponse "Too many failed attempts; see Retry-After"
+// @Router /api/user/me/2fa [delete]
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+	sessionID := c.MustGet("session").(string)
+
+	var input DisableTwoFactorInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	err := h.tfs.Disable(userID, sessionID, input.Password, input.Code, sessionClient(c))
+	if errors.Is(err, service.ErrReauthRequired) {
+		writeError(c, http.StatusForbidden, err)
+		return
+	}
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	writeOK(c, "Two-factor authentication disabled")
+}
+
+// Verify godoc
+// @Summary Complete login with a second factor
+// @Description Exchanges the mfa_token from login and an authenticator or recovery code for access and refresh tokens
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body VerifyTwoFactorInput true "MFA token and code"
+// @Success 200 {object} service.LoginResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse "Too many failed attempts; see Retry-After"
+// @Router /auth/2fa/verify [post]
+func (h *TwoFactorHandler) Verify(c *gin.Context) {
+	var input VerifyTwoFactorInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	resp, err := h.tfs.VerifyLogin(input.MFAToken, input.Code, sessionClient(c))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTwoFactorCode) {
+			writeError(c, http.StatusBadRequest, err)
+			return
+		}
+		writeError(c, http.StatusUnauthorized, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
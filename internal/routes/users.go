@@ -1,7 +1,9 @@
 package routes
 
 import (
+	"errors"
 	"net/http"
+	"todolist/internal/models"
 	"todolist/internal/service"
 	"todolist/internal/utils"
 
@@ -16,6 +18,11 @@ func NewUserHandler(us *service.UserService) *UserHandler {
 	return &UserHandler{us: us}
 }
 
+// sessionClient describes the caller for the session list.
+func sessionClient(c *gin.Context) models.SessionClient {
+	return models.SessionClient{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
+
 type RegisterInput struct {
 	Username string `json:"username" binding:"required,min=3"`
 	Password string `json:"password" binding:"required,min=8"`
@@ -31,11 +38,28 @@ type GoogleLoginInput struct {
 	Token string `json:"token" binding:"required"`
 }
 
+type RefreshTokenInput struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type ForgotPasswordInput struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordInput struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
+
 type VerifyEmailRegisterInput struct {
 	Email string `json:"email" binding:"required,email"`
 	Code  string `json:"code" binding:"required,len=6"`
 }
 
+type ResendVerificationInput struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
 type UpdateUsernameInput struct {
 	Username string `json:"username" binding:"required,min=3"`
 }
@@ -64,6 +88,7 @@ type UserProfileResponse struct {
 	PendingEmail  string `json:"pending_email"`
 	OauthProvider string `json:"oauth_provider"`
 	HasPassword   bool   `json:"has_password"`
+	TwoFactor     bool   `json:"two_factor_enabled"`
 }
 
 // Register godoc
@@ -100,6 +125,7 @@ func (uh *UserHandler) Register(c *gin.Context) {
 // @Param input body LoginInput true "Login payload"
 // @Success 200 {object} service.LoginResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse "Too many failed attempts; see Retry-After"
 // @Failure 500 {object} ErrorResponse
 // @Router /auth/login [post]
 func (uh *UserHandler) Login(c *gin.Context) {
@@ -108,7 +134,7 @@ func (uh *UserHandler) Login(c *gin.Context) {
 		writeError(c, http.StatusBadRequest, err)
 		return
 	}
-	resp, err := uh.us.LoginUser(input.UserData, input.Password)
+	resp, err := uh.us.LoginUser(input.UserData, input.Password, sessionClient(c))
 	if err != nil {
 		writeError(c, http.StatusInternalServerError, err)
 		return
@@ -116,6 +142,78 @@ func (uh *UserHandler) Login(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// Refresh godoc
+// @Summary Refresh tokens
+// @Description Exchanges a refresh token for a new access and refresh token. Every refresh token works once; reusing one revokes all tokens of that login
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body RefreshTokenInput true "Refresh token"
+// @Success 200 {object} service.LoginResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/refresh [post]
+func (uh *UserHandler) Refresh(c *gin.Context) {
+	var input RefreshTokenInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	resp, err := uh.us.RefreshTokens(input.RefreshToken)
+	if err != nil {
+		writeError(c, http.StatusUnauthorized, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Emails a single-use reset token valid for one hour. The response is the same whether or not the account exists. Requests are limited per address (one a minute, a daily cap) and per IP
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body ForgotPasswordInput true "Account email"
+// @Success 200 {object} SuccessResponce
+// @Failure 400 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /auth/password/forgot [post]
+func (uh *UserHandler) ForgotPassword(c *gin.Context) {
+	var input ForgotPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := uh.us.RequestPasswordReset(input.Email, sessionClient(c)); err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	writeOK(c, "If an account with this email exists, a reset token has been sent")
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Sets a new password with the emailed reset token and logs out all sessions
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body ResetPasswordInput true "Reset token and new password"
+// @Success 200 {object} SuccessResponce
+// @Failure 400 {object} ErrorResponse
+// @Router /auth/password/reset [post]
+func (uh *UserHandler) ResetPassword(c *gin.Context) {
+	var input ResetPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := uh.us.ResetPassword(input.Token, input.NewPassword); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	writeOK(c, "Password reset successfully")
+}
+
 // VerifyEmail godoc
 // @Summary Verify registration email
 // @Description Verifies code from register flow and returns auth token
@@ -125,6 +223,7 @@ func (uh *UserHandler) Login(c *gin.Context) {
 // @Param input body VerifyEmailRegisterInput true "Email verification payload"
 // @Success 200 {object} service.LoginResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse "Too many failed attempts; see Retry-After"
 // @Failure 500 {object} ErrorResponse
 // @Router /auth/verify [post]
 func (uh *UserHandler) VerifyEmail(c *gin.Context) {
@@ -133,7 +232,7 @@ func (uh *UserHandler) VerifyEmail(c *gin.Context) {
 		writeError(c, http.StatusBadRequest, err)
 		return
 	}
-	resp, err := uh.us.VerifyEmail(input.Email, input.Code)
+	resp, err := uh.us.VerifyEmail(input.Email, input.Code, sessionClient(c))
 	if err != nil {
 		writeError(c, http.StatusBadRequest, err)
 		return
@@ -141,15 +240,41 @@ func (uh *UserHandler) VerifyEmail(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// ResendVerification godoc
+// @Summary Resend registration code
+// @Description Emails a new verification code to an unverified account. The response is the same whether or not the account exists. Each address gets one code a minute and at most 5 a day
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body ResendVerificationInput true "Account email"
+// @Success 200 {object} SuccessResponce
+// @Failure 400 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse "Resent too often; see Retry-After"
+// @Failure 500 {object} ErrorResponse
+// @Router /auth/verify/resend [post]
+func (uh *UserHandler) ResendVerification(c *gin.Context) {
+	var input ResendVerificationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := uh.us.ResendVerification(input.Email, sessionClient(c)); err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	writeOK(c, "If an unverified account uses this email, a new code has been sent")
+}
+
 // GoogleLogin godoc
 // @Summary Login with Google
-// @Description Validates Google ID token and logs in or creates user
+// @Description Validates Google ID token and logs in or creates user. An email that belongs to an unlinked account is refused with 409
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param input body GoogleLoginInput true "Google login payload"
 // @Success 200 {object} service.LoginResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /auth/google [post]
 func (uh *UserHandler) GoogleLogin(c *gin.Context) {
@@ -165,9 +290,13 @@ func (uh *UserHandler) GoogleLogin(c *gin.Context) {
 		return
 	}
 
-	resp, err := uh.us.LoginWithOAuth(c.Request.Context(), *oauthData)
+	resp, err := uh.us.LoginWithOAuth(c.Request.Context(), *oauthData, sessionClient(c))
 	if err != nil {
-		writeError(c, http.StatusInternalServerError, err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrOAuthEmailTaken) {
+			status = http.StatusConflict
+		}
+		writeError(c, status, err)
 		return
 	}
 	c.JSON(http.StatusOK, resp)
@@ -199,6 +328,7 @@ func (uh *UserHandler) GetUser(c *gin.Context) {
 		PendingEmail:  user.PendingEmail,
 		OauthProvider: user.OauthProvider,
 		HasPassword:   user.PasswordHash != "",
+		TwoFactor:     user.TOTPEnabled,
 	})
 }
 
@@ -231,34 +361,52 @@ func (uh *UserHandler) UpdateUsername(c *gin.Context) {
 
 // UpdatePassword godoc
 // @Summary Update password
-// @Description Updates password for authenticated user
+// @Description Updates password for authenticated user and ends all sessions. The response carries tokens for a new session of the caller. Accounts without a password can set one within 10 minutes of logging in
 // @Tags users
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
 // @Param input body UpdatePasswordInput true "Password update payload"
-// @Success 200 {object} SuccessResponce
+// @Success 200 {object} service.LoginResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse "Log in again first"
+// @Failure 429 {object} ErrorResponse "Too many failed attempts; see Retry-After"
 // @Failure 500 {object} ErrorResponse
 // @Router /api/user/me/password [put]
 func (uh *UserHandler) UpdatePassword(c *gin.Context) {
 	var input UpdatePasswordInput
 	userID := c.MustGet("user").(string)
+	sessionID := c.MustGet("session").(string)
 	if err := c.ShouldBindJSON(&input); err != nil {
 		writeError(c, http.StatusBadRequest, err)
 		return
 	}
-	err := uh.us.UpdatePassword(userID, input.OldPassword, input.NewPassword)
+	err := uh.us.UpdatePassword(userID, sessionID, input.OldPassword, input.NewPassword, sessionClient(c))
 	if err != nil {
-		writeError(c, http.StatusBadRequest, err)
+		status := http.StatusBadRequest
+		if errors.Is(err, service.ErrReauthRequired) {
+			status = http.StatusForbidden
+		}
+		writeError(c, status, err)
 		return
 	}
-	writeOK(c, "Password updated successfully")
+	user, err := uh.us.GetUserByID(userID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	resp, err := uh.us.StartSession(user, sessionClient(c))
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	resp.Message = "Password updated successfully"
+	c.JSON(http.StatusOK, resp)
 }
 
 // RequestEmailUpdate godoc
 // @Summary Request email update
-// @Description Sends verification code to new email for authenticated user
+// @Description Sends verification code to new email for authenticated user. Shares the per-address limits of the resend endpoint
 // @Tags users
 // @Accept json
 // @Produce json
@@ -266,6 +414,7 @@ func (uh *UserHandler) UpdatePassword(c *gin.Context) {
 // @Param input body RequestEmailUpdateInput true "New email payload"
 // @Success 200 {object} SuccessResponce
 // @Failure 400 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/user/me/email [post]
 func (uh *UserHandler) RequestEmailUpdate(c *gin.Context) {
@@ -293,6 +442,7 @@ func (uh *UserHandler) RequestEmailUpdate(c *gin.Context) {
 // @Param input body VerifyEmailInput true "Email verification code"
 // @Success 200 {object} SuccessResponce
 // @Failure 400 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse "Too many failed attempts; see Retry-After"
 // @Failure 500 {object} ErrorResponse
 // @Router /api/user/me/email [put]
 func (uh *UserHandler) VerifyEmailUpdate(c *gin.Context) {
@@ -310,6 +460,25 @@ func (uh *UserHandler) VerifyEmailUpdate(c *gin.Context) {
 	writeOK(c, "Email updated successfully")
 }
 
+// ResendEmailUpdate godoc
+// @Summary Resend email update code
+// @Description Emails a new code for the pending email change. Each address gets one code a minute and at most 5 a day
+// @Tags users
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} SuccessResponce
+// @Failure 400 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse "Resent too often; see Retry-After"
+// @Router /api/user/me/email/resend [post]
+func (uh *UserHandler) ResendEmailUpdate(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+	if err := uh.us.ResendEmailUpdate(userID); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	writeOK(c, "Verification code sent to new email")
+}
+
 // DeleteUser godoc
 // @Summary Request user deletion
 // @Description Sends verification code to current email before account deletion
@@ -321,6 +490,8 @@ func (uh *UserHandler) VerifyEmailUpdate(c *gin.Context) {
 // @Success 200 {object} SuccessResponce
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Sole owner of a team workspace with other members"
+// @Failure 429 {object} ErrorResponse "Too many failed attempts; see Retry-After"
 // @Router /api/user/me [delete]
 func (uh *UserHandler) DeleteUser(c *gin.Context) {
 	var input DeleteUserInput
@@ -329,7 +500,11 @@ func (uh *UserHandler) DeleteUser(c *gin.Context) {
 		writeError(c, http.StatusBadRequest, err)
 		return
 	}
-	err := uh.us.DeleteUser(userID, input.Password)
+	err := uh.us.DeleteUser(userID, input.Password, sessionClient(c))
+	if errors.Is(err, service.ErrSoleWorkspaceOwner) {
+		writeError(c, http.StatusConflict, err)
+		return
+	}
 	if err != nil {
 		writeError(c, http.StatusBadRequest, err)
 		return
@@ -347,6 +522,8 @@ func (uh *UserHandler) DeleteUser(c *gin.Context) {
 // @Param input body VerifyEmailInput true "Delete verification code"
 // @Success 200 {object} SuccessResponce
 // @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Sole owner of a team workspace with other members"
+// @Failure 429 {object} ErrorResponse "Too many failed attempts; see Retry-After"
 // @Failure 500 {object} ErrorResponse
 // @Router /api/user/me/delete [put]
 func (uh *UserHandler) VerifyEmailDelete(c *gin.Context) {
@@ -357,6 +534,10 @@ func (uh *UserHandler) VerifyEmailDelete(c *gin.Context) {
 		return
 	}
 	err := uh.us.VerifyEmailDelete(userID, input.Code)
+	if errors.Is(err, service.ErrSoleWorkspaceOwner) {
+		writeError(c, http.StatusConflict, err)
+		return
+	}
 	if err != nil {
 		writeError(c, http.StatusBadRequest, err)
 		return
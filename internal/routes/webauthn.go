@@ -0,0 +1,214 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"todolist/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebAuthnHandler struct {
+	ws *service.WebAuthnService
+}
+
+func NewWebAuthnHandler(ws *service.WebAuthnService) *WebAuthnHandler {
+	return &WebAuthnHandler{ws: ws}
+}
+
+type BeginPasskeyRegistrationInput struct {
+	Password string `json:"password"`
+}
+
+type FinishPasskeyRegistrationInput struct {
+	ChallengeID string          `json:"challenge_id" binding:"required"`
+	Name        string          `json:"name" binding:"max=100"`
+	Credential  json.RawMessage `json:"credential" binding:"required" swaggertype:"object"`
+}
+
+type FinishPasskeyLoginInput struct {
+	ChallengeID string          `json:"challenge_id" binding:"required"`
+	Credential  json.RawMessage `json:"credential" binding:"required" swaggertype:"object"`
+}
+
+type RenamePasskeyInput struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// BeginRegistration godoc
+// @Summary Start passkey registration
+// @Description Returns options for navigator.credentials.create() and a challenge ID for the finish step. Needs the password, or a login within the last 10 minutes on accounts without one
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param input body BeginPasskeyRegistrationInput false "Current password"
+// @Success 200 {object} service.WebAuthnCeremony
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse "Log in again first"
+// @Failure 429 {object} ErrorResponse "Too many failed attempts; see Retry-After"
+// @Router /auth/webauthn/register/begin [post]
+func (h *WebAuthnHandler) BeginRegistration(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+	sessionID := c.MustGet("session").(string)
+
+	var input BeginPasskeyRegistrationInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	ceremony, err := h.ws.BeginRegistration(userID, sessionID, input.Password, sessionClient(c))
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, service.ErrReauthRequired) {
+			status = http.StatusForbidden
+		}
+		writeError(c, status, err)
+		return
+	}
+	c.JSON(http.StatusOK, ceremony)
+}
+
+// FinishRegistration godoc
+// @Summary Finish passkey registration
+// @Description Verifies the new credential from the browser and saves it as a passkey
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param input body FinishPasskeyRegistrationInput true "Challenge ID, passkey name and PublicKeyCredential"
+// @Success 201 {object} models.WebAuthnCredential
+// @Failure 400 {object} ErrorResponse
+// @Router /auth/webauthn/register/finish [post]
+func (h *WebAuthnHandler) FinishRegistration(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	var input FinishPasskeyRegistrationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	credential, err := h.ws.FinishRegistration(userID, input.ChallengeID, input.Name, input.Credential)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusCreated, credential)
+}
+
+// BeginLogin godoc
+// @Summary Start passkey login
+// @Description Returns options for navigator.credentials.get() and a challenge ID for the finish step. No username is needed
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} service.WebAuthnCeremony
+// @Failure 429 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /auth/webauthn/login/begin [post]
+func (h *WebAuthnHandler) BeginLogin(c *gin.Context) {
+	ceremony, err := h.ws.BeginLogin(c.ClientIP())
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, ceremony)
+}
+
+// FinishLogin godoc
+// @Summary Finish passkey login
+// @Description Verifies the assertion from the browser and returns access and refresh tokens
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body FinishPasskeyLoginInput true "Challenge ID and PublicKeyCredential"
+// @Success 200 {object} service.LoginResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/webauthn/login/finish [post]
+func (h *WebAuthnHandler) FinishLogin(c *gin.Context) {
+	var input FinishPasskeyLoginInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	resp, err := h.ws.FinishLogin(input.ChallengeID, input.Credential, sessionClient(c))
+	if err != nil {
+		writeError(c, http.StatusUnauthorized, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetAll godoc
+// @Summary List passkeys
+// @Description Lists the passkeys registered for the account
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.WebAuthnCredential
+// @Failure 500 {object} ErrorResponse
+// @Router /api/user/me/passkeys [get]
+func (h *WebAuthnHandler) GetAll(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	credentials, err := h.ws.GetCredentials(userID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, credentials)
+}
+
+// Rename godoc
+// @Summary Rename a passkey
+// @Description Changes the name shown in the passkey list
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Passkey ID"
+// @Param input body RenamePasskeyInput true "New name"
+// @Success 200 {object} SuccessResponce
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/user/me/passkeys/{id} [patch]
+func (h *WebAuthnHandler) Rename(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	var input RenamePasskeyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := h.ws.RenameCredential(userID, c.Param("id"), input.Name); err != nil {
+		writeError(c, http.StatusNotFound, err)
+		return
+	}
+	writeOK(c, "Passkey renamed successfully")
+}
+
+// Delete godoc
+// @Summary Remove a passkey
+// @Description Deletes a passkey; it can no longer be used to log in
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Passkey ID"
+// @Success 200 {object} SuccessResponce
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "It is the last login method"
+// @Router /api/user/me/passkeys/{id} [delete]
+func (h *WebAuthnHandler) Delete(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	if err := h.ws.DeleteCredential(userID, c.Param("id")); err != nil {
+		writeError(c, loginMethodStatus(err, http.StatusNotFound), err)
+		return
+	}
+	writeOK(c, "Passkey removed successfully")
+}
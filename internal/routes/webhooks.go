@@ -0,0 +1,149 @@
+package routes
+
+import (
+	"net/http"
+	"todolist/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	ws *service.WebhookService
+}
+
+func NewWebhookHandler(ws *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{ws: ws}
+}
+
+type CreateWebhookInput struct {
+	URL    string   `json:"url" binding:"required,url"`
+	Events []string `json:"events" binding:"required,min=1"`
+}
+
+type UpdateWebhookInput struct {
+	URL      string   `json:"url" binding:"required,url"`
+	Events   []string `json:"events" binding:"required,min=1"`
+	IsActive *bool    `json:"is_active"`
+}
+
+// Create godoc
+// @Summary Register a webhook
+// @Description Registers an endpoint that receives signed todo events (todo.created, todo.updated, todo.completed, todo.deleted). The url must resolve to a public address. The signing secret is only returned here
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param input body CreateWebhookInput true "Webhook payload"
+// @Success 201 {object} service.CreatedWebhook
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/webhooks [post]
+func (h *WebhookHandler) Create(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	var input CreateWebhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	hook, err := h.ws.CreateWebhook(userID, input.URL, input.Events)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusCreated, hook)
+}
+
+// GetAll godoc
+// @Summary List webhooks
+// @Description Lists webhooks registered by the authenticated user
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.Webhook
+// @Failure 500 {object} ErrorResponse
+// @Router /api/webhooks [get]
+func (h *WebhookHandler) GetAll(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	hooks, err := h.ws.GetWebhooks(userID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, hooks)
+}
+
+// Update godoc
+// @Summary Update a webhook
+// @Description Updates url, subscribed events or active flag; is_active is left unchanged when omitted. Re-enabling resets the failure counter
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Webhook ID"
+// @Param input body UpdateWebhookInput true "Webhook payload"
+// @Success 200 {object} models.Webhook
+// @Failure 400 {object} ErrorResponse
+// @Router /api/webhooks/{id} [put]
+func (h *WebhookHandler) Update(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	var input UpdateWebhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	hook, err := h.ws.UpdateWebhook(userID, c.Param("id"), input.URL, input.Events, input.IsActive)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusOK, hook)
+}
+
+// Delete godoc
+// @Summary Delete a webhook
+// @Description Deletes a webhook and its delivery log
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} SuccessResponce
+// @Failure 500 {object} ErrorResponse
+// @Router /api/webhooks/{id} [delete]
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	if err := h.ws.DeleteWebhook(userID, c.Param("id")); err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	writeOK(c, "Webhook deleted successfully")
+}
+
+// Deliveries godoc
+// @Summary List webhook deliveries
+// @Description Returns the most recent delivery attempts with response codes
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Webhook ID"
+// @Success 200 {array} models.WebhookDelivery
+// @Failure 404 {object} ErrorResponse
+// @Router /api/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) Deliveries(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	deliveries, err := h.ws.GetDeliveries(userID, c.Param("id"))
+	if err != nil {
+		writeError(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
@@ -0,0 +1,270 @@
+package routes
+
+import (
+	"net/http"
+	"todolist/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WorkspaceHandler struct {
+	ws *service.WorkspaceService
+}
+
+func NewWorkspaceHandler(ws *service.WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{ws: ws}
+}
+
+type WorkspaceInput struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type AddMemberInput struct {
+	User string `json:"user" binding:"required" example:"alice"`
+	Role string `json:"role" binding:"required" example:"member"`
+}
+
+type UpdateMemberInput struct {
+	Role string `json:"role" binding:"required" example:"admin"`
+}
+
+// Create godoc
+// @Summary Create a workspace
+// @Description Creates a team workspace owned by the authenticated user
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param input body WorkspaceInput true "Workspace"
+// @Success 201 {object} models.Workspace
+// @Failure 400 {object} ErrorResponse
+// @Router /api/workspaces [post]
+func (h *WorkspaceHandler) Create(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	var input WorkspaceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	ws, err := h.ws.CreateWorkspace(userID, input.Name)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusCreated, ws)
+}
+
+// GetAll godoc
+// @Summary List workspaces
+// @Description Lists the workspaces the authenticated user belongs to, with their role and the active flag
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.Workspace
+// @Failure 500 {object} ErrorResponse
+// @Router /api/workspaces [get]
+func (h *WorkspaceHandler) GetAll(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	workspaces, err := h.ws.GetWorkspaces(userID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, workspaces)
+}
+
+// Get godoc
+// @Summary Get a workspace
+// @Description Returns a workspace the authenticated user belongs to
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Workspace ID"
+// @Success 200 {object} models.Workspace
+// @Failure 404 {object} ErrorResponse
+// @Router /api/workspaces/{id} [get]
+func (h *WorkspaceHandler) Get(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	ws, err := h.ws.GetWorkspace(userID, c.Param("id"))
+	if err != nil {
+		writeError(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, ws)
+}
+
+// Rename godoc
+// @Description Renames a workspace. Requires the owner or admin role. The personal workspace cannot be renamed
+// @Description Renames a workspace. Requires the owner or admin role
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Workspace ID"
+// @Param input body WorkspaceInput true "Workspace"
+// @Success 200 {object} models.Workspace
+// @Failure 400 {object} ErrorResponse
+// @Router /api/workspaces/{id} [patch]
+func (h *WorkspaceHandler) Rename(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	var input WorkspaceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	ws, err := h.ws.RenameWorkspace(userID, c.Param("id"), input.Name)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusOK, ws)
+}
+
+// Delete godoc
+// @Summary Delete a workspace
+// @Description Deletes a team workspace with all its todos. Requires the owner role; personal workspaces cannot be deleted
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Workspace ID"
+// @Success 200 {object} SuccessResponce
+// @Failure 400 {object} ErrorResponse
+// @Router /api/workspaces/{id} [delete]
+func (h *WorkspaceHandler) Delete(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	if err := h.ws.DeleteWorkspace(userID, c.Param("id")); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	writeOK(c, "Workspace deleted successfully")
+}
+
+// Switch godoc
+// @Summary Switch the active workspace
+// @Description Makes the workspace active; todo endpoints then read and write its todos
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Workspace ID"
+// @Success 200 {object} models.Workspace
+// @Failure 404 {object} ErrorResponse
+// @Router /api/workspaces/{id}/switch [post]
+func (h *WorkspaceHandler) Switch(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	ws, err := h.ws.SwitchWorkspace(userID, c.Param("id"))
+	if err != nil {
+		writeError(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, ws)
+}
+
+// Members godoc
+// @Summary List workspace members
+// @Description Lists members of a workspace the authenticated user belongs to
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Workspace ID"
+// @Success 200 {array} models.WorkspaceMember
+// @Failure 404 {object} ErrorResponse
+// @Router /api/workspaces/{id}/members [get]
+func (h *WorkspaceHandler) Members(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	members, err := h.ws.GetMembers(userID, c.Param("id"))
+	if err != nil {
+		writeError(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, members)
+}
+
+// AddMember godoc
+// @Summary Add a workspace member
+// @Description Adds a registered user by username or email. Owners and admins can add members; only owners can grant owner or admin
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Workspace ID"
+// @Param input body AddMemberInput true "Member"
+// @Success 201 {object} models.WorkspaceMember
+// @Failure 400 {object} ErrorResponse
+// @Router /api/workspaces/{id}/members [post]
+func (h *WorkspaceHandler) AddMember(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	var input AddMemberInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	member, err := h.ws.AddMember(userID, c.Param("id"), input.User, input.Role)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusCreated, member)
+}
+
+// UpdateMember godoc
+// @Summary Change a member role
+// @Description Changes a member's role. Requires the owner role; the last owner cannot be demoted
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Workspace ID"
+// @Param userId path string true "Member user ID"
+// @Param input body UpdateMemberInput true "Role"
+// @Success 200 {object} SuccessResponce
+// @Failure 400 {object} ErrorResponse
+// @Router /api/workspaces/{id}/members/{userId} [put]
+func (h *WorkspaceHandler) UpdateMember(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	var input UpdateMemberInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := h.ws.UpdateMemberRole(userID, c.Param("id"), c.Param("userId"), input.Role); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	writeOK(c, "Member role updated successfully")
+}
+
+// RemoveMember godoc
+// @Summary Remove a member or leave
+// @Description Removes a member. Any member can remove themselves to leave; the last owner cannot leave
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Workspace ID"
+// @Param userId path string true "Member user ID"
+// @Success 200 {object} SuccessResponce
+// @Failure 400 {object} ErrorResponse
+// @Router /api/workspaces/{id}/members/{userId} [delete]
+func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
+	userID := c.MustGet("user").(string)
+
+	if err := h.ws.RemoveMember(userID, c.Param("id"), c.Param("userId")); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	writeOK(c, "Member removed successfully")
+}
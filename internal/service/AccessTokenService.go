@@ -0,0 +1,132 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"todolist/internal/models"
+	"todolist/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// accessTokenPrefix tells personal access tokens apart from JWTs and makes
+// them easy to spot in leaked files.
+const accessTokenPrefix = "tdl_"
+
+type AccessTokenRepository interface {
+	Create(t *models.AccessToken) error
+	GetByHash(tokenHash string) (*models.AccessToken, error)
+	GetByUserID(userId string) ([]models.AccessToken, error)
+	Touch(id string) error
+	Delete(id, userId string) error
+	DeleteByUserID(userId string) error
+}
+
+// CreatedAccessToken carries the token itself, which is only shown once.
+type CreatedAccessToken struct {
+	models.AccessToken
+	Token string `json:"token"`
+}
+
+// AccessTokenService manages personal access tokens and authenticates
+// requests with either a token or a session JWT. Tokens are revoked
+// whenever the user is logged out everywhere, e.g. by a password change.
+type AccessTokenService struct {
+	repo        AccessTokenRepository
+	sessions    *SessionService
+	userService *UserService
+}
+
+func NewAccessTokenService(repo AccessTokenRepository, sessions *SessionService, userService *UserService) *AccessTokenService {
+	return &AccessTokenService{repo: repo, sessions: sessions, userService: userService}
+}
+
+// CreateToken issues a token, confirmed with the password or, on accounts
+// without one, a recent login.
+func (s *AccessTokenService) CreateToken(userId, sessionId, password, name string, scopes []string, expiresAt *time.Time, client models.SessionClient) (*CreatedAccessToken, error) {
+	user, err := s.userService.GetUserByID(userId)
+	if err != nil {
+		return nil, errors.New("invalid user")
+	}
+	if err := s.userService.Reauthenticate(user, sessionId, password, client); err != nil {
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	if len(scopes) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(models.AccessTokenScopes, scope) {
+			return nil, errors.New("unknown scope: " + scope)
+		}
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, errors.New("expiry must be in the future")
+	}
+
+	secret, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, err
+	}
+	token := accessTokenPrefix + secret
+	created := &CreatedAccessToken{
+		AccessToken: models.AccessToken{
+			Id:        uuid.New().String(),
+			UserID:    userId,
+			Name:      name,
+			TokenHash: utils.HashToken(token),
+			Scopes:    slices.Compact(slices.Sorted(slices.Values(scopes))),
+			ExpiresAt: expiresAt,
+			CreatedAt: time.Now(),
+		},
+		Token: token,
+	}
+	if err := s.repo.Create(&created.AccessToken); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *AccessTokenService) GetTokens(userId string) ([]models.AccessToken, error) {
+	return s.repo.GetByUserID(userId)
+}
+
+// OnSessionsRevoked removes all tokens of a user who was logged out
+// everywhere.
+func (s *AccessTokenService) OnSessionsRevoked(userId string) error {
+	return s.repo.DeleteByUserID(userId)
+}
+
+func (s *AccessTokenService) RevokeToken(userId, id string) error {
+	if err := s.repo.Delete(id, userId); err != nil {
+		return errors.New("token not found")
+	}
+	return nil
+}
+
+// Authenticate accepts a personal access token or a session JWT. Claims of
+// a personal access token have no session and carry the token's scopes.
+func (s *AccessTokenService) Authenticate(token, ip string) (*utils.Claims, error) {
+	if !strings.HasPrefix(token, accessTokenPrefix) {
+		return s.sessions.Authenticate(token, ip)
+	}
+	stored, err := s.repo.GetByHash(utils.HashToken(token))
+	if err != nil {
+		return nil, errors.New("invalid access token")
+	}
+	if stored.ExpiresAt != nil && time.Now().After(*stored.ExpiresAt) {
+		return nil, errors.New("access token expired")
+	}
+	if stored.LastUsedAt == nil || time.Since(*stored.LastUsedAt) > sessionTouchInterval {
+		if err := s.repo.Touch(stored.Id); err != nil {
+			return nil, err
+		}
+	}
+	return &utils.Claims{UserID: stored.UserID, Scopes: stored.Scopes}, nil
+}
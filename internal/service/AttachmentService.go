@@ -0,0 +1,236 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"todolist/internal/models"
+	"todolist/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// DefaultAttachmentMaxBytes is used when no size limit is configured.
+const DefaultAttachmentMaxBytes = 10 << 20
+
+// attachmentTypes are the MIME types accepted for upload. The type is sniffed
+// from the file contents; the name and the client's Content-Type are ignored.
+var attachmentTypes = []string{
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"image/webp",
+	"application/pdf",
+}
+
+var (
+	ErrAttachmentTooLarge = errors.New("file is too large")
+	ErrAttachmentType     = errors.New("only PNG, JPEG, GIF, WebP images and PDF files can be attached")
+)
+
+type AttachmentRepository interface {
+	Create(a *models.TodoAttachment) error
+	GetByID(id, todoId string) (*models.TodoAttachment, error)
+	GetListByTodoID(todoId string) ([]models.TodoAttachment, error)
+	GetKeysRemovedWithUser(userId string) ([]string, error)
+	GetKeysByWorkspaceID(workspaceId string) ([]string, error)
+	Delete(id, todoId string) error
+}
+
+// AttachmentService stores files attached to todos. Metadata is kept in the
+// database and contents in blob storage under "<todoId>/<attachmentId>".
+type AttachmentService struct {
+	repo        AttachmentRepository
+	store       storage.Storage
+	todoService *TodoService
+	maxBytes    int64
+}
+
+func NewAttachmentService(repo AttachmentRepository, store storage.Storage, todoService *TodoService, maxBytes int64) *AttachmentService {
+	if maxBytes <= 0 {
+		maxBytes = DefaultAttachmentMaxBytes
+	}
+	return &AttachmentService{repo: repo, store: store, todoService: todoService, maxBytes: maxBytes}
+}
+
+func (s *AttachmentService) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+// writableTodo returns a todo the user may change; viewers of a shared todo
+// can download attachments but not add or remove them.
+func (s *AttachmentService) writableTodo(userId, todoId string) (*models.Todo, error) {
+	todo, err := s.todoService.GetTodo(userId, todoId)
+	if err != nil {
+		return nil, err
+	}
+	if todo.Permission == models.ShareRoleViewer {
+		return nil, errors.New("you have read-only access to this todo")
+	}
+	return todo, nil
+}
+
+func attachmentFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "." || name == "/" || name == "" {
+		return "attachment"
+	}
+	if len(name) > 255 {
+		name = strings.ToValidUTF8(name[:255], "")
+	}
+	return name
+}
+
+func (s *AttachmentService) Upload(ctx context.Context, userId, todoId, filename string, size int64, r io.Reader) (*models.TodoAttachment, error) {
+	todo, err := s.writableTodo(userId, todoId)
+	if err != nil {
+		return nil, err
+	}
+	if size > s.maxBytes {
+		return nil, ErrAttachmentTooLarge
+	}
+	if size <= 0 {
+		return nil, errors.New("file is empty")
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	head = head[:n]
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if !slices.Contains(attachmentTypes, contentType) {
+		return nil, ErrAttachmentType
+	}
+
+	attachment := &models.TodoAttachment{
+		Id:          uuid.New().String(),
+		TodoID:      todo.Id,
+		UserID:      userId,
+		Filename:    attachmentFilename(filename),
+		ContentType: contentType,
+		Size:        size,
+		CreatedAt:   time.Now(),
+	}
+	attachment.StorageKey = todo.Id + "/" + attachment.Id
+
+	body := io.MultiReader(bytes.NewReader(head), r)
+	if err := s.store.Put(ctx, attachment.StorageKey, body, size, contentType); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(attachment); err != nil {
+		s.removeBlob(attachment.StorageKey)
+		return nil, err
+	}
+	return attachment, nil
+}
+
+func (s *AttachmentService) GetAttachments(userId, todoId string) ([]models.TodoAttachment, error) {
+	if _, err := s.todoService.GetTodo(userId, todoId); err != nil {
+		return nil, err
+	}
+	return s.repo.GetListByTodoID(todoId)
+}
+
+// Open returns the attachment metadata and its contents. The caller closes
+// the reader.
+func (s *AttachmentService) Open(ctx context.Context, userId, todoId, id string) (*models.TodoAttachment, io.ReadCloser, error) {
+	todo, err := s.todoService.GetTodo(userId, todoId)
+	if err != nil {
+		return nil, nil, err
+	}
+	attachment, err := s.repo.GetByID(id, todo.Id)
+	if err != nil {
+		return nil, nil, errors.New("attachment not found")
+	}
+	contents, err := s.store.Open(ctx, attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, errors.New("attachment not found")
+		}
+		return nil, nil, err
+	}
+	return attachment, contents, nil
+}
+
+func (s *AttachmentService) DeleteAttachment(userId, todoId, id string) error {
+	todo, err := s.writableTodo(userId, todoId)
+	if err != nil {
+		return err
+	}
+	attachment, err := s.repo.GetByID(id, todo.Id)
+	if err != nil {
+		return errors.New("attachment not found")
+	}
+	if err := s.repo.Delete(attachment.Id, todo.Id); err != nil {
+		return err
+	}
+	s.removeBlob(attachment.StorageKey)
+	return nil
+}
+
+func (s *AttachmentService) removeBlob(key string) {
+	if err := s.store.Delete(context.Background(), key); err != nil {
+		slog.Error("Failed to delete attachment blob", "error", err, "key", key)
+	}
+}
+
+// HandleTodoEvent removes the blobs of a deleted todo. Their rows are already
+// gone by cascade.
+func (s *AttachmentService) HandleTodoEvent(event models.TodoEvent) {
+	if event.Type != models.TodoDeleted {
+		return
+	}
+	go func() {
+		if err := s.store.DeletePrefix(context.Background(), event.Todo.Id); err != nil {
+			slog.Error("Failed to delete todo attachments", "error", err, "todo_id", event.Todo.Id)
+		}
+	}()
+}
+
+// BeforeUserDelete collects the blobs that go away with the user's rows and
+// removes them after the account is deleted.
+func (s *AttachmentService) BeforeUserDelete(userId string) (func(), error) {
+	keys, err := s.repo.GetKeysRemovedWithUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		go func() {
+			for _, key := range keys {
+				s.removeBlob(key)
+			}
+		}()
+	}, nil
+}
+
+// BeforeWorkspaceDelete collects the blobs of the workspace's todos and
+// removes them after the workspace is deleted.
+func (s *AttachmentService) BeforeWorkspaceDelete(workspaceId string) (func(), error) {
+	keys, err := s.repo.GetKeysByWorkspaceID(workspaceId)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		go func() {
+			for _, key := range keys {
+				s.removeBlob(key)
+			}
+		}()
+	}, nil
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"todolist/internal/models"
+)
+
+// AttemptPolicy describes how failures of one kind of key are throttled.
+// The first Free failures cost nothing; every further one locks the key for
+// Base, doubling each time up to Max. Counters reset after Window without
+// failures or on success.
+type AttemptPolicy struct {
+	Free   int
+	Base   time.Duration
+	Max    time.Duration
+	Window time.Duration
+}
+
+var (
+	// accountAttemptPolicy guards a single account against password and
+	// code guessing.
+	accountAttemptPolicy = AttemptPolicy{Free: 5, Base: time.Second, Max: 15 * time.Minute, Window: time.Hour}
+	// ipAttemptPolicy is looser, since many users may share an address, and
+	// catches guessing spread over many accounts.
+	ipAttemptPolicy = AttemptPolicy{Free: 20, Base: time.Second, Max: time.Hour, Window: time.Hour}
+	// resendCooldownPolicy allows one resent code per address a minute.
+	resendCooldownPolicy = AttemptPolicy{Free: 0, Base: time.Minute, Max: time.Minute, Window: time.Minute}
+	// resendDailyPolicy blocks an address for a day once it got
+	// resendDailyCap resent codes.
+	resendDailyPolicy = AttemptPolicy{Free: resendDailyCap - 1, Base: 24 * time.Hour, Max: 24 * time.Hour, Window: 24 * time.Hour}
+	// ceremonyIPPolicy caps unauthenticated passkey logins, each of which
+	// stores a challenge, at 30 a minute per address.
+	ceremonyIPPolicy = AttemptPolicy{Free: 30, Base: time.Minute, Max: time.Minute, Window: time.Minute}
+)
+
+// verificationCodeMaxFailures is how many wrong guesses an emailed code
+// survives before it is invalidated.
+const verificationCodeMaxFailures = 5
+
+// resendDailyCap is how many codes can be resent to one address a day.
+const resendDailyCap = 5
+
+func (p AttemptPolicy) lockout(failures int) time.Duration {
+	over := failures - p.Free
+	if over <= 0 {
+		return 0
+	}
+	if over > 30 {
+		return p.Max
+	}
+	return min(p.Base<<(over-1), p.Max)
+}
+
+// TooManyAttemptsError is returned while a key is locked out. Handlers
+// answer it with 429 and a Retry-After header.
+type TooManyAttemptsError struct {
+	RetryAfter time.Duration
+}
+
+func (e *TooManyAttemptsError) Error() string {
+	return fmt.Sprintf("too many failed attempts, try again in %d seconds", e.RetryAfterSeconds())
+}
+
+// RetryAfterSeconds rounds the wait up to whole seconds.
+func (e *TooManyAttemptsError) RetryAfterSeconds() int {
+	return max(1, int(math.Ceil(e.RetryAfter.Seconds())))
+}
+
+type AuthAttemptRepository interface {
+	Get(key string) (*models.AuthAttempt, error)
+	RecordFailure(key string, window time.Duration) (int, error)
+	Lock(key string, until time.Time) error
+	Reset(key string) error
+}
+
+// AttemptKey names what is being throttled, e.g. logins to one account.
+type AttemptKey struct {
+	Key    string
+	Policy AttemptPolicy
+}
+
+func accountAttempts(scope, userId string) AttemptKey {
+	return AttemptKey{Key: scope + ":user:" + userId, Policy: accountAttemptPolicy}
+}
+
+// publicLinkAttempts guards the password of a single public link.
+func publicLinkAttempts(linkId string) AttemptKey {
+	return AttemptKey{Key: "public-link:link:" + linkId, Policy: accountAttemptPolicy}
+}
+
+// ipAttempts returns an empty key, which is ignored, when the client IP is
+// unknown.
+func ipAttempts(scope, ip string) AttemptKey {
+	if ip == "" {
+		return AttemptKey{}
+	}
+	return AttemptKey{Key: scope + ":ip:" + ip, Policy: ipAttemptPolicy}
+}
+
+// ceremonyAttempts counts every passkey login started from an address.
+func ceremonyAttempts(ip string) AttemptKey {
+	if ip == "" {
+		return AttemptKey{}
+	}
+	return AttemptKey{Key: "webauthn-begin:ip:" + ip, Policy: ceremonyIPPolicy}
+}
+
+// resendAttempts throttles sending emails of one kind (scope) to an address.
+// Here every request counts as an attempt, not only failed ones.
+func resendAttempts(scope, email string) []AttemptKey {
+	email = strings.ToLower(strings.TrimSpace(email))
+	return []AttemptKey{
+		{Key: scope + ":email:" + email, Policy: resendCooldownPolicy},
+		{Key: scope + "-daily:email:" + email, Policy: resendDailyPolicy},
+	}
+}
+
+// AttemptLimiter counts failed authentication attempts in the database, so
+// limits hold across restarts and server instances.
+type AttemptLimiter struct {
+	repo AuthAttemptRepository
+}
+
+func NewAttemptLimiter(repo AuthAttemptRepository) *AttemptLimiter {
+	return &AttemptLimiter{repo: repo}
+}
+
+// Check returns a TooManyAttemptsError if any of the keys is locked out.
+func (l *AttemptLimiter) Check(keys ...AttemptKey) error {
+	var wait time.Duration
+	for _, key := range keys {
+		if key.Key == "" {
+			continue
+		}
+		attempt, err := l.repo.Get(key.Key)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if attempt.LockedUntil != nil {
+			wait = max(wait, time.Until(*attempt.LockedUntil))
+		}
+	}
+	if wait > 0 {
+		return &TooManyAttemptsError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// Fail records a failure for every key and locks those past their free
+// attempts. It returns the failure count of the first key.
+func (l *AttemptLimiter) Fail(keys ...AttemptKey) (int, error) {
+	first := 0
+	for i, key := range keys {
+		if key.Key == "" {
+			continue
+		}
+		failures, err := l.repo.RecordFailure(key.Key, key.Policy.Window)
+		if err != nil {
+			return 0, err
+		}
+		if i == 0 {
+			first = failures
+		}
+		if lockout := key.Policy.lockout(failures); lockout > 0 {
+			if err := l.repo.Lock(key.Key, time.Now().Add(lockout)); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return first, nil
+}
+
+// Reset clears the counter of a key after a successful attempt.
+func (l *AttemptLimiter) Reset(key AttemptKey) error {
+	if key.Key == "" {
+		return nil
+	}
+	return l.repo.Reset(key.Key)
+}
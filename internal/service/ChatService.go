@@ -0,0 +1,294 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"todolist/internal/models"
+	"todolist/internal/utils"
+)
+
+const (
+	chatLinkCodeTTL   = 10 * time.Minute
+	chatListLimit     = 20
+	chatActionDone    = "complete_todo"
+	chatResponseOwn   = "ephemeral"
+	chatResponseShare = "in_channel"
+)
+
+const chatHelp = "*Usage:*\n" +
+	"`/todo add <text>` create a todo, e.g. `/todo add pay rent friday at 9am #home !high`\n" +
+	"`/todo list` show pending todos\n" +
+	"`/todo done <id>` complete a todo by ID or ID prefix\n" +
+	"`/todo link <code>` link this chat account using a code from the web app\n" +
+	"`/todo unlink` disconnect this chat account"
+
+type ChatRepository interface {
+	CreateLinkCode(c *models.ChatLinkCode) error
+	ConsumeLinkCode(code string) (string, error)
+	DeleteExpiredLinkCodes() error
+	UpsertLink(l *models.ChatLink) error
+	GetLink(provider, teamId, chatUserId string) (*models.ChatLink, error)
+	GetLinksByUserID(userId string) ([]models.ChatLink, error)
+	DeleteLink(provider, teamId, chatUserId string) error
+	DeleteLinksByUserID(userId string) error
+}
+
+// ChatCommand is a parsed slash command invocation.
+type ChatCommand struct {
+	TeamID     string
+	ChatUserID string
+	Text       string
+}
+
+// ChatService runs slash commands and interactive actions from a chat
+// workspace on behalf of the linked TodoList user.
+type ChatService struct {
+	repo        ChatRepository
+	todoService *TodoService
+	provider    string
+}
+
+func NewChatService(repo ChatRepository, todoService *TodoService) *ChatService {
+	return &ChatService{repo: repo, todoService: todoService, provider: models.ChatProviderSlack}
+}
+
+// CreateLinkCode issues a one-time code the user sends with `/todo link`.
+func (s *ChatService) CreateLinkCode(userId string) (*models.ChatLinkCode, error) {
+	if err := s.repo.DeleteExpiredLinkCodes(); err != nil {
+		return nil, err
+	}
+	code, err := utils.GenerateSecureToken(4)
+	if err != nil {
+		return nil, err
+	}
+	c := &models.ChatLinkCode{
+		Code:      strings.ToUpper(code),
+		UserID:    userId,
+		ExpiresAt: time.Now().Add(chatLinkCodeTTL),
+	}
+	if err := s.repo.CreateLinkCode(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *ChatService) GetLinks(userId string) ([]models.ChatLink, error) {
+	return s.repo.GetLinksByUserID(userId)
+}
+
+func (s *ChatService) DeleteLinks(userId string) error {
+	return s.repo.DeleteLinksByUserID(userId)
+}
+
+func (s *ChatService) linkedUser(teamId, chatUserId string) (string, error) {
+	link, err := s.repo.GetLink(s.provider, teamId, chatUserId)
+	if err != nil {
+		return "", err
+	}
+	return link.UserID, nil
+}
+
+// HandleCommand executes `/todo <text>`. User-facing problems are returned
+// as chat messages; only infrastructure failures are returned as errors.
+func (s *ChatService) HandleCommand(cmd ChatCommand) (*models.ChatMessage, error) {
+	verb, args, _ := strings.Cut(strings.TrimSpace(cmd.Text), " ")
+	verb = strings.ToLower(verb)
+	args = strings.TrimSpace(args)
+
+	switch verb {
+	case "", "help":
+		return chatText(chatHelp), nil
+	case "link":
+		return s.link(cmd, args)
+	}
+
+	userId, err := s.linkedUser(cmd.TeamID, cmd.ChatUserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return chatText("Your chat account is not linked yet. Generate a code in the TodoList settings and run `/todo link <code>`."), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch verb {
+	case "add":
+		return s.add(userId, args)
+	case "list", "ls":
+		return s.list(userId)
+	case "done":
+		return s.done(userId, args)
+	case "unlink":
+		if err := s.repo.DeleteLink(s.provider, cmd.TeamID, cmd.ChatUserID); err != nil {
+			return nil, err
+		}
+		return chatText("Your chat account has been unlinked."), nil
+	default:
+		return chatText(fmt.Sprintf("Unknown command `%s`.\n%s", verb, chatHelp)), nil
+	}
+}
+
+// HandleAction processes a button click from a message sent by list.
+func (s *ChatService) HandleAction(teamId, chatUserId, actionId, value string) (*models.ChatMessage, error) {
+	userId, err := s.linkedUser(teamId, chatUserId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return chatText("Your chat account is not linked."), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if actionId != chatActionDone {
+		return chatText("Unsupported action."), nil
+	}
+
+	todo, err := s.todoService.GetTodo(userId, value)
+	if err != nil {
+		return chatText("Todo not found."), nil
+	}
+	if err := s.complete(userId, todo); err != nil {
+		return nil, err
+	}
+	msg, err := s.list(userId)
+	if err != nil {
+		return nil, err
+	}
+	msg.ReplaceOriginal = true
+	msg.Text = fmt.Sprintf(":white_check_mark: Completed *%s*", todo.Title)
+	msg.Blocks = append([]models.ChatBlock{chatSection(msg.Text, nil)}, msg.Blocks...)
+	return msg, nil
+}
+
+func (s *ChatService) link(cmd ChatCommand, code string) (*models.ChatMessage, error) {
+	if code == "" {
+		return chatText("Usage: `/todo link <code>`"), nil
+	}
+	userId, err := s.repo.ConsumeLinkCode(strings.ToUpper(code))
+	if errors.Is(err, sql.ErrNoRows) {
+		return chatText("This code is invalid or has expired."), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = s.repo.UpsertLink(&models.ChatLink{
+		Provider:   s.provider,
+		TeamID:     cmd.TeamID,
+		ChatUserID: cmd.ChatUserID,
+		UserID:     userId,
+		CreatedAt:  time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chatText("Linked! Try `/todo add buy milk tomorrow`."), nil
+}
+
+func (s *ChatService) add(userId, text string) (*models.ChatMessage, error) {
+	if text == "" {
+		return chatText("Usage: `/todo add <text>`"), nil
+	}
+	todo, err := s.todoService.QuickAddTodo(userId, text, time.UTC)
+	if err != nil {
+		return chatText("Could not create todo: " + err.Error()), nil
+	}
+	return chatText(fmt.Sprintf(":memo: Created *%s* (due %s, id `%s`)", todo.Title, formatChatTime(todo.Deadline), shortTodoID(todo.Id))), nil
+}
+
+func (s *ChatService) list(userId string) (*models.ChatMessage, error) {
+	pending := false
+	todos, err := s.todoService.FindTodos(userId, TodoFilter{Completed: &pending})
+	if err != nil {
+		return nil, err
+	}
+	if len(todos) == 0 {
+		return chatText(":tada: No pending todos."), nil
+	}
+
+	msg := &models.ChatMessage{
+		ResponseType: chatResponseOwn,
+		Text:         fmt.Sprintf("You have %d pending todos", len(todos)),
+	}
+	now := time.Now()
+	for i, todo := range todos {
+		if i == chatListLimit {
+			msg.Blocks = append(msg.Blocks, models.ChatBlock{
+				Type:     "context",
+				Elements: []models.ChatElement{{Type: "mrkdwn", Text: &models.ChatText{Type: "mrkdwn", Text: fmt.Sprintf("…and %d more", len(todos)-chatListLimit)}}},
+			})
+			break
+		}
+		line := fmt.Sprintf("*%s*\n`%s` due %s", todo.Title, shortTodoID(todo.Id), formatChatTime(todo.Deadline))
+		if todo.Deadline.Before(now) {
+			line += " :warning: overdue"
+		}
+		msg.Blocks = append(msg.Blocks, chatSection(line, &models.ChatElement{
+			Type:     "button",
+			Text:     &models.ChatText{Type: "plain_text", Text: "Complete"},
+			ActionID: chatActionDone,
+			Value:    todo.Id,
+			Style:    "primary",
+		}))
+	}
+	return msg, nil
+}
+
+func (s *ChatService) done(userId, id string) (*models.ChatMessage, error) {
+	if id == "" {
+		return chatText("Usage: `/todo done <id>`"), nil
+	}
+	todos, err := s.todoService.GetTodosByUserID(userId)
+	if err != nil {
+		return nil, err
+	}
+	var match *models.Todo
+	for i := range todos {
+		if strings.HasPrefix(todos[i].Id, id) {
+			if match != nil {
+				return chatText(fmt.Sprintf("ID `%s` matches several todos, use more characters.", id)), nil
+			}
+			match = &todos[i]
+		}
+	}
+	if match == nil {
+		return chatText(fmt.Sprintf("Todo `%s` not found.", id)), nil
+	}
+	if err := s.complete(userId, match); err != nil {
+		return nil, err
+	}
+	return &models.ChatMessage{
+		ResponseType: chatResponseShare,
+		Text:         fmt.Sprintf(":white_check_mark: Completed *%s*", match.Title),
+	}, nil
+}
+
+func (s *ChatService) complete(userId string, todo *models.Todo) error {
+	if todo.Completed {
+		return nil
+	}
+	todo.Completed = true
+	return s.todoService.UpdateTodo(userId, todo)
+}
+
+func chatText(text string) *models.ChatMessage {
+	return &models.ChatMessage{ResponseType: chatResponseOwn, Text: text}
+}
+
+func chatSection(text string, accessory *models.ChatElement) models.ChatBlock {
+	return models.ChatBlock{
+		Type:      "section",
+		Text:      &models.ChatText{Type: "mrkdwn", Text: text},
+		Accessory: accessory,
+	}
+}
+
+func formatChatTime(t time.Time) string {
+	return fmt.Sprintf("<!date^%d^{date_short_pretty} {time}|%s>", t.Unix(), t.UTC().Format("2006-01-02 15:04 UTC"))
+}
+
+func shortTodoID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
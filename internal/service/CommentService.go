@@ -0,0 +1,196 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"todolist/internal/models"
+	"todolist/internal/utils"
+
+	"github.com/google/uuid"
+	"github.com/yuin/goldmark"
+)
+
+const maxCommentLength = 10000
+
+// mentionPattern matches @username not preceded by a word character, so
+// email addresses in a comment are not treated as mentions.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9_.-]+)`)
+
+// goldmark's default renderer drops raw HTML and dangerous link schemes.
+var commentMarkdown = goldmark.New()
+
+type CommentRepository interface {
+	Create(comment *models.TodoComment) error
+	GetByID(id, todoId string) (*models.TodoComment, error)
+	GetListByTodoID(todoId string) ([]models.TodoComment, error)
+	Update(comment *models.TodoComment) error
+	Delete(id, todoId string) error
+}
+
+// CommentService manages comments on todos. Anyone who can see a todo can
+// comment on it; only the author can edit or delete a comment.
+type CommentService struct {
+	repo          CommentRepository
+	userRepo      UserRepository
+	workspaceRepo WorkspaceRepository
+	shares        SharedTodoRepository
+	todoService   *TodoService
+}
+
+func NewCommentService(repo CommentRepository, userRepo UserRepository, workspaceRepo WorkspaceRepository, shares SharedTodoRepository, todoService *TodoService) *CommentService {
+	return &CommentService{repo: repo, userRepo: userRepo, workspaceRepo: workspaceRepo, shares: shares, todoService: todoService}
+}
+
+func validateCommentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", errors.New("comment body is empty")
+	}
+	if len(body) > maxCommentLength {
+		return "", errors.New("comment is too long")
+	}
+	return body, nil
+}
+
+// parseMentions returns the distinct usernames mentioned in body.
+func parseMentions(body string) []string {
+	mentions := []string{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		username := strings.TrimRight(match[1], ".-")
+		if username != "" && !slices.Contains(mentions, username) {
+			mentions = append(mentions, username)
+		}
+	}
+	return mentions
+}
+
+func renderComment(comment *models.TodoComment) {
+	var buf bytes.Buffer
+	if err := commentMarkdown.Convert([]byte(comment.Body), &buf); err == nil {
+		comment.BodyHTML = buf.String()
+	}
+	comment.Mentions = parseMentions(comment.Body)
+}
+
+// canAccess reports whether user can see todo, either as a member of its
+// workspace or through a share.
+func (s *CommentService) canAccess(userId string, todo *models.Todo) bool {
+	if _, err := s.workspaceRepo.GetForMember(todo.WorkspaceID, userId); err == nil {
+		return true
+	}
+	_, err := s.shares.GetSharedTodo(todo.Id, userId)
+	return err == nil
+}
+
+// notifyMentions emails mentioned users who can access the todo. Users
+// listed in skip (already notified) and the author are left out.
+func (s *CommentService) notifyMentions(author *models.User, todo *models.Todo, comment *models.TodoComment, skip []string) {
+	for _, username := range comment.Mentions {
+		if slices.Contains(skip, username) || username == author.Username {
+			continue
+		}
+		user, err := s.userRepo.GetByUsername(username)
+		if err != nil || !user.IsVerified || !s.canAccess(user.Id, todo) {
+			continue
+		}
+		go utils.SendMentionEmail(user.Email, author.Username, todo.Title, comment.Body)
+	}
+}
+
+func (s *CommentService) GetComments(userId, todoId string) ([]models.TodoComment, error) {
+	if _, err := s.todoService.GetTodo(userId, todoId); err != nil {
+		return nil, err
+	}
+	comments, err := s.repo.GetListByTodoID(todoId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range comments {
+		renderComment(&comments[i])
+	}
+	return comments, nil
+}
+
+func (s *CommentService) AddComment(userId, todoId, body string) (*models.TodoComment, error) {
+	body, err := validateCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+	todo, err := s.todoService.GetTodo(userId, todoId)
+	if err != nil {
+		return nil, err
+	}
+	author, err := s.userRepo.GetByID(userId)
+	if err != nil {
+		return nil, err
+	}
+	comment := &models.TodoComment{
+		Id:        uuid.New().String(),
+		TodoID:    todo.Id,
+		UserID:    userId,
+		Username:  author.Username,
+		Body:      body,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := s.repo.Create(comment); err != nil {
+		return nil, err
+	}
+	renderComment(comment)
+	s.notifyMentions(author, todo, comment, nil)
+	return comment, nil
+}
+
+// authoredComment returns a comment the user wrote on a todo they can access.
+func (s *CommentService) authoredComment(userId, todoId, commentId string) (*models.Todo, *models.TodoComment, error) {
+	todo, err := s.todoService.GetTodo(userId, todoId)
+	if err != nil {
+		return nil, nil, err
+	}
+	comment, err := s.repo.GetByID(commentId, todo.Id)
+	if err != nil {
+		return nil, nil, errors.New("comment not found")
+	}
+	if comment.UserID != userId {
+		return nil, nil, errors.New("only the author can change a comment")
+	}
+	return todo, comment, nil
+}
+
+// UpdateComment edits a comment. Only users mentioned for the first time
+// are notified.
+func (s *CommentService) UpdateComment(userId, todoId, commentId, body string) (*models.TodoComment, error) {
+	body, err := validateCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+	todo, comment, err := s.authoredComment(userId, todoId, commentId)
+	if err != nil {
+		return nil, err
+	}
+	author, err := s.userRepo.GetByID(userId)
+	if err != nil {
+		return nil, err
+	}
+	previous := parseMentions(comment.Body)
+	comment.Body = body
+	comment.UpdatedAt = time.Now()
+	if err := s.repo.Update(comment); err != nil {
+		return nil, err
+	}
+	renderComment(comment)
+	s.notifyMentions(author, todo, comment, previous)
+	return comment, nil
+}
+
+func (s *CommentService) DeleteComment(userId, todoId, commentId string) error {
+	_, comment, err := s.authoredComment(userId, todoId, commentId)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(comment.Id, comment.TodoID)
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"sync"
+	"todolist/internal/models"
+)
+
+const (
+	eventHistorySize     = 100
+	subscriberBufferSize = 32
+)
+
+type StreamEvent struct {
+	ID    uint64
+	Event models.TodoEvent
+}
+
+// EventBroker keeps live subscribers and a short per-user history of todo
+// events so that reconnecting clients can resume from their last event ID.
+type EventBroker struct {
+	mu          sync.Mutex
+	lastID      uint64
+	history     map[string][]StreamEvent
+	subscribers map[string]map[chan StreamEvent]struct{}
+}
+
+func NewEventBroker() *EventBroker {
+	return &EventBroker{
+		history:     make(map[string][]StreamEvent),
+		subscribers: make(map[string]map[chan StreamEvent]struct{}),
+	}
+}
+
+func (b *EventBroker) HandleTodoEvent(event models.TodoEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	streamEvent := StreamEvent{ID: b.lastID, Event: event}
+
+	for _, userId := range event.Recipients {
+		history := append(b.history[userId], streamEvent)
+		if len(history) > eventHistorySize {
+			history = history[len(history)-eventHistorySize:]
+		}
+		b.history[userId] = history
+
+		for ch := range b.subscribers[userId] {
+			select {
+			case ch <- streamEvent:
+			default:
+				// The client is too slow; drop it so it reconnects and replays from history.
+				delete(b.subscribers[userId], ch)
+				close(ch)
+			}
+		}
+	}
+}
+
+// Subscribe registers a listener for the user's events. Events newer than
+// lastEventID that are still in history are returned for replay.
+func (b *EventBroker) Subscribe(userId string, lastEventID uint64) (<-chan StreamEvent, []StreamEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var missed []StreamEvent
+	if lastEventID > 0 && lastEventID <= b.lastID {
+		for _, event := range b.history[userId] {
+			if event.ID > lastEventID {
+				missed = append(missed, event)
+			}
+		}
+	}
+
+	ch := make(chan StreamEvent, subscriberBufferSize)
+	if b.subscribers[userId] == nil {
+		b.subscribers[userId] = make(map[chan StreamEvent]struct{})
+	}
+	b.subscribers[userId][ch] = struct{}{}
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[userId][ch]; ok {
+			delete(b.subscribers[userId], ch)
+			close(ch)
+		}
+		if len(b.subscribers[userId]) == 0 {
+			delete(b.subscribers, userId)
+		}
+	}
+	return ch, missed, unsubscribe
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"errors"
+	"time"
+	"todolist/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ErrLastLoginMethod keeps users from locking themselves out.
+var ErrLastLoginMethod = errors.New("you cannot remove your last login method")
+
+type UserIdentityRepository interface {
+	Create(i *models.UserIdentity) error
+	GetByProviderSubject(provider, subject string) (*models.UserIdentity, error)
+	GetByUserID(userId string) ([]models.UserIdentity, error)
+	Touch(id string) error
+	Delete(id, userId string) error
+}
+
+// IdentityService manages how a user can log in: linked provider
+// identities, the password and passkeys. At least one of them always stays.
+type IdentityService struct {
+	repo        UserIdentityRepository
+	users       UserRepository
+	passkeys    WebAuthnRepository
+	userService *UserService
+}
+
+func NewIdentityService(repo UserIdentityRepository, users UserRepository, passkeys WebAuthnRepository, userService *UserService) *IdentityService {
+	return &IdentityService{repo: repo, users: users, passkeys: passkeys, userService: userService}
+}
+
+func (s *IdentityService) GetIdentities(userId string) ([]models.UserIdentity, error) {
+	return s.repo.GetByUserID(userId)
+}
+
+// Link adds a provider identity to the user, who re-authenticated when the
+// link was started.
+func (s *IdentityService) Link(userId string, data models.OAuthUser) (*models.UserIdentity, error) {
+	existing, err := s.repo.GetByProviderSubject(data.Provider, data.ID)
+	if err == nil {
+		if existing.UserID == userId {
+			return nil, errors.New("this account is already linked")
+		}
+		return nil, errors.New("this account is linked to another user")
+	}
+
+	identity := &models.UserIdentity{
+		Id:        uuid.New().String(),
+		UserID:    userId,
+		Provider:  data.Provider,
+		Subject:   data.ID,
+		Email:     data.Email,
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.Create(identity); err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+func (s *IdentityService) Unlink(userId, id string) error {
+	if err := s.ensureOtherLoginMethod(userId); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(id, userId); err != nil {
+		return errors.New("identity not found")
+	}
+	return nil
+}
+
+// RemovePassword leaves the user to log in with providers or passkeys only.
+func (s *IdentityService) RemovePassword(userId, password string, client models.SessionClient) error {
+	user, err := s.users.GetByID(userId)
+	if err != nil {
+		return errors.New("invalid user")
+	}
+	if user.PasswordHash == "" {
+		return errors.New("no password is set")
+	}
+	if err := s.userService.Reauthenticate(user, "", password, client); err != nil {
+		return err
+	}
+	if err := s.ensureOtherLoginMethod(userId); err != nil {
+		return err
+	}
+	user.PasswordHash = ""
+	return s.users.Update(user)
+}
+
+// ensureOtherLoginMethod fails unless the user has more than one way to log
+// in, so one of them may be removed.
+func (s *IdentityService) ensureOtherLoginMethod(userId string) error {
+	user, err := s.users.GetByID(userId)
+	if err != nil {
+		return errors.New("invalid user")
+	}
+	identities, err := s.repo.GetByUserID(userId)
+	if err != nil {
+		return err
+	}
+	passkeys, err := s.passkeys.GetCredentialsByUserID(userId)
+	if err != nil {
+		return err
+	}
+
+	methods := len(identities) + len(passkeys)
+	if user.PasswordHash != "" {
+		methods++
+	}
+	if methods <= 1 {
+		return ErrLastLoginMethod
+	}
+	return nil
+}
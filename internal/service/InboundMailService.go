@@ -0,0 +1,247 @@
+package service
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
+	"todolist/internal/models"
+	"todolist/internal/utils"
+)
+
+const (
+	inboundMaxTitleLength       = 200
+	inboundMaxDescriptionLength = 10000
+)
+
+type InboundRepository interface {
+	Create(a *models.InboundAddress) error
+	GetByUserID(userId string) (*models.InboundAddress, error)
+	GetByToken(token string) (*models.InboundAddress, error)
+	Update(a *models.InboundAddress) error
+}
+
+// InboundMailService turns mail sent to a user's secret address into todos.
+// It implements smtpd.Backend.
+type InboundMailService struct {
+	repo        InboundRepository
+	userRepo    UserRepository
+	todoService *TodoService
+	domain      string
+}
+
+func NewInboundMailService(repo InboundRepository, userRepo UserRepository, todoService *TodoService, domain string) *InboundMailService {
+	return &InboundMailService{repo: repo, userRepo: userRepo, todoService: todoService, domain: strings.ToLower(domain)}
+}
+
+func (s *InboundMailService) withAddress(a *models.InboundAddress) *models.InboundAddress {
+	a.Address = a.Token + "@" + s.domain
+	return a
+}
+
+// GetAddress returns the user's inbound address, creating one on first use.
+func (s *InboundMailService) GetAddress(userId string) (*models.InboundAddress, error) {
+	a, err := s.repo.GetByUserID(userId)
+	if err == nil {
+		return s.withAddress(a), nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	token, err := utils.GenerateSecureToken(12)
+	if err != nil {
+		return nil, err
+	}
+	a = &models.InboundAddress{
+		UserID:         userId,
+		Token:          token,
+		AllowedSenders: []string{},
+		CreatedAt:      time.Now(),
+	}
+	if err := s.repo.Create(a); err != nil {
+		return nil, err
+	}
+	return s.withAddress(a), nil
+}
+
+func (s *InboundMailService) RotateAddress(userId string) (*models.InboundAddress, error) {
+	a, err := s.GetAddress(userId)
+	if err != nil {
+		return nil, err
+	}
+	token, err := utils.GenerateSecureToken(12)
+	if err != nil {
+		return nil, err
+	}
+	a.Token = token
+	if err := s.repo.Update(a); err != nil {
+		return nil, err
+	}
+	return s.withAddress(a), nil
+}
+
+func (s *InboundMailService) UpdateAllowedSenders(userId string, senders []string) (*models.InboundAddress, error) {
+	a, err := s.GetAddress(userId)
+	if err != nil {
+		return nil, err
+	}
+	normalized := make([]string, 0, len(senders))
+	for _, sender := range senders {
+		parsed, err := mail.ParseAddress(sender)
+		if err != nil {
+			return nil, errors.New("invalid sender address: " + sender)
+		}
+		normalized = append(normalized, strings.ToLower(parsed.Address))
+	}
+	a.AllowedSenders = normalized
+	if err := s.repo.Update(a); err != nil {
+		return nil, err
+	}
+	return s.withAddress(a), nil
+}
+
+func (s *InboundMailService) tokenFromRecipient(rcpt string) (string, bool) {
+	local, domain, ok := strings.Cut(strings.ToLower(rcpt), "@")
+	if !ok || domain != s.domain || local == "" {
+		return "", false
+	}
+	return local, true
+}
+
+func (s *InboundMailService) AcceptRecipient(rcpt string) bool {
+	token, ok := s.tokenFromRecipient(rcpt)
+	if !ok {
+		return false
+	}
+	_, err := s.repo.GetByToken(token)
+	return err == nil
+}
+
+// Deliver creates one todo per recipient address. The envelope sender and the
+// From header must both be the account email or on the allow-list.
+func (s *InboundMailService) Deliver(from string, to []string, data []byte) error {
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return errors.New("malformed message")
+	}
+	headerFrom := from
+	if parsed, err := mail.ParseAddress(msg.Header.Get("From")); err == nil {
+		headerFrom = strings.ToLower(parsed.Address)
+	}
+
+	title, description, err := parseInboundMessage(msg)
+	if err != nil {
+		return err
+	}
+	if title == "" {
+		title = "Email from " + headerFrom
+	}
+
+	// Every recipient is checked before any todo is created, so a rejected
+	// message never leaves a partial import behind.
+	var userIds []string
+	for _, rcpt := range to {
+		token, ok := s.tokenFromRecipient(rcpt)
+		if !ok {
+			continue
+		}
+		a, err := s.repo.GetByToken(token)
+		if err != nil {
+			continue
+		}
+		user, err := s.userRepo.GetByID(a.UserID)
+		if err != nil {
+			continue
+		}
+		allowed := append([]string{strings.ToLower(user.Email)}, a.AllowedSenders...)
+		if !slices.Contains(allowed, from) || !slices.Contains(allowed, headerFrom) {
+			return errors.New("sender not allowed")
+		}
+		if !slices.Contains(userIds, a.UserID) {
+			userIds = append(userIds, a.UserID)
+		}
+	}
+
+	for _, userId := range userIds {
+		if _, err := s.todoService.CreateTodo(userId, title, description, time.Now().Add(defaultDeadline)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseInboundMessage(msg *mail.Message) (string, string, error) {
+	dec := new(mime.WordDecoder)
+	subject, err := dec.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+
+	body, err := extractPlainText(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body)
+	if err != nil {
+		return "", "", errors.New("unreadable message body")
+	}
+	return truncate(strings.TrimSpace(subject), inboundMaxTitleLength),
+		truncate(strings.TrimSpace(body), inboundMaxDescriptionLength), nil
+}
+
+// extractPlainText returns the first text/plain part of a possibly multipart body.
+func extractPlainText(contentType, encoding string, body io.Reader) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if err == io.EOF {
+				return "", nil
+			}
+			if err != nil {
+				return "", err
+			}
+			text, err := extractPlainText(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			if err != nil {
+				return "", err
+			}
+			if text != "" {
+				return text, nil
+			}
+		}
+	}
+
+	if mediaType != "text/plain" {
+		return "", nil
+	}
+
+	switch strings.ToLower(encoding) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+	text, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	return string(text), nil
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
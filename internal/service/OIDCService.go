@@ -0,0 +1,365 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"todolist/internal/models"
+	"todolist/internal/utils"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// oidcStateTTL is how long the user has to log in at the provider.
+const oidcStateTTL = 10 * time.Minute
+
+var ErrUnknownOIDCProvider = errors.New("unknown login provider")
+
+type OIDCStateRepository interface {
+	Create(s *models.OIDCState) error
+	Take(state string) (*models.OIDCState, error)
+}
+
+// OIDCProviderConfig configures one login provider. With Issuer set the
+// endpoints are discovered and the ID token is verified (Keycloak, GitLab,
+// Microsoft, Google...). Plain OAuth 2.0 providers such as GitHub instead set
+// AuthURL, TokenURL and UserInfoURL, and the user is read from UserInfoURL.
+// Emails count as verified only with email_verified=true, unless TrustEmail
+// is set for providers that return verified addresses only but no such
+// claim (e.g. GitHub's primary email).
+type OIDCProviderConfig struct {
+	ID           string
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	AuthURL      string
+	TokenURL     string
+	UserInfoURL  string
+	TrustEmail   bool
+}
+
+// OIDCProvider is what the login page shows for a configured provider.
+type OIDCProvider struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// OIDCAuthorization starts a provider login: the browser keeps State and
+// goes to AuthorizationURL, and the provider redirects back with a code and
+// the same state.
+type OIDCAuthorization struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+}
+
+// oidcProvider discovers the issuer on first use, so an unreachable provider
+// doesn't stop the server from starting.
+type oidcProvider struct {
+	cfg OIDCProviderConfig
+
+	mu       sync.Mutex
+	oauth    *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+	userInfo func(ctx context.Context, token *oauth2.Token) (map[string]any, error)
+}
+
+func (p *oidcProvider) load(redirectURL string) (*oauth2.Config, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.oauth != nil {
+		return p.oauth, nil
+	}
+
+	oauth := &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       p.cfg.Scopes,
+	}
+	if p.cfg.Issuer == "" {
+		oauth.Endpoint = oauth2.Endpoint{AuthURL: p.cfg.AuthURL, TokenURL: p.cfg.TokenURL}
+		p.userInfo = func(ctx context.Context, token *oauth2.Token) (map[string]any, error) {
+			return fetchUserInfo(ctx, oauth.Client(ctx, token), p.cfg.UserInfoURL)
+		}
+		p.oauth = oauth
+		return oauth, nil
+	}
+
+	// The provider keeps this context for fetching signing keys later.
+	provider, err := oidc.NewProvider(context.Background(), p.cfg.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("%s discovery failed: %w", p.cfg.ID, err)
+	}
+	oauth.Endpoint = provider.Endpoint()
+	p.verifier = provider.Verifier(&oidc.Config{ClientID: p.cfg.ClientID})
+	if provider.UserInfoEndpoint() != "" {
+		p.userInfo = func(ctx context.Context, token *oauth2.Token) (map[string]any, error) {
+			info, err := provider.UserInfo(ctx, oauth2.StaticTokenSource(token))
+			if err != nil {
+				return nil, err
+			}
+			var claims map[string]any
+			return claims, info.Claims(&claims)
+		}
+	}
+	p.oauth = oauth
+	return oauth, nil
+}
+
+func fetchUserInfo(ctx context.Context, client *http.Client, url string) (map[string]any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("user info request failed with status %d", resp.StatusCode)
+	}
+	var claims map[string]any
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	return claims, decoder.Decode(&claims)
+}
+
+// OIDCService logs users in with any OpenID Connect or OAuth 2.0 provider
+// using the authorization code flow with PKCE. The state is single use and
+// the ID token must carry the nonce of the login it answers.
+type OIDCService struct {
+	repo        OIDCStateRepository
+	userService *UserService
+	identities  *IdentityService
+	redirectURL string
+	providers   map[string]*oidcProvider
+	order       []string
+}
+
+func NewOIDCService(repo OIDCStateRepository, userService *UserService, identities *IdentityService, redirectURL string, configs []OIDCProviderConfig) (*OIDCService, error) {
+	s := &OIDCService{repo: repo, userService: userService, identities: identities, redirectURL: redirectURL, providers: map[string]*oidcProvider{}}
+	for _, cfg := range configs {
+		if cfg.ClientID == "" {
+			return nil, fmt.Errorf("%s: client ID is not set", cfg.ID)
+		}
+		if cfg.Issuer == "" && (cfg.AuthURL == "" || cfg.TokenURL == "" || cfg.UserInfoURL == "") {
+			return nil, fmt.Errorf("%s: set an issuer or the auth, token and user info URLs", cfg.ID)
+		}
+		if _, ok := s.providers[cfg.ID]; ok {
+			return nil, fmt.Errorf("%s: provider configured twice", cfg.ID)
+		}
+		if cfg.Name == "" {
+			cfg.Name = cfg.ID
+		}
+		if len(cfg.Scopes) == 0 && cfg.Issuer != "" {
+			cfg.Scopes = []string{oidc.ScopeOpenID, "email", "profile"}
+		}
+		s.providers[cfg.ID] = &oidcProvider{cfg: cfg}
+		s.order = append(s.order, cfg.ID)
+	}
+	return s, nil
+}
+
+// Providers lists the configured providers in configuration order.
+func (s *OIDCService) Providers() []OIDCProvider {
+	providers := make([]OIDCProvider, 0, len(s.order))
+	for _, id := range s.order {
+		providers = append(providers, OIDCProvider{ID: id, Name: s.providers[id].cfg.Name})
+	}
+	return providers
+}
+
+// Begin starts a login with a provider.
+func (s *OIDCService) Begin(providerID string) (*OIDCAuthorization, error) {
+	return s.begin(providerID, nil)
+}
+
+// BeginLink starts linking a provider to a signed-in user, who confirms it
+// with their password or a recent login.
+func (s *OIDCService) BeginLink(userId, sessionId, password, providerID string, client models.SessionClient) (*OIDCAuthorization, error) {
+	user, err := s.userService.GetUserByID(userId)
+	if err != nil {
+		return nil, errors.New("invalid user")
+	}
+	if err := s.userService.Reauthenticate(user, sessionId, password, client); err != nil {
+		return nil, err
+	}
+	return s.begin(providerID, &userId)
+}
+
+func (s *OIDCService) begin(providerID string, userId *string) (*OIDCAuthorization, error) {
+	provider, ok := s.providers[providerID]
+	if !ok {
+		return nil, ErrUnknownOIDCProvider
+	}
+	oauth, err := provider.load(s.redirectURL)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, err
+	}
+	verifier := oauth2.GenerateVerifier()
+	err = s.repo.Create(&models.OIDCState{
+		State:        state,
+		Provider:     providerID,
+		UserID:       userId,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(oidcStateTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	options := []oauth2.AuthCodeOption{oauth2.S256ChallengeOption(verifier)}
+	if provider.verifier != nil {
+		options = append(options, oidc.Nonce(nonce))
+	}
+	return &OIDCAuthorization{AuthorizationURL: oauth.AuthCodeURL(state, options...), State: state}, nil
+}
+
+// Finish exchanges the code from the provider's redirect and logs the user
+// in like LoginWithOAuth.
+func (s *OIDCService) Finish(ctx context.Context, code, state string, client models.SessionClient) (*LoginResponse, error) {
+	user, err := s.exchange(ctx, code, state, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.userService.LoginWithOAuth(ctx, *user, client)
+}
+
+// FinishLink exchanges the code from the provider's redirect and links the
+// identity to the user who started the link.
+func (s *OIDCService) FinishLink(ctx context.Context, userId, code, state string) (*models.UserIdentity, error) {
+	user, err := s.exchange(ctx, code, state, &userId)
+	if err != nil {
+		return nil, err
+	}
+	return s.identities.Link(userId, *user)
+}
+
+// exchange spends a started login of userId (nil for logins) and returns the
+// user the provider vouches for.
+func (s *OIDCService) exchange(ctx context.Context, code, state string, userId *string) (*models.OAuthUser, error) {
+	started, err := s.repo.Take(state)
+	if err != nil || time.Now().After(started.ExpiresAt) {
+		return nil, errors.New("login request expired, please try again")
+	}
+	if (started.UserID == nil) != (userId == nil) || (userId != nil && *started.UserID != *userId) {
+		return nil, errors.New("login request expired, please try again")
+	}
+	provider, ok := s.providers[started.Provider]
+	if !ok {
+		return nil, ErrUnknownOIDCProvider
+	}
+	oauth, err := provider.load(s.redirectURL)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := oauth.Exchange(ctx, code, oauth2.VerifierOption(started.CodeVerifier))
+	if err != nil {
+		return nil, errors.New("the provider rejected the login")
+	}
+
+	claims := map[string]any{}
+	if provider.verifier != nil {
+		rawIDToken, _ := token.Extra("id_token").(string)
+		if rawIDToken == "" {
+			return nil, errors.New("the provider returned no ID token")
+		}
+		idToken, err := provider.verifier.Verify(ctx, rawIDToken)
+		if err != nil {
+			return nil, errors.New("invalid ID token")
+		}
+		if idToken.Nonce != started.Nonce {
+			return nil, errors.New("invalid ID token")
+		}
+		if err := idToken.Claims(&claims); err != nil {
+			return nil, err
+		}
+	}
+	if _, hasEmail := claims["email"]; !hasEmail && provider.userInfo != nil {
+		info, err := provider.userInfo(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+		// The subject of the verified ID token wins over user info.
+		for k, v := range info {
+			if _, ok := claims[k]; !ok {
+				claims[k] = v
+			}
+		}
+	}
+	return oauthUserFromClaims(started.Provider, claims, provider.cfg.TrustEmail)
+}
+
+// oauthUserFromClaims maps standard OIDC claims, falling back to GitHub-style
+// fields (id, login) for plain OAuth 2.0 providers. Addresses the provider
+// doesn't mark as verified are refused; a missing email_verified claim is
+// accepted only from providers whose emails are trusted.
+func oauthUserFromClaims(provider string, claims map[string]any, trustEmail bool) (*models.OAuthUser, error) {
+	user := &models.OAuthUser{
+		ID:       firstClaim(claims, "sub", "id"),
+		Email:    firstClaim(claims, "email"),
+		Name:     firstClaim(claims, "name", "preferred_username", "login"),
+		Provider: provider,
+	}
+	if user.ID == "" {
+		return nil, errors.New("the provider returned no user ID")
+	}
+	if user.Email == "" {
+		return nil, errors.New("the provider returned no email address")
+	}
+	verified, ok := claims["email_verified"]
+	if (ok && claimString(verified) != "true") || (!ok && !trustEmail) {
+		return nil, errors.New("the email address is not verified by the provider")
+	}
+	if user.Name == "" {
+		user.Name = user.Email
+	}
+	return user, nil
+}
+
+// firstClaim returns the first of the claims that is set, as a string.
+func firstClaim(claims map[string]any, names ...string) string {
+	for _, name := range names {
+		if v := claimString(claims[name]); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// claimString formats string, boolean and numeric claims; numeric IDs such
+// as GitHub's come as json.Number from user info and float64 from tokens.
+func claimString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
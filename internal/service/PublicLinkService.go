@@ -0,0 +1,139 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"todolist/internal/models"
+	"todolist/internal/utils"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrPublicLinkNotFound = errors.New("link not found or expired")
+	ErrPublicLinkPassword = errors.New("password is missing or invalid")
+)
+
+type PublicLinkRepository interface {
+	Create(link *models.PublicLink) error
+	GetListByOwnerID(ownerId string) ([]models.PublicLink, error)
+	GetByTokenHash(tokenHash string) (*models.PublicLink, error)
+	GetTodos(link *models.PublicLink) ([]models.PublicTodo, error)
+	Delete(id, ownerId string) error
+}
+
+// PublicLinkService manages links that show selected todos to people
+// without an account.
+type PublicLinkService struct {
+	repo        PublicLinkRepository
+	todoService *TodoService
+	limiter     *AttemptLimiter
+}
+
+func NewPublicLinkService(repo PublicLinkRepository, todoService *TodoService, limiter *AttemptLimiter) *PublicLinkService {
+	return &PublicLinkService{repo: repo, todoService: todoService, limiter: limiter}
+}
+
+// CreateLink publishes the owner's own todos. The returned link carries the
+// token; it cannot be recovered later.
+func (s *PublicLinkService) CreateLink(ownerId, name string, todoIds []string, password string, expiresAt *time.Time) (*models.PublicLink, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("name is empty")
+	}
+	if len(todoIds) == 0 {
+		return nil, errors.New("select at least one todo")
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, errors.New("expiry is in the past")
+	}
+
+	link := &models.PublicLink{
+		Id:        uuid.New().String(),
+		OwnerID:   ownerId,
+		Name:      name,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+	for _, todoId := range todoIds {
+		todo, err := s.todoService.GetTodo(ownerId, todoId)
+		if err != nil || todo.UserID != ownerId || todo.SharedBy != "" {
+			return nil, errors.New("todo not found")
+		}
+		if !slices.Contains(link.TodoIDs, todo.Id) {
+			link.TodoIDs = append(link.TodoIDs, todo.Id)
+		}
+	}
+	if password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), 10)
+		if err != nil {
+			return nil, err
+		}
+		link.PasswordHash = string(hash)
+		link.HasPassword = true
+	}
+
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, err
+	}
+	link.TokenHash = utils.HashToken(token)
+	if err := s.repo.Create(link); err != nil {
+		return nil, err
+	}
+	link.Token = token
+	return link, nil
+}
+
+func (s *PublicLinkService) GetLinks(ownerId string) ([]models.PublicLink, error) {
+	return s.repo.GetListByOwnerID(ownerId)
+}
+
+func (s *PublicLinkService) RevokeLink(ownerId, id string) error {
+	if err := s.repo.Delete(id, ownerId); err != nil {
+		return errors.New("link not found")
+	}
+	return nil
+}
+
+// View resolves a token to the read-only list. password is checked only for
+// links that have one; wrong guesses are throttled per link and per client IP.
+func (s *PublicLinkService) View(token, password, ip string) (*models.PublicLinkView, error) {
+	link, err := s.repo.GetByTokenHash(utils.HashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPublicLinkNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if link.ExpiresAt != nil && time.Now().After(*link.ExpiresAt) {
+		return nil, ErrPublicLinkNotFound
+	}
+	if link.PasswordHash != "" {
+		if password == "" {
+			return nil, ErrPublicLinkPassword
+		}
+		linkKey, ipKey := publicLinkAttempts(link.Id), ipAttempts("public-link", ip)
+		if err := s.limiter.Check(linkKey, ipKey); err != nil {
+			return nil, err
+		}
+		if bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(password)) != nil {
+			if _, err := s.limiter.Fail(linkKey, ipKey); err != nil {
+				return nil, err
+			}
+			return nil, ErrPublicLinkPassword
+		}
+		if err := s.limiter.Reset(linkKey); err != nil {
+			return nil, err
+		}
+	}
+	todos, err := s.repo.GetTodos(link)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PublicLinkView{Name: link.Name, ExpiresAt: link.ExpiresAt, Todos: todos}, nil
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"errors"
+	"time"
+	"todolist/internal/models"
+	"todolist/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// sessionTouchInterval limits how often last_seen_at is written.
+const sessionTouchInterval = time.Minute
+
+type SessionRepository interface {
+	Create(session *models.Session) error
+	GetByID(id, userId string) (*models.Session, error)
+	GetListByUserID(userId string) ([]models.Session, error)
+	Touch(id, ip string) error
+	Delete(id, userId string) error
+	DeleteAllExcept(userId, keepId string) error
+}
+
+// SessionRevocationListener is told when a user is logged out everywhere,
+// so credentials that don't belong to a session can be revoked too.
+type SessionRevocationListener interface {
+	OnSessionsRevoked(userId string) error
+}
+
+// SessionService tracks logins per device. Every access token names its
+// session, so deleting a session revokes the token before it expires.
+type SessionService struct {
+	repo       SessionRepository
+	jwtManager *utils.JWTManager
+	listeners  []SessionRevocationListener
+}
+
+func NewSessionService(repo SessionRepository, jwtManager *utils.JWTManager) *SessionService {
+	return &SessionService{repo: repo, jwtManager: jwtManager}
+}
+
+func (s *SessionService) AddRevocationListener(l SessionRevocationListener) {
+	s.listeners = append(s.listeners, l)
+}
+
+func (s *SessionService) Start(userId string, client models.SessionClient) (*models.Session, error) {
+	session := &models.Session{
+		Id:         uuid.New().String(),
+		UserID:     userId,
+		Device:     utils.DeviceName(client.UserAgent),
+		IP:         client.IP,
+		UserAgent:  client.UserAgent,
+		CreatedAt:  time.Now(),
+		LastSeenAt: time.Now(),
+	}
+	if err := s.repo.Create(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Authenticate validates an access token and checks that its session is
+// still active, recording when and from where it was last used.
+func (s *SessionService) Authenticate(token, ip string) (*utils.Claims, error) {
+	claims, err := s.jwtManager.Validate(token)
+	if err != nil {
+		return nil, err
+	}
+	if claims.SessionID == "" {
+		return nil, errors.New("session expired")
+	}
+	session, err := s.repo.GetByID(claims.SessionID, claims.UserID)
+	if err != nil {
+		return nil, errors.New("session expired")
+	}
+	if time.Since(session.LastSeenAt) > sessionTouchInterval || (ip != "" && session.IP != ip) {
+		if err := s.repo.Touch(session.Id, ip); err != nil {
+			return nil, err
+		}
+	}
+	return claims, nil
+}
+
+func (s *SessionService) GetSessions(userId, currentId string) ([]models.Session, error) {
+	sessions, err := s.repo.GetListByUserID(userId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].Id == currentId
+	}
+	return sessions, nil
+}
+
+// StartedWithin reports whether the session began less than d ago, i.e. the
+// user has just logged in.
+func (s *SessionService) StartedWithin(userId, id string, d time.Duration) (bool, error) {
+	session, err := s.repo.GetByID(id, userId)
+	if err != nil {
+		return false, errors.New("session expired")
+	}
+	return time.Since(session.CreatedAt) < d, nil
+}
+
+func (s *SessionService) Revoke(userId, id string) error {
+	if err := s.repo.Delete(id, userId); err != nil {
+		return errors.New("session not found")
+	}
+	return nil
+}
+
+// RevokeOthers logs out every other device. Personal access tokens are not
+// sessions and keep working.
+func (s *SessionService) RevokeOthers(userId, currentId string) error {
+	return s.repo.DeleteAllExcept(userId, currentId)
+}
+
+// RevokeAll logs the user out everywhere, including the current device, and
+// tells listeners to revoke credentials that outlive sessions.
+func (s *SessionService) RevokeAll(userId string) error {
+	if err := s.repo.DeleteAllExcept(userId, ""); err != nil {
+		return err
+	}
+	return s.notifyRevoked(userId)
+}
+
+func (s *SessionService) notifyRevoked(userId string) error {
+	for _, l := range s.listeners {
+		if err := l.OnSessionsRevoked(userId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
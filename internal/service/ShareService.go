@@ -0,0 +1,136 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+	"todolist/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type ShareRepository interface {
+	Create(share *models.TodoShare) error
+	GetByID(id string) (*models.TodoShare, error)
+	GetListByOwnerID(ownerId string) ([]models.TodoShare, error)
+	GetListByGranteeID(granteeId string) ([]models.TodoShare, error)
+	UpdateRole(id, role string) error
+	Delete(id string) error
+}
+
+type ShareService struct {
+	repo        ShareRepository
+	userRepo    UserRepository
+	todoService *TodoService
+}
+
+func NewShareService(repo ShareRepository, userRepo UserRepository, todoService *TodoService) *ShareService {
+	return &ShareService{repo: repo, userRepo: userRepo, todoService: todoService}
+}
+
+func validateShareRole(role string) error {
+	if role != models.ShareRoleViewer && role != models.ShareRoleEditor {
+		return errors.New("role must be viewer or editor")
+	}
+	return nil
+}
+
+func (s *ShareService) findRecipient(recipient string) (*models.User, error) {
+	recipient = strings.TrimSpace(recipient)
+	var user *models.User
+	var err error
+	if strings.Contains(recipient, "@") {
+		user, err = s.userRepo.GetByEmail(recipient)
+	} else {
+		user, err = s.userRepo.GetByUsername(recipient)
+	}
+	if err != nil || !user.IsVerified {
+		return nil, errors.New("user not found")
+	}
+	return user, nil
+}
+
+// Share gives recipient (a username or email) access to one todo, or to the
+// owner's whole list when todoId is empty.
+func (s *ShareService) Share(ownerId, recipient, todoId, role string) (*models.TodoShare, error) {
+	if err := validateShareRole(role); err != nil {
+		return nil, err
+	}
+	grantee, err := s.findRecipient(recipient)
+	if err != nil {
+		return nil, err
+	}
+	if grantee.Id == ownerId {
+		return nil, errors.New("cannot share with yourself")
+	}
+
+	share := &models.TodoShare{
+		Id:        uuid.New().String(),
+		OwnerID:   ownerId,
+		GranteeID: grantee.Id,
+		Role:      role,
+		CreatedAt: time.Now(),
+	}
+	if todoId != "" {
+		todo, err := s.todoService.GetTodo(ownerId, todoId)
+		if err != nil || todo.UserID != ownerId || todo.SharedBy != "" {
+			return nil, errors.New("todo not found")
+		}
+		share.TodoID = &todoId
+	}
+	if err := s.repo.Create(share); err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return nil, errors.New("already shared with this user")
+		}
+		return nil, err
+	}
+	return s.repo.GetByID(share.Id)
+}
+
+func (s *ShareService) GetOutgoing(ownerId string) ([]models.TodoShare, error) {
+	return s.repo.GetListByOwnerID(ownerId)
+}
+
+func (s *ShareService) GetIncoming(granteeId string) ([]models.TodoShare, error) {
+	return s.repo.GetListByGranteeID(granteeId)
+}
+
+func (s *ShareService) getShare(id string) (*models.TodoShare, error) {
+	share, err := s.repo.GetByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("share not found")
+	}
+	return share, err
+}
+
+func (s *ShareService) UpdateRole(ownerId, id, role string) (*models.TodoShare, error) {
+	if err := validateShareRole(role); err != nil {
+		return nil, err
+	}
+	share, err := s.getShare(id)
+	if err != nil {
+		return nil, err
+	}
+	if share.OwnerID != ownerId {
+		return nil, errors.New("share not found")
+	}
+	if err := s.repo.UpdateRole(id, role); err != nil {
+		return nil, err
+	}
+	share.Role = role
+	return share, nil
+}
+
+// Revoke removes a share. The owner can revoke it and the recipient can
+// leave it.
+func (s *ShareService) Revoke(userId, id string) error {
+	share, err := s.getShare(id)
+	if err != nil {
+		return err
+	}
+	if share.OwnerID != userId && share.GranteeID != userId {
+		return errors.New("share not found")
+	}
+	return s.repo.Delete(id)
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"todolist/internal/models"
+
+	"github.com/google/uuid"
+)
+
+const templateMaxItems = 100
+
+var templateOffsetRe = regexp.MustCompile(`^([+-])?((?:\d+[wdhm])+)$`)
+var templateOffsetPartRe = regexp.MustCompile(`(\d+)([wdhm])`)
+
+type TemplateRepository interface {
+	Create(t *models.TodoTemplate) error
+	GetByID(id, userId string) (*models.TodoTemplate, error)
+	GetListByUserID(userId string) ([]models.TodoTemplate, error)
+	Update(t *models.TodoTemplate) error
+	Delete(id, userId string) error
+}
+
+type TemplateService struct {
+	repo        TemplateRepository
+	todoService *TodoService
+}
+
+func NewTemplateService(repo TemplateRepository, todoService *TodoService) *TemplateService {
+	return &TemplateService{repo: repo, todoService: todoService}
+}
+
+// templateOffset is a parsed relative deadline. Weeks and days are applied
+// with AddDate so that "+1d" keeps the wall-clock time across DST changes.
+type templateOffset struct {
+	days     int
+	duration time.Duration
+}
+
+// parseTemplateOffset accepts "+3d", "1w2d", "+4h30m", "-1d" or "0".
+func parseTemplateOffset(s string) (templateOffset, error) {
+	s = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), " ", "")
+	if s == "" || s == "0" || s == "+0" {
+		return templateOffset{}, nil
+	}
+	m := templateOffsetRe.FindStringSubmatch(s)
+	if m == nil {
+		return templateOffset{}, fmt.Errorf("invalid offset %q, expected e.g. +3d, +1w2d or +4h", s)
+	}
+	var off templateOffset
+	for _, part := range templateOffsetPartRe.FindAllStringSubmatch(m[2], -1) {
+		n, err := strconv.Atoi(part[1])
+		if err != nil || n > 3650 {
+			return templateOffset{}, fmt.Errorf("offset %q is too large", s)
+		}
+		switch part[2] {
+		case "w":
+			off.days += 7 * n
+		case "d":
+			off.days += n
+		case "h":
+			off.duration += time.Duration(n) * time.Hour
+		case "m":
+			off.duration += time.Duration(n) * time.Minute
+		}
+	}
+	if m[1] == "-" {
+		off.days, off.duration = -off.days, -off.duration
+	}
+	return off, nil
+}
+
+func (o templateOffset) from(start time.Time) time.Time {
+	return start.AddDate(0, 0, o.days).Add(o.duration)
+}
+
+func (s *TemplateService) prepareTemplate(t *models.TodoTemplate) error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(t.Items) == 0 {
+		return errors.New("template must contain at least one item")
+	}
+	if len(t.Items) > templateMaxItems {
+		return fmt.Errorf("template can contain at most %d items", templateMaxItems)
+	}
+	for i := range t.Items {
+		item := &t.Items[i]
+		if strings.TrimSpace(item.Title) == "" {
+			return fmt.Errorf("item %d: title is required", i+1)
+		}
+		if _, err := parseTemplateOffset(item.Offset); err != nil {
+			return fmt.Errorf("item %d: %w", i+1, err)
+		}
+		todo := models.Todo{Priority: item.Priority, Tags: item.Tags}
+		if err := validateTodoDetails(&todo); err != nil {
+			return fmt.Errorf("item %d: %w", i+1, err)
+		}
+		item.Tags = todo.Tags
+		item.Id = uuid.New().String()
+		item.TemplateID = t.Id
+		item.Position = i
+	}
+	return nil
+}
+
+func (s *TemplateService) CreateTemplate(userId string, t *models.TodoTemplate) (*models.TodoTemplate, error) {
+	t.Id = uuid.New().String()
+	t.UserID = userId
+	if err := s.prepareTemplate(t); err != nil {
+		return nil, err
+	}
+	t.CreatedAt = time.Now()
+	t.UpdatedAt = time.Now()
+	if err := s.repo.Create(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (s *TemplateService) GetTemplates(userId string) ([]models.TodoTemplate, error) {
+	return s.repo.GetListByUserID(userId)
+}
+
+func (s *TemplateService) GetTemplate(userId, id string) (*models.TodoTemplate, error) {
+	t, err := s.repo.GetByID(id, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("template not found")
+	}
+	return t, err
+}
+
+func (s *TemplateService) UpdateTemplate(userId, id string, input *models.TodoTemplate) (*models.TodoTemplate, error) {
+	t, err := s.GetTemplate(userId, id)
+	if err != nil {
+		return nil, err
+	}
+	t.Name = input.Name
+	t.Description = input.Description
+	t.Items = input.Items
+	if err := s.prepareTemplate(t); err != nil {
+		return nil, err
+	}
+	t.UpdatedAt = time.Now()
+	if err := s.repo.Update(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (s *TemplateService) DeleteTemplate(userId, id string) error {
+	return s.repo.Delete(id, userId)
+}
+
+// Instantiate creates one todo per template item with deadlines relative to
+// start. All todos are created in a single transaction. Items with a zero or
+// negative offset fall on or before start, so with start now they are
+// created already due or overdue rather than rejected.
+func (s *TemplateService) Instantiate(userId, id string, start time.Time) ([]*models.Todo, error) {
+	t, err := s.GetTemplate(userId, id)
+	if err != nil {
+		return nil, err
+	}
+	todos := make([]*models.Todo, 0, len(t.Items))
+	for _, item := range t.Items {
+		off, err := parseTemplateOffset(item.Offset)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, &models.Todo{
+			Title:       item.Title,
+			Description: item.Description,
+			Deadline:    off.from(start),
+			Priority:    item.Priority,
+			Tags:        append([]string{}, item.Tags...),
+		})
+	}
+	return s.todoService.CreateTodos(userId, todos)
+}
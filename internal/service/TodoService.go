@@ -2,71 +2,478 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
 	"time"
 	"todolist/internal/models"
+	"todolist/internal/quickadd"
 
 	"github.com/google/uuid"
 )
 
+// defaultDeadline is used for todos created without an explicit deadline,
+// e.g. from email or a quick-add string without a date.
+const defaultDeadline = 24 * time.Hour
+
+var todoPriorities = []string{"", quickadd.PriorityLow, quickadd.PriorityMedium, quickadd.PriorityHigh}
+
 type TodoRepository interface {
 	Create(todo *models.Todo) error
-	GetListByUserID(userId string) ([]models.Todo, error)
+	CreateMany(todos []*models.Todo) error
+	GetListByWorkspaceID(workspaceId string) ([]models.Todo, error)
+	GetPageByWorkspaceID(workspaceId string, afterDeadline time.Time, afterId string, limit int) ([]models.Todo, error)
+	GetByID(id, workspaceId string) (*models.Todo, error)
 	Update(todo *models.Todo) error
-	Delete(id string, userId string) error
+	Delete(id string, workspaceId string) error
+}
+
+// WorkspaceResolver returns the workspace the user is currently working in.
+// All todo queries are scoped to it.
+type WorkspaceResolver interface {
+	GetActiveID(userId string) (string, error)
+	GetMemberIDs(workspaceId string) ([]string, error)
+}
+
+// SharedTodoRepository looks up todos other users have shared with a user.
+type SharedTodoRepository interface {
+	GetSharedTodos(granteeId string) ([]models.Todo, error)
+	GetSharedTodosPage(granteeId string, afterDeadline time.Time, afterId string, limit int) ([]models.Todo, error)
+	GetSharedTodo(todoId, granteeId string) (*models.Todo, error)
+	GetGranteeIDs(todoId string) ([]string, error)
+}
+
+// TodoEventListener receives every change made through TodoService.
+// Implementations must not block: events are delivered synchronously.
+type TodoEventListener interface {
+	HandleTodoEvent(event models.TodoEvent)
 }
 
 type TodoService struct {
-	repo TodoRepository
+	repo       TodoRepository
+	shares     SharedTodoRepository
+	workspaces WorkspaceResolver
+	listeners  []TodoEventListener
 }
 
-func NewTodoService(repo TodoRepository) *TodoService {
-	return &TodoService{repo: repo}
+func NewTodoService(repo TodoRepository, shares SharedTodoRepository, workspaces WorkspaceResolver) *TodoService {
+	return &TodoService{repo: repo, shares: shares, workspaces: workspaces}
 }
 
-func (s *TodoService) CreateTodo(userId, title, description string, deadline time.Time) (*models.Todo, error) {
-	if userId == "" || title == "" {
-		return nil, errors.New("userId or title is empty")
+func (s *TodoService) AddListener(listener TodoEventListener) {
+	s.listeners = append(s.listeners, listener)
+}
+
+// recipients returns everyone who sees the todo: its creator, the members of
+// its workspace and the users it is shared with. Shares go away with a
+// deleted todo, so collect them before deleting it.
+func (s *TodoService) recipients(todo *models.Todo) []string {
+	recipients := []string{todo.UserID}
+	members, err := s.workspaces.GetMemberIDs(todo.WorkspaceID)
+	if err != nil {
+		slog.Error("Failed to load todo event recipients", "error", err, "todo_id", todo.Id)
 	}
-	if deadline.Before(time.Now()) {
-		return nil, errors.New("deadline is before now")
+	grantees, err := s.shares.GetGranteeIDs(todo.Id)
+	if err != nil {
+		slog.Error("Failed to load todo event recipients", "error", err, "todo_id", todo.Id)
+	}
+	for _, id := range append(members, grantees...) {
+		if !slices.Contains(recipients, id) {
+			recipients = append(recipients, id)
+		}
 	}
-	newTodo := &models.Todo{
-		Id:          uuid.New().String(),
+	return recipients
+}
+
+func (s *TodoService) publish(eventType models.TodoEventType, todo *models.Todo, recipients []string) {
+	event := models.TodoEvent{
+		Type:       eventType,
+		UserID:     todo.UserID,
+		Recipients: recipients,
+		Todo:       *todo,
+		OccurredAt: time.Now(),
+	}
+	for _, listener := range s.listeners {
+		listener.HandleTodoEvent(event)
+	}
+}
+
+func validateTodoDetails(todo *models.Todo) error {
+	if !slices.Contains(todoPriorities, todo.Priority) {
+		return errors.New("priority must be low, medium or high")
+	}
+	if todo.Tags == nil {
+		todo.Tags = []string{}
+	}
+	return nil
+}
+
+func (s *TodoService) CreateTodo(userId, title, description string, deadline time.Time) (*models.Todo, error) {
+	return s.createTodo(&models.Todo{
 		UserID:      userId,
 		Title:       title,
 		Description: description,
-		Completed:   false,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
 		Deadline:    deadline,
+	})
+}
+
+func prepareNewTodo(newTodo *models.Todo) error {
+	if newTodo.UserID == "" || newTodo.Title == "" {
+		return errors.New("userId or title is empty")
 	}
+	if err := validateTodoDetails(newTodo); err != nil {
+		return err
+	}
+	newTodo.Id = uuid.New().String()
+	newTodo.Completed = false
+	newTodo.CreatedAt = time.Now()
+	newTodo.UpdatedAt = time.Now()
+	return nil
+}
 
-	err := s.repo.Create(newTodo)
+func (s *TodoService) createTodo(newTodo *models.Todo) (*models.Todo, error) {
+	if newTodo.Deadline.Before(time.Now()) {
+		return nil, errors.New("deadline is before now")
+	}
+	if err := prepareNewTodo(newTodo); err != nil {
+		return nil, err
+	}
+	workspaceId, err := s.workspaces.GetActiveID(newTodo.UserID)
 	if err != nil {
 		return nil, err
 	}
+	newTodo.WorkspaceID = workspaceId
+	err = s.repo.Create(newTodo)
+	if err != nil {
+		return nil, err
+	}
+	s.publish(models.TodoCreated, newTodo, s.recipients(newTodo))
 	return newTodo, nil
 }
 
+// CreateTodos validates and creates several todos in one transaction.
+// Events are published only after the whole batch is stored. Unlike
+// CreateTodo, deadlines may already have passed: a checklist scheduled
+// around a start date can have items that are overdue when it is created.
+func (s *TodoService) CreateTodos(userId string, todos []*models.Todo) ([]*models.Todo, error) {
+	workspaceId, err := s.workspaces.GetActiveID(userId)
+	if err != nil {
+		return nil, err
+	}
+	for i, todo := range todos {
+		todo.UserID = userId
+		if err := prepareNewTodo(todo); err != nil {
+			return nil, fmt.Errorf("todo %d: %w", i+1, err)
+		}
+		todo.WorkspaceID = workspaceId
+	}
+	if err := s.repo.CreateMany(todos); err != nil {
+		return nil, err
+	}
+	for _, todo := range todos {
+		s.publish(models.TodoCreated, todo, s.recipients(todo))
+	}
+	return todos, nil
+}
+
+// ParseQuickAdd previews how a quick-add string will be interpreted.
+func (s *TodoService) ParseQuickAdd(text string, loc *time.Location) quickadd.Result {
+	return quickadd.Parse(text, time.Now().In(loc))
+}
+
+// QuickAddTodo parses a quick-add string and creates the resulting todo.
+func (s *TodoService) QuickAddTodo(userId, text string, loc *time.Location) (*models.Todo, error) {
+	parsed := s.ParseQuickAdd(text, loc)
+	if parsed.Title == "" {
+		return nil, errors.New("title is empty")
+	}
+	deadline := time.Now().Add(defaultDeadline)
+	if parsed.Deadline != nil {
+		deadline = *parsed.Deadline
+	}
+	return s.createTodo(&models.Todo{
+		UserID:     userId,
+		Title:      parsed.Title,
+		Deadline:   deadline,
+		Priority:   parsed.Priority,
+		Tags:       parsed.Tags,
+		Recurrence: parsed.Recurrence,
+	})
+}
+
+// accessibleTodo returns a todo from the user's active workspace or one that
+// was shared with them. Shared todos carry SharedBy and Permission.
+func (s *TodoService) accessibleTodo(userId, todoId string) (*models.Todo, error) {
+	workspaceId, err := s.workspaces.GetActiveID(userId)
+	if err != nil {
+		return nil, err
+	}
+	todo, err := s.repo.GetByID(todoId, workspaceId)
+	if err == nil {
+		return todo, nil
+	}
+	todo, err = s.shares.GetSharedTodo(todoId, userId)
+	if err != nil {
+		return nil, errors.New("todo not found")
+	}
+	return todo, nil
+}
+
+// UpdateTodo saves changes made by a workspace member or by a user the todo is
+// shared with as editor.
 func (s *TodoService) UpdateTodo(userId string, newTodo *models.Todo) error {
-	if newTodo.UserID != userId {
+	oldTodo, err := s.accessibleTodo(userId, newTodo.Id)
+	if err != nil {
+		return err
+	}
+	if oldTodo.Permission == models.ShareRoleViewer {
+		return errors.New("you have read-only access to this todo")
+	}
+	if newTodo.UserID != oldTodo.UserID {
 		return errors.New("wrong userId")
 	}
-	err := s.repo.Update(newTodo)
+	if err := validateTodoDetails(newTodo); err != nil {
+		return err
+	}
+	newTodo.WorkspaceID = oldTodo.WorkspaceID
+	err = s.repo.Update(newTodo)
 	if err != nil {
 		return err
 	}
+
+	newTodo.CreatedAt = oldTodo.CreatedAt
+	newTodo.UpdatedAt = time.Now()
+	newTodo.SharedBy = oldTodo.SharedBy
+	newTodo.Permission = oldTodo.Permission
+	recipients := s.recipients(newTodo)
+	s.publish(models.TodoUpdated, newTodo, recipients)
+	if newTodo.Completed && !oldTodo.Completed {
+		s.publish(models.TodoCompleted, newTodo, recipients)
+	}
 	return nil
 }
 
+// DeleteTodo is allowed only inside the active workspace, never through a share.
 func (s *TodoService) DeleteTodo(userId string, todoId string) error {
-	err := s.repo.Delete(todoId, userId)
+	workspaceId, err := s.workspaces.GetActiveID(userId)
 	if err != nil {
 		return err
 	}
+	todo, err := s.repo.GetByID(todoId, workspaceId)
+	if err != nil {
+		return errors.New("todo not found")
+	}
+	recipients := s.recipients(todo)
+	err = s.repo.Delete(todoId, workspaceId)
+	if err != nil {
+		return err
+	}
+	s.publish(models.TodoDeleted, todo, recipients)
 	return nil
 }
 
+func (s *TodoService) workspaceTodos(userId string) ([]models.Todo, error) {
+	workspaceId, err := s.workspaces.GetActiveID(userId)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetListByWorkspaceID(workspaceId)
+}
+
+// GetTodosByUserID returns the todos of the user's active workspace followed
+// by todos shared with them that are not already in it.
 func (s *TodoService) GetTodosByUserID(userId string) ([]models.Todo, error) {
-	return s.repo.GetListByUserID(userId)
+	todos, err := s.workspaceTodos(userId)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := s.shares.GetSharedTodos(userId)
+	if err != nil {
+		return nil, err
+	}
+	for _, todo := range shared {
+		if !slices.ContainsFunc(todos, func(t models.Todo) bool { return t.Id == todo.Id }) {
+			todos = append(todos, todo)
+		}
+	}
+	return todos, nil
+}
+
+func (s *TodoService) GetTodo(userId, todoId string) (*models.Todo, error) {
+	return s.accessibleTodo(userId, todoId)
+}
+
+// TodoFilter narrows a todo list. Zero values match everything.
+type TodoFilter struct {
+	Completed *bool
+	Overdue   bool
+	Tag       string
+	Priority  string
+	Search    string
+	DueBefore *time.Time
+	DueAfter  *time.Time
+}
+
+func (f TodoFilter) matches(todo models.Todo, now time.Time) bool {
+	if f.Completed != nil && todo.Completed != *f.Completed {
+		return false
+	}
+	if f.Overdue && (todo.Completed || !todo.Deadline.Before(now)) {
+		return false
+	}
+	if f.Tag != "" && !slices.Contains(todo.Tags, strings.ToLower(f.Tag)) {
+		return false
+	}
+	if f.Priority != "" && todo.Priority != f.Priority {
+		return false
+	}
+	if f.Search != "" {
+		needle := strings.ToLower(f.Search)
+		if !strings.Contains(strings.ToLower(todo.Title), needle) && !strings.Contains(strings.ToLower(todo.Description), needle) {
+			return false
+		}
+	}
+	if f.DueBefore != nil && !todo.Deadline.Before(*f.DueBefore) {
+		return false
+	}
+	if f.DueAfter != nil && !todo.Deadline.After(*f.DueAfter) {
+		return false
+	}
+	return true
+}
+
+// FindTodos returns the user's own and shared todos that match filter,
+// ordered by deadline.
+func (s *TodoService) FindTodos(userId string, filter TodoFilter) ([]models.Todo, error) {
+	todos, err := s.GetTodosByUserID(userId)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	result := make([]models.Todo, 0, len(todos))
+	for _, todo := range todos {
+		if filter.matches(todo, now) {
+			result = append(result, todo)
+		}
+	}
+	slices.SortStableFunc(result, func(a, b models.Todo) int {
+		return a.Deadline.Compare(b.Deadline)
+	})
+	return result, nil
+}
+
+// todoPageSize is how many todos EachTodo loads at a time.
+const todoPageSize = 100
+
+// todoPager walks one todo source in (deadline, id) order, a page at a time.
+type todoPager struct {
+	fetch func(afterDeadline time.Time, afterId string) ([]models.Todo, error)
+	page  []models.Todo
+	last  *models.Todo
+	done  bool
+}
+
+// peek returns the next todo without consuming it, or nil at the end.
+func (p *todoPager) peek() (*models.Todo, error) {
+	if len(p.page) == 0 && !p.done {
+		var afterDeadline time.Time
+		afterId := ""
+		if p.last != nil {
+			afterDeadline, afterId = p.last.Deadline, p.last.Id
+		}
+		page, err := p.fetch(afterDeadline, afterId)
+		if err != nil {
+			return nil, err
+		}
+		p.page = page
+		p.done = len(page) < todoPageSize
+	}
+	if len(p.page) == 0 {
+		return nil, nil
+	}
+	return &p.page[0], nil
+}
+
+func (p *todoPager) next() models.Todo {
+	todo := p.page[0]
+	p.last = &todo
+	p.page = p.page[1:]
+	return todo
+}
+
+// EachTodo calls fn for the todos FindTodos returns, ordered by deadline,
+// loading them from the database a page at a time instead of all at once.
+func (s *TodoService) EachTodo(userId string, filter TodoFilter, fn func(models.Todo) error) error {
+	workspaceId, err := s.workspaces.GetActiveID(userId)
+	if err != nil {
+		return err
+	}
+	own := &todoPager{fetch: func(afterDeadline time.Time, afterId string) ([]models.Todo, error) {
+		return s.repo.GetPageByWorkspaceID(workspaceId, afterDeadline, afterId, todoPageSize)
+	}}
+	shared := &todoPager{fetch: func(afterDeadline time.Time, afterId string) ([]models.Todo, error) {
+		return s.shares.GetSharedTodosPage(userId, afterDeadline, afterId, todoPageSize)
+	}}
+
+	now := time.Now()
+	for {
+		a, err := own.peek()
+		if err != nil {
+			return err
+		}
+		b, err := shared.peek()
+		if err != nil {
+			return err
+		}
+		var todo models.Todo
+		switch {
+		case a == nil && b == nil:
+			return nil
+		case b == nil || (a != nil && (a.Deadline.Before(b.Deadline) || a.Deadline.Equal(b.Deadline) && a.Id <= b.Id)):
+			todo = own.next()
+		default:
+			todo = shared.next()
+			if todo.WorkspaceID == workspaceId {
+				continue
+			}
+		}
+		if !filter.matches(todo, now) {
+			continue
+		}
+		if err := fn(todo); err != nil {
+			return err
+		}
+	}
+}
+
+type TodoStats struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Pending   int `json:"pending"`
+	Overdue   int `json:"overdue"`
+	DueToday  int `json:"due_today"`
+}
+
+func (s *TodoService) GetStats(userId string) (*TodoStats, error) {
+	todos, err := s.workspaceTodos(userId)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	endOfDay := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+	stats := &TodoStats{Total: len(todos)}
+	for _, todo := range todos {
+		if todo.Completed {
+			stats.Completed++
+			continue
+		}
+		stats.Pending++
+		if todo.Deadline.Before(now) {
+			stats.Overdue++
+		} else if todo.Deadline.Before(endOfDay) {
+			stats.DueToday++
+		}
+	}
+	return stats, nil
 }
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"todolist/internal/models"
+	"todolist/internal/utils"
+)
+
+// totpIssuer is the account name prefix shown in authenticator apps.
+const totpIssuer = "ToDoList"
+
+// recoveryCodeCount is how many one-time recovery codes are issued.
+const recoveryCodeCount = 10
+
+var ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+
+type TwoFactorUserRepository interface {
+	GetByID(id string) (*models.User, error)
+	SetTOTP(id, secret string, enabled bool) error
+	UseTOTPStep(id string, step int64) (bool, error)
+}
+
+type RecoveryCodeRepository interface {
+	Replace(userId string, codeHashes []string) error
+	Use(userId, codeHash string) (bool, error)
+	DeleteAll(userId string) error
+}
+
+// TwoFactorService manages TOTP enrollment and the second login step. A
+// login that passed the password check gets an MFA challenge token, which is
+// exchanged for a session here together with a TOTP or recovery code.
+type TwoFactorService struct {
+	users       TwoFactorUserRepository
+	recovery    RecoveryCodeRepository
+	userService *UserService
+	limiter     *AttemptLimiter
+	jwtManager  *utils.JWTManager
+}
+
+func NewTwoFactorService(users TwoFactorUserRepository, recovery RecoveryCodeRepository, userService *UserService, limiter *AttemptLimiter, jwtManager *utils.JWTManager) *TwoFactorService {
+	return &TwoFactorService{users: users, recovery: recovery, userService: userService, limiter: limiter, jwtManager: jwtManager}
+}
+
+// Setup creates a new secret for the user. Two-factor authentication stays
+// off until Confirm receives a code generated from it.
+func (s *TwoFactorService) Setup(userId string) (*models.TOTPSetup, error) {
+	user, err := s.users.GetByID(userId)
+	if err != nil {
+		return nil, errors.New("invalid user")
+	}
+	if user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.users.SetTOTP(user.Id, secret, false); err != nil {
+		return nil, err
+	}
+	return &models.TOTPSetup{
+		Secret: secret,
+		URI:    utils.TOTPURI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm enables two-factor authentication and returns the recovery codes.
+// They are only stored hashed, so this is the only time they are shown.
+func (s *TwoFactorService) Confirm(userId, code string) ([]string, error) {
+	user, err := s.users.GetByID(userId)
+	if err != nil {
+		return nil, errors.New("invalid user")
+	}
+	if user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+	if user.TOTPSecret == "" {
+		return nil, errors.New("two-factor setup was not started")
+	}
+	step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.recovery.Replace(user.Id, hashes); err != nil {
+		return nil, err
+	}
+	if err := s.users.SetTOTP(user.Id, user.TOTPSecret, true); err != nil {
+		return nil, err
+	}
+	if _, err := s.users.UseTOTPStep(user.Id, step); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable turns two-factor authentication off. The user re-authenticates
+// like for other sensitive changes and gives a current code.
+func (s *TwoFactorService) Disable(userId, sessionId, password, code string, client models.SessionClient) error {
+	user, err := s.users.GetByID(userId)
+	if err != nil {
+		return errors.New("invalid user")
+	}
+	if !user.TOTPEnabled {
+		return errors.New("two-factor authentication is not enabled")
+	}
+	if err := s.userService.Reauthenticate(user, sessionId, password, client); err != nil {
+		return err
+	}
+	if err := s.checkCode(user, code, AttemptKey{}); err != nil {
+		return err
+	}
+	if err := s.users.SetTOTP(user.Id, "", false); err != nil {
+		return err
+	}
+	return s.recovery.DeleteAll(user.Id)
+}
+
+// VerifyLogin completes a login that was answered with an MFA challenge.
+func (s *TwoFactorService) VerifyLogin(mfaToken, code string, client models.SessionClient) (*LoginResponse, error) {
+	claims, err := s.jwtManager.ValidateMFAChallenge(mfaToken)
+	if err != nil {
+		return nil, errors.New("login expired, please log in again")
+	}
+	user, err := s.users.GetByID(claims.UserID)
+	if err != nil || !user.TOTPEnabled {
+		return nil, errors.New("login expired, please log in again")
+	}
+	if err := s.checkCode(user, code, ipAttempts("2fa", client.IP)); err != nil {
+		return nil, err
+	}
+	return s.userService.StartSession(user, client)
+}
+
+// checkCode accepts either a TOTP code or an unused recovery code, counting
+// failures for the account and ipKey (which may be empty).
+func (s *TwoFactorService) checkCode(user *models.User, code string, ipKey AttemptKey) error {
+	accountKey := accountAttempts("2fa", user.Id)
+	if err := s.limiter.Check(accountKey, ipKey); err != nil {
+		return err
+	}
+	if err := s.matchCode(user, code); err != nil {
+		if !errors.Is(err, ErrInvalidTwoFactorCode) {
+			return err
+		}
+		if _, failErr := s.limiter.Fail(accountKey, ipKey); failErr != nil {
+			return failErr
+		}
+		return err
+	}
+	return s.limiter.Reset(accountKey)
+}
+
+// matchCode checks a TOTP or recovery code. Each TOTP time step is accepted
+// only once, so an observed code cannot be replayed.
+func (s *TwoFactorService) matchCode(user *models.User, code string) error {
+	code = normalizeRecoveryCode(code)
+	if step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now()); ok {
+		fresh, err := s.users.UseTOTPStep(user.Id, step)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+	used, err := s.recovery.Use(user.Id, utils.HashToken(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// generateRecoveryCodes returns codes formatted for display ("xxxxx-xxxxx")
+// and the hashes to store.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		raw, err := utils.GenerateSecureToken(5)
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, utils.HashToken(raw))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode drops the separators users may type or paste.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
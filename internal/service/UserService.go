@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
+	"net/mail"
 	"strings"
 	"time"
 	"todolist/internal/models"
@@ -16,25 +18,111 @@ type UserRepository interface {
 	GetByID(id string) (*models.User, error)
 	GetByEmail(email string) (*models.User, error)
 	GetByUsername(username string) (*models.User, error)
-	GetByOAuth(provider, oauthID string) (*models.User, error)
-	CreateWithDefaultTodo(u *models.User, todo *models.Todo) error
+	CreateWithPersonalWorkspace(u *models.User, ws *models.Workspace, todo *models.Todo, identity *models.UserIdentity) error
 	Update(u *models.User) error
 	Delete(id string) error
 }
 
+type RefreshTokenRepository interface {
+	Create(t *models.RefreshToken) error
+	GetByHash(tokenHash string) (*models.RefreshToken, error)
+	MarkUsed(id string) (bool, error)
+}
+
+type PasswordResetRepository interface {
+	Create(t *models.PasswordResetToken) error
+	GetByHash(tokenHash string) (*models.PasswordResetToken, error)
+	MarkUsed(id string) (bool, error)
+}
+
+type VerificationTokenRepository interface {
+	Create(t *models.VerificationToken) error
+	GetPending(userId, purpose string) (*models.VerificationToken, error)
+	MarkUsed(id string) (bool, error)
+	Delete(userId, purpose string) error
+}
+
+// verificationPurpose describes one flow confirmed by an emailed code: how
+// long the code is valid and what to answer for a wrong or missing one.
+type verificationPurpose struct {
+	ttl     time.Duration
+	invalid string
+	missing string
+}
+
+var verificationPurposes = map[string]verificationPurpose{
+	models.VerificationRegister:      {10 * time.Minute, "invalid verification code or email", "invalid verification code or email"},
+	models.VerificationEmailChange:   {time.Hour, "invalid verification code", "no pending email update"},
+	models.VerificationAccountDelete: {10 * time.Minute, "invalid verification code", "no pending deletion request"},
+}
+
+// reauthWindow is how recent a login must be to confirm a sensitive change
+// on an account without a password.
+const reauthWindow = 10 * time.Minute
+
+// ErrReauthRequired asks the user to log in again before a sensitive change.
+var ErrReauthRequired = errors.New("please log in again to confirm this change")
+
+// ErrInvalidPassword rejects the password given to confirm a sensitive change.
+var ErrInvalidPassword = errors.New("invalid password")
+
+// ErrOAuthEmailTaken refuses a provider login whose email belongs to an
+// account the provider identity isn't linked to.
+var ErrOAuthEmailTaken = errors.New("an account with this email already exists: log in to it and link this provider from your account settings")
+
+// passwordResetTTL must match the validity stated in the reset email.
+const passwordResetTTL = time.Hour
+
+// UserDeletionHook is called before an account is deleted. The returned
+// function runs once the deletion succeeded, e.g. to remove files kept
+// outside the database. Hooks also run when the deletion is requested, so an
+// error refuses it before a code is sent; the function is dropped then.
+type UserDeletionHook interface {
+	BeforeUserDelete(userId string) (func(), error)
+}
+
 type UserService struct {
-	repo       UserRepository
-	jwtManager *utils.JWTManager
+	repo          UserRepository
+	refreshRepo   RefreshTokenRepository
+	resetRepo     PasswordResetRepository
+	tokens        VerificationTokenRepository
+	identities    UserIdentityRepository
+	sessions      *SessionService
+	limiter       *AttemptLimiter
+	jwtManager    *utils.JWTManager
+	refreshTTL    time.Duration
+	deletionHooks []UserDeletionHook
 }
 
+// LoginResponse carries a short-lived access token (Token) and a refresh
+// token that can be exchanged once for a new pair at /auth/refresh. When the
+// account has two-factor authentication, login instead answers with
+// MFARequired and an MFAToken to pass to /auth/2fa/verify with the code.
 type LoginResponse struct {
-	Message string       `json:"message"`
-	User    *models.User `json:"user"`
-	Token   string       `json:"token"`
+	Message      string       `json:"message"`
+	User         *models.User `json:"user"`
+	Token        string       `json:"token"`
+	RefreshToken string       `json:"refresh_token,omitempty"`
+	ExpiresIn    int          `json:"expires_in,omitempty"`
+	MFARequired  bool         `json:"mfa_required,omitempty"`
+	MFAToken     string       `json:"mfa_token,omitempty"`
 }
 
-func NewUserService(repo UserRepository, jwtManager *utils.JWTManager) *UserService {
-	return &UserService{repo: repo, jwtManager: jwtManager}
+func NewUserService(repo UserRepository, refreshRepo RefreshTokenRepository, resetRepo PasswordResetRepository, tokens VerificationTokenRepository, identities UserIdentityRepository, sessions *SessionService, limiter *AttemptLimiter, jwtManager *utils.JWTManager, refreshTTL time.Duration) *UserService {
+	return &UserService{repo: repo, refreshRepo: refreshRepo, resetRepo: resetRepo, tokens: tokens, identities: identities, sessions: sessions, limiter: limiter, jwtManager: jwtManager, refreshTTL: refreshTTL}
+}
+
+func (s *UserService) AddDeletionHook(hook UserDeletionHook) {
+	s.deletionHooks = append(s.deletionHooks, hook)
+}
+
+func newPersonalWorkspace(userId string) *models.Workspace {
+	return &models.Workspace{
+		Id:              uuid.New().String(),
+		Name:            "Personal",
+		PersonalOwnerID: &userId,
+		CreatedAt:       time.Now(),
+	}
 }
 
 func (s *UserService) RegisterUser(username, email, password string) (*LoginResponse, error) {
@@ -43,23 +131,18 @@ func (s *UserService) RegisterUser(username, email, password string) (*LoginResp
 		return nil, err
 	}
 
-	code, err := utils.GenerateVerificationCode()
-	if err != nil {
-		return nil, err
-	}
-	expires := time.Now().Add(10 * time.Minute)
 	user := &models.User{
-		Id:                      uuid.New().String(),
-		Username:                username,
-		Email:                   email,
-		PasswordHash:            string(hashedPassword),
-		VerificationCode:        code,
-		VerificationCodeExpires: expires,
+		Id:           uuid.New().String(),
+		Username:     username,
+		Email:        email,
+		PasswordHash: string(hashedPassword),
 	}
 
+	workspace := newPersonalWorkspace(user.Id)
 	defaultTodo := &models.Todo{
 		Id:          uuid.New().String(),
 		UserID:      user.Id,
+		WorkspaceID: workspace.Id,
 		Title:       "Welcome to ToDoList!",
 		Description: "This is your first task. Explore the app and get things done!",
 		Completed:   false,
@@ -68,7 +151,7 @@ func (s *UserService) RegisterUser(username, email, password string) (*LoginResp
 		Deadline:    time.Now().Add(24 * time.Hour),
 	}
 
-	err = s.repo.CreateWithDefaultTodo(user, defaultTodo)
+	err = s.repo.CreateWithPersonalWorkspace(user, workspace, defaultTodo, nil)
 	if err != nil {
 		if strings.Contains(err.Error(), "unique constraint") {
 			return nil, errors.New("username or email already exists")
@@ -76,47 +159,68 @@ func (s *UserService) RegisterUser(username, email, password string) (*LoginResp
 		return nil, err
 	}
 
-	go utils.SendVerificationEmail(user.Email, code)
+	if err := s.issueVerificationCode(user, models.VerificationRegister, user.Email); err != nil {
+		return nil, err
+	}
 
 	return &LoginResponse{Message: "User registered successfully. Check email for verification", Token: ""}, nil
 }
 
-func (s *UserService) LoginUser(userdata, password string) (*LoginResponse, error) {
+func (s *UserService) LoginUser(userdata, password string, client models.SessionClient) (*LoginResponse, error) {
 	user := &models.User{}
 	var err error
 
+	ipKey := ipAttempts("login", client.IP)
+	if err := s.limiter.Check(ipKey); err != nil {
+		return nil, err
+	}
+
 	hasAt := strings.Contains(userdata, "@")
 	if hasAt {
 		user, err = s.repo.GetByEmail(userdata)
-		if err != nil {
-			return nil, err
-		}
 	} else {
 		user, err = s.repo.GetByUsername(userdata)
-		if err != nil {
-			return nil, err
+	}
+	if err != nil {
+		if _, failErr := s.limiter.Fail(ipKey); failErr != nil {
+			return nil, failErr
 		}
+		return nil, err
 	}
 
+	accountKey := accountAttempts("login", user.Id)
+	if err := s.limiter.Check(accountKey); err != nil {
+		return nil, err
+	}
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
 	if err != nil {
+		if _, failErr := s.limiter.Fail(accountKey, ipKey); failErr != nil {
+			return nil, failErr
+		}
 		return nil, errors.New("invalid credentials")
 	}
+	if err := s.limiter.Reset(accountKey); err != nil {
+		return nil, err
+	}
 
 	if !user.IsVerified {
 		return nil, errors.New("user is not verified")
 	}
 
-	token, err := s.jwtManager.Generate(user.Id)
-	if err != nil {
+	return s.completeLogin(user, client)
+}
+
+func (s *UserService) VerifyEmail(email, code string, client models.SessionClient) (*LoginResponse, error) {
+	ipKey := ipAttempts("code", client.IP)
+	if err := s.limiter.Check(ipKey); err != nil {
 		return nil, err
 	}
-	return &LoginResponse{User: user, Token: token}, nil
-}
 
-func (s *UserService) VerifyEmail(email, code string) (*LoginResponse, error) {
 	user, err := s.repo.GetByEmail(email)
 	if err != nil {
+		if _, failErr := s.limiter.Fail(ipKey); failErr != nil {
+			return nil, failErr
+		}
 		return nil, errors.New("invalid verification code or email")
 	}
 
@@ -124,81 +228,196 @@ func (s *UserService) VerifyEmail(email, code string) (*LoginResponse, error) {
 		return nil, errors.New("email already verified")
 	}
 
-	if user.VerificationCode != code {
-		return nil, errors.New("invalid verification code or email")
-	}
-
-	if time.Now().After(user.VerificationCodeExpires) {
-		return nil, errors.New("verification code expired")
+	if _, err := s.consumeVerificationCode(user, models.VerificationRegister, code, ipKey); err != nil {
+		return nil, err
 	}
 
-	user.VerificationCode = ""
 	user.IsVerified = true
-	user.VerificationCodeExpires = time.Time{}
 	err = s.repo.Update(user)
 	if err != nil {
 		return nil, errors.New("failed to update user")
 	}
 
-	token, err := s.jwtManager.Generate(user.Id)
+	return s.StartSession(user, client)
+}
+
+// LoginWithOAuth logs in the account linked to the provider identity. An
+// unknown identity gets a new account, unless its email already belongs to
+// one: the owner has to log in and link the provider from settings, so a
+// provider account can never take over an existing user.
+func (s *UserService) LoginWithOAuth(ctx context.Context, data models.OAuthUser, client models.SessionClient) (*LoginResponse, error) {
+	identity, err := s.identities.GetByProviderSubject(data.Provider, data.ID)
+	if err == nil {
+		user, err := s.repo.GetByID(identity.UserID)
+		if err != nil {
+			return nil, errors.New("invalid user")
+		}
+		if err := s.identities.Touch(identity.Id); err != nil {
+			return nil, err
+		}
+		return s.completeLogin(user, client)
+	}
+
+	if _, err := s.repo.GetByEmail(data.Email); err == nil {
+		return nil, ErrOAuthEmailTaken
+	}
+	identity = &models.UserIdentity{
+		Id:        uuid.New().String(),
+		Provider:  data.Provider,
+		Subject:   data.ID,
+		Email:     data.Email,
+		CreatedAt: time.Now(),
+	}
+	user := &models.User{
+		Id:         uuid.New().String(),
+		Email:      data.Email,
+		Username:   data.Name,
+		IsVerified: true,
+	}
+	identity.UserID = user.Id
+	workspace := newPersonalWorkspace(user.Id)
+	defaultTodo := &models.Todo{
+		Id:          uuid.New().String(),
+		UserID:      user.Id,
+		WorkspaceID: workspace.Id,
+		Title:       "Welcome to ToDoList!",
+		Description: "This is your first task. Explore the app and get things done!",
+		Completed:   false,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+		Deadline:    time.Now().Add(24 * time.Hour),
+	}
+	err = s.repo.CreateWithPersonalWorkspace(user, workspace, defaultTodo, identity)
+	if err != nil {
+		if strings.Contains(err.Error(), "unique constraint") {
+			return nil, errors.New("username or email already exists")
+		}
+		return nil, err
+	}
+	return s.completeLogin(user, client)
+}
+
+// Reauthenticate confirms a sensitive change with the password, or by a
+// login within reauthWindow on accounts without one. Wrong passwords count
+// against the same limits as logins.
+func (s *UserService) Reauthenticate(user *models.User, sessionId, password string, client models.SessionClient) error {
+	if user.PasswordHash == "" {
+		recent, err := s.sessions.StartedWithin(user.Id, sessionId, reauthWindow)
+		if err != nil {
+			return err
+		}
+		if !recent {
+			return ErrReauthRequired
+		}
+		return nil
+	}
+
+	accountKey := accountAttempts("login", user.Id)
+	ipKey := ipAttempts("login", client.IP)
+	if err := s.limiter.Check(accountKey, ipKey); err != nil {
+		return err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		if _, failErr := s.limiter.Fail(accountKey, ipKey); failErr != nil {
+			return failErr
+		}
+		return ErrInvalidPassword
+	}
+	return s.limiter.Reset(accountKey)
+}
+
+// completeLogin starts a session once the first factor is accepted, or asks
+// for the second factor when the account has two-factor authentication.
+func (s *UserService) completeLogin(user *models.User, client models.SessionClient) (*LoginResponse, error) {
+	if !user.TOTPEnabled {
+		return s.StartSession(user, client)
+	}
+	challenge, err := s.jwtManager.GenerateMFAChallenge(user.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResponse{
+		Message:     "Two-factor authentication required",
+		MFARequired: true,
+		MFAToken:    challenge,
+	}, nil
+}
+
+// StartSession records a new login and issues its first pair of tokens.
+func (s *UserService) StartSession(user *models.User, client models.SessionClient) (*LoginResponse, error) {
+	session, err := s.sessions.Start(user.Id, client)
 	if err != nil {
 		return nil, err
 	}
+	return s.issueTokens(user, session.Id)
+}
 
+// issueTokens creates an access token and a refresh token for a session. The
+// session is also the refresh token family.
+func (s *UserService) issueTokens(user *models.User, sessionId string) (*LoginResponse, error) {
+	token, err := s.jwtManager.Generate(user.Id, sessionId)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, err
+	}
+	err = s.refreshRepo.Create(&models.RefreshToken{
+		Id:        uuid.New().String(),
+		UserID:    user.Id,
+		FamilyID:  sessionId,
+		TokenHash: utils.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
 	return &LoginResponse{
-		Token: token,
-		User:  user,
+		User:         user,
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(s.jwtManager.TokenDuration().Seconds()),
 	}, nil
 }
 
-func (s *UserService) LoginWithOAuth(ctx context.Context, data models.OAuthUser) (*LoginResponse, error) {
-	user, err := s.repo.GetByOAuth(data.Provider, data.ID)
+// RefreshTokens exchanges a refresh token for a new pair. Each refresh token
+// works once; presenting a spent one means it leaked, so the whole session is
+// ended and the user has to log in again.
+func (s *UserService) RefreshTokens(refreshToken string) (*LoginResponse, error) {
+	stored, err := s.refreshRepo.GetByHash(utils.HashToken(refreshToken))
+	if err != nil || stored.RevokedAt != nil {
+		return nil, errors.New("invalid refresh token")
+	}
+	if stored.UsedAt != nil {
+		return nil, s.revokeReusedSession(stored)
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, errors.New("refresh token expired")
+	}
+	spent, err := s.refreshRepo.MarkUsed(stored.Id)
+	if err != nil {
+		return nil, err
+	}
+	if !spent {
+		return nil, s.revokeReusedSession(stored)
+	}
+
+	user, err := s.repo.GetByID(stored.UserID)
 	if err != nil {
-		user, err = s.repo.GetByEmail(data.Email)
+		return nil, errors.New("invalid refresh token")
+	}
+	return s.issueTokens(user, stored.FamilyID)
+}
 
-		if err != nil {
-			user = &models.User{
-				Id:            uuid.New().String(),
-				Email:         data.Email,
-				Username:      data.Name,
-				OauthProvider: data.Provider,
-				OauthId:       data.ID,
-				IsVerified:    true,
-			}
-			defaultTodo := &models.Todo{
-				Id:          uuid.New().String(),
-				UserID:      user.Id,
-				Title:       "Welcome to ToDoList!",
-				Description: "This is your first task. Explore the app and get things done!",
-				Completed:   false,
-				CreatedAt:   time.Now(),
-				UpdatedAt:   time.Now(),
-				Deadline:    time.Now().Add(24 * time.Hour),
-			}
-			err = s.repo.CreateWithDefaultTodo(user, defaultTodo)
-			if err != nil {
-				if strings.Contains(err.Error(), "unique constraint") {
-					return nil, errors.New("username or email already exists")
-				}
-				return nil, err
-			}
-		} else {
-			updateUser := &models.User{
-				Id:                      user.Id,
-				Username:                user.Username,
-				Email:                   data.Email,
-				PasswordHash:            user.PasswordHash,
-				IsVerified:              true,
-				VerificationCode:        "",
-				VerificationCodeExpires: time.Time{},
-				OauthProvider:           data.Provider,
-				OauthId:                 data.ID,
-			}
-			s.repo.Update(updateUser)
-		}
+// revokeReusedSession ends the session of a reused token. Deleting the
+// session drops its refresh tokens and rejects its access tokens.
+func (s *UserService) revokeReusedSession(stored *models.RefreshToken) error {
+	if err := s.sessions.Revoke(stored.UserID, stored.FamilyID); err != nil {
+		return err
 	}
-	token, _ := s.jwtManager.Generate(user.Id)
-	return &LoginResponse{User: user, Token: token}, nil
+	return errors.New("refresh token reuse detected, please log in again")
 }
 
 func (s *UserService) UpdateUsername(userId, newUsername string) error {
@@ -210,19 +429,19 @@ func (s *UserService) UpdateUsername(userId, newUsername string) error {
 	return s.repo.Update(user)
 }
 
-func (s *UserService) UpdatePassword(userId, oldPassword, newPassword string) error {
+// UpdatePassword sets a new password and ends all of the user's sessions.
+// Accounts without a password confirm setting one with a recent login.
+func (s *UserService) UpdatePassword(userId, sessionId, oldPassword, newPassword string, client models.SessionClient) error {
 	user, err := s.repo.GetByID(userId)
 	if err != nil {
 		return errors.New("invalid user")
 	}
 
-	if user.PasswordHash != "" {
-		err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(oldPassword))
-		if err != nil {
+	if err := s.Reauthenticate(user, sessionId, oldPassword, client); err != nil {
+		if errors.Is(err, ErrInvalidPassword) {
 			return errors.New("invalid old password")
 		}
-	} else if user.OauthProvider == "" {
-		return errors.New("cannot set password without old password for non-oauth users")
+		return err
 	}
 
 	newPasswordHash, err := bcrypt.GenerateFromPassword([]byte(newPassword), 10)
@@ -230,92 +449,287 @@ func (s *UserService) UpdatePassword(userId, oldPassword, newPassword string) er
 		return err
 	}
 	user.PasswordHash = string(newPasswordHash)
-	return s.repo.Update(user)
+	if err := s.repo.Update(user); err != nil {
+		return err
+	}
+	// A changed password logs out every device, including the current one.
+	return s.sessions.RevokeAll(userId)
+}
+
+// RequestPasswordReset emails a reset token if an account uses this email.
+// Unknown emails are ignored so the caller cannot probe for accounts; every
+// request counts against the address and IP limits either way.
+func (s *UserService) RequestPasswordReset(email string, client models.SessionClient) error {
+	keys := append(resendAttempts("reset", email), ipAttempts("reset", client.IP))
+	if err := s.limiter.Check(keys...); err != nil {
+		return err
+	}
+	if _, err := s.limiter.Fail(keys...); err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetByEmail(email)
+	if err != nil {
+		return nil
+	}
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return err
+	}
+	err = s.resetRepo.Create(&models.PasswordResetToken{
+		Id:        uuid.New().String(),
+		UserID:    user.Id,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(passwordResetTTL),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	go utils.SendPasswordResetEmail(user.Email, token)
+	return nil
+}
+
+// ResetPassword sets a new password with a token from RequestPasswordReset
+// and ends all of the user's sessions.
+func (s *UserService) ResetPassword(token, newPassword string) error {
+	reset, err := s.resetRepo.GetByHash(utils.HashToken(token))
+	if err != nil || reset.UsedAt != nil || time.Now().After(reset.ExpiresAt) {
+		return errors.New("invalid or expired reset token")
+	}
+	spent, err := s.resetRepo.MarkUsed(reset.Id)
+	if err != nil {
+		return err
+	}
+	if !spent {
+		return errors.New("invalid or expired reset token")
+	}
+
+	user, err := s.repo.GetByID(reset.UserID)
+	if err != nil {
+		return errors.New("invalid user")
+	}
+	newPasswordHash, err := bcrypt.GenerateFromPassword([]byte(newPassword), 10)
+	if err != nil {
+		return err
+	}
+	user.PasswordHash = string(newPasswordHash)
+	if err := s.repo.Update(user); err != nil {
+		return err
+	}
+	return s.sessions.RevokeAll(user.Id)
+}
+
+// validateEmail accepts a bare address, so every API that changes an email
+// shares the check the REST binding does.
+func validateEmail(email string) error {
+	parsed, err := mail.ParseAddress(email)
+	if err != nil || parsed.Address != email {
+		return errors.New("invalid email address")
+	}
+	return nil
 }
 
 func (s *UserService) RequestEmailUpdate(userId, newEmail string) error {
+	if err := validateEmail(newEmail); err != nil {
+		return err
+	}
 	user, err := s.repo.GetByID(userId)
 	if err != nil {
 		return errors.New("invalid user")
 	}
 
-	code, err := utils.GenerateVerificationCode()
-	if err != nil {
+	if strings.EqualFold(newEmail, user.Email) {
+		return errors.New("new email is the same as the current one")
+	}
+
+	// Shares the limits of ResendEmailUpdate, so asking again for the same
+	// address is no way around them.
+	keys := resendAttempts("resend", newEmail)
+	if err := s.limiter.Check(keys...); err != nil {
+		return err
+	}
+	if _, err := s.limiter.Fail(keys...); err != nil {
 		return err
 	}
-	expires := time.Now().Add(10 * time.Minute)
 
-	user.PendingEmail = newEmail
-	user.VerificationCode = code
-	user.VerificationCodeExpires = expires
+	if _, err := s.repo.GetByEmail(newEmail); err == nil {
+		return errors.New("email is already in use")
+	}
 
-	err = s.repo.Update(user)
+	return s.issueVerificationCode(user, models.VerificationEmailChange, newEmail)
+}
+
+func (s *UserService) VerifyEmailUpdate(userId, code string) error {
+	user, err := s.repo.GetByID(userId)
+	if err != nil {
+		return errors.New("invalid user")
+	}
+
+	token, err := s.consumeVerificationCode(user, models.VerificationEmailChange, code)
 	if err != nil {
 		return err
 	}
 
-	go utils.SendVerificationEmail(newEmail, code)
+	user.Email = token.Email
+	user.PendingEmail = ""
+	if err := s.repo.Update(user); err != nil {
+		if strings.Contains(err.Error(), "unique constraint") {
+			return errors.New("email is already in use")
+		}
+		return err
+	}
 	return nil
 }
 
-func (s *UserService) VerifyEmailUpdate(userId, code string) error {
+// ResendVerification emails a new registration code if an unverified account
+// uses this email. Every request counts against the address and IP limits,
+// whether or not a code is sent, so the answer doesn't reveal which
+// addresses have accounts.
+func (s *UserService) ResendVerification(email string, client models.SessionClient) error {
+	keys := append(resendAttempts("resend", email), ipAttempts("resend", client.IP))
+	if err := s.limiter.Check(keys...); err != nil {
+		return err
+	}
+	if _, err := s.limiter.Fail(keys...); err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetByEmail(email)
+	if err != nil || user.IsVerified {
+		return nil
+	}
+	return s.issueVerificationCode(user, models.VerificationRegister, user.Email)
+}
+
+// ResendEmailUpdate emails a new code for the user's pending email change,
+// also when the previous one has expired.
+func (s *UserService) ResendEmailUpdate(userId string) error {
 	user, err := s.repo.GetByID(userId)
 	if err != nil {
 		return errors.New("invalid user")
 	}
-
-	if user.PendingEmail == "" {
+	token, err := s.tokens.GetPending(user.Id, models.VerificationEmailChange)
+	if err != nil {
 		return errors.New("no pending email update")
 	}
 
-	if user.VerificationCode != code {
-		return errors.New("invalid verification code")
+	keys := resendAttempts("resend", token.Email)
+	if err := s.limiter.Check(keys...); err != nil {
+		return err
+	}
+	if _, err := s.limiter.Fail(keys...); err != nil {
+		return err
 	}
 
-	if time.Now().After(user.VerificationCodeExpires) {
-		return errors.New("verification code expired")
+	if _, err := s.repo.GetByEmail(token.Email); err == nil {
+		return errors.New("email is already in use")
 	}
+	return s.issueVerificationCode(user, models.VerificationEmailChange, token.Email)
+}
 
-	user.Email = user.PendingEmail
-	user.PendingEmail = ""
-	user.VerificationCode = ""
-	user.VerificationCodeExpires = time.Time{}
+// issueVerificationCode emails a new code for purpose to email, replacing
+// the user's previous code for the same purpose.
+func (s *UserService) issueVerificationCode(user *models.User, purpose, email string) error {
+	code, err := utils.GenerateVerificationCode()
+	if err != nil {
+		return err
+	}
+	err = s.tokens.Create(&models.VerificationToken{
+		Id:        uuid.New().String(),
+		UserID:    user.Id,
+		Purpose:   purpose,
+		CodeHash:  s.jwtManager.HashCode(code),
+		Email:     email,
+		ExpiresAt: time.Now().Add(verificationPurposes[purpose].ttl),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
 
-	return s.repo.Update(user)
+	go utils.SendVerificationEmail(email, code)
+	return nil
+}
+
+// consumeVerificationCode spends the user's pending code for purpose. Wrong
+// codes count against the account and the given keys; every
+// verificationCodeMaxFailures failures the code is invalidated, so guessing
+// has to start over with a new one.
+func (s *UserService) consumeVerificationCode(user *models.User, purpose, code string, keys ...AttemptKey) (*models.VerificationToken, error) {
+	p := verificationPurposes[purpose]
+	accountKey := accountAttempts("code:"+purpose, user.Id)
+
+	token, err := s.tokens.GetPending(user.Id, purpose)
+	if err != nil {
+		if _, failErr := s.limiter.Fail(keys...); failErr != nil {
+			return nil, failErr
+		}
+		return nil, errors.New(p.missing)
+	}
+
+	if err := s.limiter.Check(accountKey); err != nil {
+		return nil, err
+	}
+	if !s.codeMatches(token, code) {
+		failures, err := s.limiter.Fail(append([]AttemptKey{accountKey}, keys...)...)
+		if err != nil {
+			return nil, err
+		}
+		if failures%verificationCodeMaxFailures == 0 {
+			if err := s.tokens.Delete(user.Id, purpose); err != nil {
+				return nil, err
+			}
+			return nil, errors.New("too many failed attempts, request a new verification code")
+		}
+		return nil, errors.New(p.invalid)
+	}
+
+	if time.Now().After(token.ExpiresAt) {
+		return nil, errors.New("verification code expired")
+	}
+	spent, err := s.tokens.MarkUsed(token.Id)
+	if err != nil {
+		return nil, err
+	}
+	if !spent {
+		return nil, errors.New(p.invalid)
+	}
+	return token, s.limiter.Reset(accountKey)
+}
+
+// codeMatches checks a code against its stored hash. Codes carried over from
+// the users table by migration 000018 are plain SHA-256 hashes rather than
+// HMACs, and are accepted until they expire.
+func (s *UserService) codeMatches(token *models.VerificationToken, code string) bool {
+	if subtle.ConstantTimeCompare([]byte(token.CodeHash), []byte(s.jwtManager.HashCode(code))) == 1 {
+		return true
+	}
+	return subtle.ConstantTimeCompare([]byte(token.CodeHash), []byte(utils.HashToken(code))) == 1
 }
 
 func (s *UserService) GetUserByID(userId string) (*models.User, error) {
 	return s.repo.GetByID(userId)
 }
 
-func (s *UserService) DeleteUser(userId, password string) error {
+// DeleteUser asks for the password, on accounts that have one, and emails a
+// code that confirms the deletion.
+func (s *UserService) DeleteUser(userId, password string, client models.SessionClient) error {
 	user, err := s.repo.GetByID(userId)
 	if err != nil {
 		return errors.New("invalid user")
 	}
 
 	if user.PasswordHash != "" {
-		err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
-		if err != nil {
-			return errors.New("invalid password")
+		if err := s.Reauthenticate(user, "", password, client); err != nil {
+			return err
 		}
 	}
 
-	code, err := utils.GenerateVerificationCode()
-	if err != nil {
-		return err
-	}
-	expires := time.Now().Add(10 * time.Minute)
-
-	user.VerificationCode = code
-	user.VerificationCodeExpires = expires
-
-	if err = s.repo.Update(user); err != nil {
+	if _, err := s.beforeDelete(userId); err != nil {
 		return err
 	}
-
-	go utils.SendVerificationEmail(user.Email, code)
-	return nil
+	return s.issueVerificationCode(user, models.VerificationAccountDelete, user.Email)
 }
 
 func (s *UserService) VerifyEmailDelete(userId, code string) error {
@@ -324,17 +738,31 @@ func (s *UserService) VerifyEmailDelete(userId, code string) error {
 		return errors.New("invalid user")
 	}
 
-	if user.VerificationCode == "" {
-		return errors.New("no pending deletion request")
+	if _, err := s.consumeVerificationCode(user, models.VerificationAccountDelete, code); err != nil {
+		return err
 	}
 
-	if user.VerificationCode != code {
-		return errors.New("invalid verification code")
+	afterDelete, err := s.beforeDelete(userId)
+	if err != nil {
+		return err
 	}
-
-	if time.Now().After(user.VerificationCodeExpires) {
-		return errors.New("verification code expired")
+	if err := s.repo.Delete(userId); err != nil {
+		return err
+	}
+	for _, after := range afterDelete {
+		after()
 	}
+	return nil
+}
 
-	return s.repo.Delete(userId)
+func (s *UserService) beforeDelete(userId string) ([]func(), error) {
+	afterDelete := make([]func(), 0, len(s.deletionHooks))
+	for _, hook := range s.deletionHooks {
+		after, err := hook.BeforeUserDelete(userId)
+		if err != nil {
+			return nil, err
+		}
+		afterDelete = append(afterDelete, after)
+	}
+	return afterDelete, nil
 }
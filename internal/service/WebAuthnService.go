@@ -0,0 +1,282 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+	"todolist/internal/models"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+)
+
+// webauthnChallengeTTL is how long the browser has to answer a ceremony.
+const webauthnChallengeTTL = 5 * time.Minute
+
+type WebAuthnRepository interface {
+	CreateCredential(c *models.WebAuthnCredential) error
+	GetCredentialsByUserID(userId string) ([]models.WebAuthnCredential, error)
+	UpdateCredentialUse(id, credential string) error
+	RenameCredential(id, userId, name string) error
+	DeleteCredential(id, userId string) error
+	CreateChallenge(c *models.WebAuthnChallenge) error
+	TakeChallenge(id string) (*models.WebAuthnChallenge, error)
+}
+
+// WebAuthnCeremony is returned by the begin steps. Options is passed to
+// navigator.credentials.create() or .get(); ChallengeID is sent back with
+// the result.
+type WebAuthnCeremony struct {
+	ChallengeID string `json:"challenge_id"`
+	Options     any    `json:"options" swaggertype:"object"`
+}
+
+// WebAuthnService registers passkeys and logs users in with them. Passkeys
+// are discoverable and require user verification (PIN or biometrics), so a
+// passkey login counts as two factors and skips the TOTP step.
+type WebAuthnService struct {
+	repo        WebAuthnRepository
+	users       UserRepository
+	userService *UserService
+	identities  *IdentityService
+	limiter     *AttemptLimiter
+	webauthn    *webauthn.WebAuthn
+}
+
+func NewWebAuthnService(repo WebAuthnRepository, users UserRepository, userService *UserService, identities *IdentityService, limiter *AttemptLimiter, rpID string, rpOrigins []string) (*WebAuthnService, error) {
+	wa, err := webauthn.New(&webauthn.Config{
+		RPID:          rpID,
+		RPDisplayName: totpIssuer,
+		RPOrigins:     rpOrigins,
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			ResidentKey:      protocol.ResidentKeyRequirementRequired,
+			UserVerification: protocol.VerificationRequired,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &WebAuthnService{repo: repo, users: users, userService: userService, identities: identities, limiter: limiter, webauthn: wa}, nil
+}
+
+// webauthnUser adapts a user and their stored passkeys to the library.
+type webauthnUser struct {
+	user        *models.User
+	stored      []models.WebAuthnCredential
+	credentials []webauthn.Credential
+}
+
+func (u *webauthnUser) WebAuthnID() []byte                         { return []byte(u.user.Id) }
+func (u *webauthnUser) WebAuthnName() string                       { return u.user.Email }
+func (u *webauthnUser) WebAuthnDisplayName() string                { return u.user.Username }
+func (u *webauthnUser) WebAuthnCredentials() []webauthn.Credential { return u.credentials }
+
+// storedCredential returns the database row of a library credential.
+func (u *webauthnUser) storedCredential(id []byte) *models.WebAuthnCredential {
+	credentialID := encodeCredentialID(id)
+	for i := range u.stored {
+		if u.stored[i].CredentialID == credentialID {
+			return &u.stored[i]
+		}
+	}
+	return nil
+}
+
+func encodeCredentialID(id []byte) string {
+	return base64.RawURLEncoding.EncodeToString(id)
+}
+
+func (s *WebAuthnService) loadUser(userId string) (*webauthnUser, error) {
+	user, err := s.users.GetByID(userId)
+	if err != nil {
+		return nil, errors.New("invalid user")
+	}
+	stored, err := s.repo.GetCredentialsByUserID(user.Id)
+	if err != nil {
+		return nil, err
+	}
+	credentials := make([]webauthn.Credential, 0, len(stored))
+	for _, c := range stored {
+		var credential webauthn.Credential
+		if err := json.Unmarshal([]byte(c.Credential), &credential); err != nil {
+			return nil, err
+		}
+		credentials = append(credentials, credential)
+	}
+	return &webauthnUser{user: user, stored: stored, credentials: credentials}, nil
+}
+
+func (s *WebAuthnService) saveChallenge(userId *string, session *webauthn.SessionData, options any) (*WebAuthnCeremony, error) {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return nil, err
+	}
+	challenge := &models.WebAuthnChallenge{
+		Id:          uuid.New().String(),
+		UserID:      userId,
+		SessionData: string(data),
+		ExpiresAt:   time.Now().Add(webauthnChallengeTTL),
+	}
+	if err := s.repo.CreateChallenge(challenge); err != nil {
+		return nil, err
+	}
+	return &WebAuthnCeremony{ChallengeID: challenge.Id, Options: options}, nil
+}
+
+// takeChallenge loads and spends a ceremony started for userId (nil for
+// logins).
+func (s *WebAuthnService) takeChallenge(id string, userId *string) (*webauthn.SessionData, error) {
+	challenge, err := s.repo.TakeChallenge(id)
+	if err != nil || time.Now().After(challenge.ExpiresAt) {
+		return nil, errors.New("passkey request expired, please try again")
+	}
+	if (challenge.UserID == nil) != (userId == nil) || (userId != nil && *challenge.UserID != *userId) {
+		return nil, errors.New("passkey request expired, please try again")
+	}
+	var session webauthn.SessionData
+	if err := json.Unmarshal([]byte(challenge.SessionData), &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// BeginRegistration starts adding a passkey. Like other sign-in changes it
+// must be confirmed with the password, or a recent login on accounts
+// without one.
+func (s *WebAuthnService) BeginRegistration(userId, sessionId, password string, client models.SessionClient) (*WebAuthnCeremony, error) {
+	user, err := s.loadUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userService.Reauthenticate(user.user, sessionId, password, client); err != nil {
+		return nil, err
+	}
+	options, session, err := s.webauthn.BeginRegistration(user,
+		webauthn.WithExclusions(webauthn.Credentials(user.credentials).CredentialDescriptors()))
+	if err != nil {
+		return nil, err
+	}
+	return s.saveChallenge(&user.user.Id, session, options)
+}
+
+func (s *WebAuthnService) FinishRegistration(userId, challengeId, name string, response []byte) (*models.WebAuthnCredential, error) {
+	session, err := s.takeChallenge(challengeId, &userId)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.loadUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(response)
+	if err != nil {
+		return nil, errors.New("invalid passkey response")
+	}
+	credential, err := s.webauthn.CreateCredential(user, *session, parsed)
+	if err != nil {
+		return nil, errors.New("passkey could not be verified")
+	}
+	data, err := json.Marshal(credential)
+	if err != nil {
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "Passkey"
+	}
+	stored := &models.WebAuthnCredential{
+		Id:           uuid.New().String(),
+		UserID:       userId,
+		Name:         name,
+		CredentialID: encodeCredentialID(credential.ID),
+		Credential:   string(data),
+		CreatedAt:    time.Now(),
+	}
+	if err := s.repo.CreateCredential(stored); err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// BeginLogin starts a username-less login: the browser offers the passkeys
+// it has for this site and the chosen one identifies the user. Begins are
+// limited per client IP since each one stores a challenge.
+func (s *WebAuthnService) BeginLogin(ip string) (*WebAuthnCeremony, error) {
+	key := ceremonyAttempts(ip)
+	if err := s.limiter.Check(key); err != nil {
+		return nil, err
+	}
+	if _, err := s.limiter.Fail(key); err != nil {
+		return nil, err
+	}
+	options, session, err := s.webauthn.BeginDiscoverableLogin(
+		webauthn.WithUserVerification(protocol.VerificationRequired))
+	if err != nil {
+		return nil, err
+	}
+	return s.saveChallenge(nil, session, options)
+}
+
+func (s *WebAuthnService) FinishLogin(challengeId string, response []byte, client models.SessionClient) (*LoginResponse, error) {
+	session, err := s.takeChallenge(challengeId, nil)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(response)
+	if err != nil {
+		return nil, errors.New("invalid passkey response")
+	}
+
+	var user *webauthnUser
+	_, credential, err := s.webauthn.ValidatePasskeyLogin(func(_, userHandle []byte) (webauthn.User, error) {
+		user, err = s.loadUser(string(userHandle))
+		return user, err
+	}, *session, parsed)
+	if err != nil {
+		return nil, errors.New("passkey could not be verified")
+	}
+	if credential.Authenticator.CloneWarning {
+		return nil, errors.New("passkey could not be verified")
+	}
+	if !user.user.IsVerified {
+		return nil, errors.New("user is not verified")
+	}
+
+	stored := user.storedCredential(credential.ID)
+	if stored == nil {
+		return nil, errors.New("passkey could not be verified")
+	}
+	data, err := json.Marshal(credential)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateCredentialUse(stored.Id, string(data)); err != nil {
+		return nil, err
+	}
+	return s.userService.StartSession(user.user, client)
+}
+
+func (s *WebAuthnService) GetCredentials(userId string) ([]models.WebAuthnCredential, error) {
+	return s.repo.GetCredentialsByUserID(userId)
+}
+
+func (s *WebAuthnService) RenameCredential(userId, id, name string) error {
+	if err := s.repo.RenameCredential(id, userId, strings.TrimSpace(name)); err != nil {
+		return errors.New("passkey not found")
+	}
+	return nil
+}
+
+func (s *WebAuthnService) DeleteCredential(userId, id string) error {
+	if err := s.identities.ensureOtherLoginMethod(userId); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteCredential(id, userId); err != nil {
+		return errors.New("passkey not found")
+	}
+	return nil
+}
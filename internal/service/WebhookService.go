@@ -0,0 +1,252 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+	"todolist/internal/models"
+	"todolist/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+const (
+	webhookMaxAttempts   = 5
+	webhookBaseDelay     = 2 * time.Second
+	webhookMaxFailures   = 10
+	webhookTimeout       = 10 * time.Second
+	webhookDeliveryLimit = 50
+)
+
+type WebhookRepository interface {
+	Create(hook *models.Webhook) error
+	GetByID(id, userId string) (*models.Webhook, error)
+	GetListByUserID(userId string) ([]models.Webhook, error)
+	GetActiveForEvent(userId, event string) ([]models.Webhook, error)
+	Update(hook *models.Webhook) error
+	Delete(id, userId string) error
+	RecordFailure(id string, maxFailures int) (bool, error)
+	ResetFailures(id string) error
+	CreateDelivery(d *models.WebhookDelivery) error
+	GetDeliveries(webhookId string, limit int) ([]models.WebhookDelivery, error)
+}
+
+type WebhookService struct {
+	repo   WebhookRepository
+	client *http.Client
+}
+
+// CreatedWebhook carries the signing secret, which is only shown once.
+type CreatedWebhook struct {
+	models.Webhook
+	Secret string `json:"secret"`
+}
+
+type WebhookPayload struct {
+	Id        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      models.Todo `json:"data"`
+}
+
+func NewWebhookService(repo WebhookRepository) *WebhookService {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = utils.PublicDialer(webhookTimeout).DialContext
+	return &WebhookService{
+		repo:   repo,
+		client: &http.Client{Timeout: webhookTimeout, Transport: transport},
+	}
+}
+
+func validateWebhook(rawURL string, events []string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("webhook url must be an absolute http(s) url")
+	}
+	// Deliveries are checked again when dialing; this gives an early error.
+	ips, err := net.LookupIP(u.Hostname())
+	if err != nil || len(ips) == 0 {
+		return errors.New("webhook host could not be resolved")
+	}
+	for _, ip := range ips {
+		if !utils.IsPublicIP(ip) {
+			return errors.New("webhook url must point to a public address")
+		}
+	}
+	if len(events) == 0 {
+		return errors.New("at least one event is required")
+	}
+	for _, event := range events {
+		if !slices.Contains(models.TodoEventTypes, models.TodoEventType(event)) {
+			return errors.New("unknown event: " + event)
+		}
+	}
+	return nil
+}
+
+func (s *WebhookService) CreateWebhook(userId, rawURL string, events []string) (*CreatedWebhook, error) {
+	if err := validateWebhook(rawURL, events); err != nil {
+		return nil, err
+	}
+	secret, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, err
+	}
+	hook := &models.Webhook{
+		Id:        uuid.New().String(),
+		UserID:    userId,
+		URL:       rawURL,
+		Secret:    secret,
+		Events:    events,
+		IsActive:  true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := s.repo.Create(hook); err != nil {
+		return nil, err
+	}
+	return &CreatedWebhook{Webhook: *hook, Secret: secret}, nil
+}
+
+func (s *WebhookService) GetWebhooks(userId string) ([]models.Webhook, error) {
+	return s.repo.GetListByUserID(userId)
+}
+
+func (s *WebhookService) UpdateWebhook(userId, id, rawURL string, events []string, isActive *bool) (*models.Webhook, error) {
+	hook, err := s.repo.GetByID(id, userId)
+	if err != nil {
+		return nil, errors.New("webhook not found")
+	}
+	if err := validateWebhook(rawURL, events); err != nil {
+		return nil, err
+	}
+	if isActive != nil {
+		if *isActive && !hook.IsActive {
+			hook.FailureCount = 0
+		}
+		hook.IsActive = *isActive
+	}
+	hook.URL = rawURL
+	hook.Events = events
+	if err := s.repo.Update(hook); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+func (s *WebhookService) DeleteWebhook(userId, id string) error {
+	return s.repo.Delete(id, userId)
+}
+
+func (s *WebhookService) GetDeliveries(userId, id string) ([]models.WebhookDelivery, error) {
+	if _, err := s.repo.GetByID(id, userId); err != nil {
+		return nil, errors.New("webhook not found")
+	}
+	return s.repo.GetDeliveries(id, webhookDeliveryLimit)
+}
+
+// HandleTodoEvent fans the event out to every active subscriber in the background.
+func (s *WebhookService) HandleTodoEvent(event models.TodoEvent) {
+	go func() {
+		hooks, err := s.repo.GetActiveForEvent(event.UserID, string(event.Type))
+		if err != nil {
+			slog.Error("Failed to load webhooks", "error", err, "user_id", event.UserID)
+			return
+		}
+		if len(hooks) == 0 {
+			return
+		}
+
+		body, err := json.Marshal(WebhookPayload{
+			Id:        uuid.New().String(),
+			Event:     string(event.Type),
+			CreatedAt: event.OccurredAt,
+			Data:      event.Todo,
+		})
+		if err != nil {
+			slog.Error("Failed to encode webhook payload", "error", err)
+			return
+		}
+
+		for _, hook := range hooks {
+			go s.deliver(hook, string(event.Type), body)
+		}
+	}()
+}
+
+func (s *WebhookService) deliver(hook models.Webhook, event string, body []byte) {
+	delay := webhookBaseDelay
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		statusCode, err := s.send(hook, event, body)
+		delivery := &models.WebhookDelivery{
+			Id:         uuid.New().String(),
+			WebhookID:  hook.Id,
+			Event:      event,
+			Payload:    string(body),
+			Attempt:    attempt,
+			StatusCode: statusCode,
+			Success:    err == nil,
+			CreatedAt:  time.Now(),
+		}
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+		if logErr := s.repo.CreateDelivery(delivery); logErr != nil {
+			slog.Error("Failed to record webhook delivery", "error", logErr, "webhook_id", hook.Id)
+		}
+
+		if err == nil {
+			if err := s.repo.ResetFailures(hook.Id); err != nil {
+				slog.Error("Failed to reset webhook failures", "error", err, "webhook_id", hook.Id)
+			}
+			return
+		}
+
+		if attempt < webhookMaxAttempts {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+
+	disabled, err := s.repo.RecordFailure(hook.Id, webhookMaxFailures)
+	if err != nil {
+		slog.Error("Failed to record webhook failure", "error", err, "webhook_id", hook.Id)
+		return
+	}
+	if disabled {
+		slog.Warn("Webhook disabled after repeated failures", "webhook_id", hook.Id, "url", hook.URL)
+	}
+}
+
+func (s *WebhookService) send(hook models.Webhook, event string, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ToDoList-Webhooks/1.0")
+	req.Header.Set("X-Webhook-Id", hook.Id)
+	req.Header.Set("X-Webhook-Event", event)
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Webhook-Signature", utils.SignWebhookPayload(hook.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, errors.New("unexpected status " + resp.Status)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,356 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+	"todolist/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type WorkspaceRepository interface {
+	Create(ws *models.Workspace, owner *models.WorkspaceMember) error
+	GetForMember(id, userId string) (*models.Workspace, error)
+	GetListByUserID(userId string) ([]models.Workspace, error)
+	GetActiveID(userId string) (string, error)
+	SetActive(userId, workspaceId string) error
+	Rename(id, name string) error
+	Delete(id string) error
+	GetMembers(workspaceId string) ([]models.WorkspaceMember, error)
+	AddMember(m *models.WorkspaceMember) error
+	UpdateMemberRole(workspaceId, userId, role string) error
+	RemoveMember(workspaceId, userId string) error
+	CountOwners(workspaceId string) (int, error)
+}
+
+// ErrSoleWorkspaceOwner refuses to delete the account of the only owner of a
+// team workspace that has other members.
+var ErrSoleWorkspaceOwner = errors.New("transfer ownership of your team workspaces before deleting your account")
+
+// WorkspaceDeletionHook is called before a workspace is deleted. The returned
+// function runs once the deletion succeeded.
+type WorkspaceDeletionHook interface {
+	BeforeWorkspaceDelete(workspaceId string) (func(), error)
+}
+
+// WorkspaceService manages team workspaces. Owners manage everything, admins
+// manage members, and every member can work with the workspace's todos.
+type WorkspaceService struct {
+	repo          WorkspaceRepository
+	userRepo      UserRepository
+	deletionHooks []WorkspaceDeletionHook
+}
+
+func NewWorkspaceService(repo WorkspaceRepository, userRepo UserRepository) *WorkspaceService {
+	return &WorkspaceService{repo: repo, userRepo: userRepo}
+}
+
+func (s *WorkspaceService) AddDeletionHook(hook WorkspaceDeletionHook) {
+	s.deletionHooks = append(s.deletionHooks, hook)
+}
+
+func validateWorkspaceRole(role string) error {
+	switch role {
+	case models.WorkspaceRoleOwner, models.WorkspaceRoleAdmin, models.WorkspaceRoleMember:
+		return nil
+	}
+	return errors.New("role must be owner, admin or member")
+}
+
+func validateWorkspaceName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("name is required")
+	}
+	if len([]rune(name)) > 100 {
+		return "", errors.New("name must be at most 100 characters long")
+	}
+	return name, nil
+}
+
+// membership returns the workspace as seen by userId.
+func (s *WorkspaceService) membership(userId, id string) (*models.Workspace, error) {
+	ws, err := s.repo.GetForMember(id, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("workspace not found")
+	}
+	return ws, err
+}
+
+func (s *WorkspaceService) manageable(userId, id string) (*models.Workspace, error) {
+	ws, err := s.membership(userId, id)
+	if err != nil {
+		return nil, err
+	}
+	if ws.Role == models.WorkspaceRoleMember {
+		return nil, errors.New("only owners and admins can manage this workspace")
+	}
+	if ws.IsPersonal {
+		return nil, errors.New("personal workspace cannot be shared")
+	}
+	return ws, nil
+}
+
+func (s *WorkspaceService) CreateWorkspace(userId, name string) (*models.Workspace, error) {
+	name, err := validateWorkspaceName(name)
+	if err != nil {
+		return nil, err
+	}
+	ws := &models.Workspace{
+		Id:        uuid.New().String(),
+		Name:      name,
+		Role:      models.WorkspaceRoleOwner,
+		CreatedAt: time.Now(),
+	}
+	owner := &models.WorkspaceMember{
+		WorkspaceID: ws.Id,
+		UserID:      userId,
+		Role:        models.WorkspaceRoleOwner,
+		JoinedAt:    ws.CreatedAt,
+	}
+	if err := s.repo.Create(ws, owner); err != nil {
+		return nil, err
+	}
+	return ws, nil
+}
+
+// GetWorkspaces lists the user's workspaces and marks the active one.
+func (s *WorkspaceService) GetWorkspaces(userId string) ([]models.Workspace, error) {
+	workspaces, err := s.repo.GetListByUserID(userId)
+	if err != nil {
+		return nil, err
+	}
+	activeId, err := s.repo.GetActiveID(userId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range workspaces {
+		workspaces[i].Active = workspaces[i].Id == activeId
+	}
+	return workspaces, nil
+}
+
+func (s *WorkspaceService) GetWorkspace(userId, id string) (*models.Workspace, error) {
+	ws, err := s.membership(userId, id)
+	if err != nil {
+		return nil, err
+	}
+	activeId, err := s.repo.GetActiveID(userId)
+	if err != nil {
+		return nil, err
+	}
+	ws.Active = ws.Id == activeId
+	return ws, nil
+}
+
+func (s *WorkspaceService) RenameWorkspace(userId, id, name string) (*models.Workspace, error) {
+	name, err := validateWorkspaceName(name)
+	if err != nil {
+		return nil, err
+	}
+	ws, err := s.membership(userId, id)
+	if err != nil {
+		return nil, err
+	}
+	if ws.IsPersonal {
+		return nil, errors.New("personal workspace cannot be renamed")
+	}
+	if ws.Role == models.WorkspaceRoleMember {
+		return nil, errors.New("only owners and admins can rename this workspace")
+	}
+	if err := s.repo.Rename(id, name); err != nil {
+		return nil, err
+	}
+	ws.Name = name
+	return ws, nil
+}
+
+// DeleteWorkspace removes a team workspace and all its todos.
+func (s *WorkspaceService) DeleteWorkspace(userId, id string) error {
+	ws, err := s.membership(userId, id)
+	if err != nil {
+		return err
+	}
+	if ws.IsPersonal {
+		return errors.New("personal workspace cannot be deleted")
+	}
+	if ws.Role != models.WorkspaceRoleOwner {
+		return errors.New("only owners can delete this workspace")
+	}
+
+	afterDelete := make([]func(), 0, len(s.deletionHooks))
+	for _, hook := range s.deletionHooks {
+		after, err := hook.BeforeWorkspaceDelete(id)
+		if err != nil {
+			return err
+		}
+		afterDelete = append(afterDelete, after)
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	for _, after := range afterDelete {
+		after()
+	}
+	return nil
+}
+
+// SwitchWorkspace makes id the workspace all todo requests of the user
+// operate on.
+func (s *WorkspaceService) SwitchWorkspace(userId, id string) (*models.Workspace, error) {
+	ws, err := s.membership(userId, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetActive(userId, id); err != nil {
+		return nil, err
+	}
+	ws.Active = true
+	return ws, nil
+}
+
+func (s *WorkspaceService) GetMembers(userId, id string) ([]models.WorkspaceMember, error) {
+	if _, err := s.membership(userId, id); err != nil {
+		return nil, err
+	}
+	return s.repo.GetMembers(id)
+}
+
+// AddMember invites a registered user by username or email. Only owners can
+// grant the owner or admin role.
+func (s *WorkspaceService) AddMember(userId, id, login, role string) (*models.WorkspaceMember, error) {
+	if err := validateWorkspaceRole(role); err != nil {
+		return nil, err
+	}
+	ws, err := s.manageable(userId, id)
+	if err != nil {
+		return nil, err
+	}
+	if role != models.WorkspaceRoleMember && ws.Role != models.WorkspaceRoleOwner {
+		return nil, errors.New("only owners can grant the " + role + " role")
+	}
+
+	login = strings.TrimSpace(login)
+	var user *models.User
+	if strings.Contains(login, "@") {
+		user, err = s.userRepo.GetByEmail(login)
+	} else {
+		user, err = s.userRepo.GetByUsername(login)
+	}
+	if err != nil || !user.IsVerified {
+		return nil, errors.New("user not found")
+	}
+
+	member := &models.WorkspaceMember{
+		WorkspaceID: id,
+		UserID:      user.Id,
+		Username:    user.Username,
+		Email:       user.Email,
+		Role:        role,
+		JoinedAt:    time.Now(),
+	}
+	if err := s.repo.AddMember(member); err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return nil, errors.New("user is already a member")
+		}
+		return nil, err
+	}
+	return member, nil
+}
+
+func (s *WorkspaceService) UpdateMemberRole(userId, id, memberId, role string) error {
+	if err := validateWorkspaceRole(role); err != nil {
+		return err
+	}
+	ws, err := s.manageable(userId, id)
+	if err != nil {
+		return err
+	}
+	if ws.Role != models.WorkspaceRoleOwner {
+		return errors.New("only owners can change roles")
+	}
+	target, err := s.membership(memberId, id)
+	if err != nil {
+		return errors.New("member not found")
+	}
+	if target.Role == models.WorkspaceRoleOwner && role != models.WorkspaceRoleOwner {
+		if err := s.ensureAnotherOwner(id); err != nil {
+			return err
+		}
+	}
+	return s.repo.UpdateMemberRole(id, memberId, role)
+}
+
+// RemoveMember removes memberId from the workspace. Members may remove
+// themselves; admins may remove plain members; owners may remove anyone.
+func (s *WorkspaceService) RemoveMember(userId, id, memberId string) error {
+	ws, err := s.membership(userId, id)
+	if err != nil {
+		return err
+	}
+	if ws.IsPersonal {
+		return errors.New("cannot leave personal workspace")
+	}
+	target, err := s.membership(memberId, id)
+	if err != nil {
+		return errors.New("member not found")
+	}
+
+	if memberId != userId {
+		switch {
+		case ws.Role == models.WorkspaceRoleMember:
+			return errors.New("only owners and admins can remove members")
+		case ws.Role == models.WorkspaceRoleAdmin && target.Role != models.WorkspaceRoleMember:
+			return errors.New("admins can remove only members")
+		}
+	}
+	if target.Role == models.WorkspaceRoleOwner {
+		if err := s.ensureAnotherOwner(id); err != nil {
+			return err
+		}
+	}
+	return s.repo.RemoveMember(id, memberId)
+}
+
+// BeforeUserDelete refuses to delete the only owner of a team workspace that
+// has other members. Team workspaces without other members go away with the
+// account.
+func (s *WorkspaceService) BeforeUserDelete(userId string) (func(), error) {
+	workspaces, err := s.repo.GetListByUserID(userId)
+	if err != nil {
+		return nil, err
+	}
+	for _, ws := range workspaces {
+		if ws.IsPersonal || ws.Role != models.WorkspaceRoleOwner {
+			continue
+		}
+		members, err := s.repo.GetMembers(ws.Id)
+		if err != nil {
+			return nil, err
+		}
+		if len(members) < 2 {
+			continue
+		}
+		owners, err := s.repo.CountOwners(ws.Id)
+		if err != nil {
+			return nil, err
+		}
+		if owners < 2 {
+			return nil, ErrSoleWorkspaceOwner
+		}
+	}
+	return func() {}, nil
+}
+
+func (s *WorkspaceService) ensureAnotherOwner(id string) error {
+	owners, err := s.repo.CountOwners(id)
+	if err != nil {
+		return err
+	}
+	if owners < 2 {
+		return errors.New("workspace must keep at least one owner")
+	}
+	return nil
+}
@@ -0,0 +1,286 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"todolist/internal/models"
+	"todolist/internal/utils"
+)
+
+// In-memory repositories for service tests. They implement only what the
+// services under test use, with the same not-found errors as the database.
+
+type fakeUsers struct {
+	mu    sync.Mutex
+	users map[string]*models.User
+}
+
+func (f *fakeUsers) add(u *models.User) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	copied := *u
+	f.users[u.Id] = &copied
+}
+
+func (f *fakeUsers) find(match func(*models.User) bool) (*models.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, u := range f.users {
+		if match(u) {
+			copied := *u
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeUsers) GetByID(id string) (*models.User, error) {
+	return f.find(func(u *models.User) bool { return u.Id == id })
+}
+
+func (f *fakeUsers) GetByEmail(email string) (*models.User, error) {
+	return f.find(func(u *models.User) bool { return strings.EqualFold(u.Email, email) })
+}
+
+func (f *fakeUsers) GetByUsername(username string) (*models.User, error) {
+	return f.find(func(u *models.User) bool { return u.Username == username })
+}
+
+func (f *fakeUsers) CreateWithPersonalWorkspace(u *models.User, ws *models.Workspace, todo *models.Todo, identity *models.UserIdentity) error {
+	f.add(u)
+	return nil
+}
+
+func (f *fakeUsers) Update(u *models.User) error {
+	f.add(u)
+	return nil
+}
+
+func (f *fakeUsers) Delete(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.users[id]; !ok {
+		return errors.New("no rows affected")
+	}
+	delete(f.users, id)
+	return nil
+}
+
+type fakeVerificationTokens struct {
+	mu     sync.Mutex
+	tokens []models.VerificationToken
+}
+
+func (f *fakeVerificationTokens) Create(t *models.VerificationToken) error {
+	f.Delete(t.UserID, t.Purpose)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tokens = append(f.tokens, *t)
+	return nil
+}
+
+func (f *fakeVerificationTokens) GetPending(userId, purpose string) (*models.VerificationToken, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, t := range f.tokens {
+		if t.UserID == userId && t.Purpose == purpose && t.UsedAt == nil {
+			return &t, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeVerificationTokens) MarkUsed(id string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.tokens {
+		if f.tokens[i].Id == id && f.tokens[i].UsedAt == nil {
+			now := time.Now()
+			f.tokens[i].UsedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeVerificationTokens) Delete(userId, purpose string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	kept := f.tokens[:0]
+	for _, t := range f.tokens {
+		if t.UserID != userId || t.Purpose != purpose {
+			kept = append(kept, t)
+		}
+	}
+	f.tokens = kept
+	return nil
+}
+
+type fakeAuthAttempts struct {
+	mu       sync.Mutex
+	attempts map[string]*models.AuthAttempt
+}
+
+func (f *fakeAuthAttempts) Get(key string) (*models.AuthAttempt, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	a, ok := f.attempts[key]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	copied := *a
+	return &copied, nil
+}
+
+func (f *fakeAuthAttempts) RecordFailure(key string, window time.Duration) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	a, ok := f.attempts[key]
+	if !ok || time.Since(a.UpdatedAt) > window {
+		a = &models.AuthAttempt{Key: key}
+		f.attempts[key] = a
+	}
+	a.Failures++
+	a.UpdatedAt = time.Now()
+	return a.Failures, nil
+}
+
+func (f *fakeAuthAttempts) Lock(key string, until time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if a, ok := f.attempts[key]; ok {
+		a.LockedUntil = &until
+	}
+	return nil
+}
+
+func (f *fakeAuthAttempts) Reset(key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.attempts, key)
+	return nil
+}
+
+type fakeSessions struct {
+	mu       sync.Mutex
+	sessions map[string]models.Session
+}
+
+func (f *fakeSessions) Create(session *models.Session) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sessions[session.Id] = *session
+	return nil
+}
+
+func (f *fakeSessions) GetByID(id, userId string) (*models.Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.sessions[id]
+	if !ok || s.UserID != userId {
+		return nil, sql.ErrNoRows
+	}
+	return &s, nil
+}
+
+func (f *fakeSessions) GetListByUserID(userId string) ([]models.Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sessions := []models.Session{}
+	for _, s := range f.sessions {
+		if s.UserID == userId {
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions, nil
+}
+
+func (f *fakeSessions) Touch(id, ip string) error {
+	return nil
+}
+
+func (f *fakeSessions) Delete(id, userId string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s, ok := f.sessions[id]; !ok || s.UserID != userId {
+		return errors.New("no rows affected")
+	}
+	delete(f.sessions, id)
+	return nil
+}
+
+func (f *fakeSessions) DeleteAllExcept(userId, keepId string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for id, s := range f.sessions {
+		if s.UserID == userId && id != keepId {
+			delete(f.sessions, id)
+		}
+	}
+	return nil
+}
+
+type fakeRefreshTokens struct {
+	mu     sync.Mutex
+	tokens map[string]models.RefreshToken
+}
+
+func (f *fakeRefreshTokens) Create(t *models.RefreshToken) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tokens[t.TokenHash] = *t
+	return nil
+}
+
+func (f *fakeRefreshTokens) GetByHash(tokenHash string) (*models.RefreshToken, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, ok := f.tokens[tokenHash]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &t, nil
+}
+
+func (f *fakeRefreshTokens) MarkUsed(id string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for hash, t := range f.tokens {
+		if t.Id == id && t.UsedAt == nil {
+			now := time.Now()
+			t.UsedAt = &now
+			f.tokens[hash] = t
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// userTestEnv is a UserService over fake repositories.
+type userTestEnv struct {
+	users    *fakeUsers
+	codes    *fakeVerificationTokens
+	attempts *fakeAuthAttempts
+	sessions *SessionService
+	limiter  *AttemptLimiter
+	jwt      *utils.JWTManager
+	service  *UserService
+}
+
+func newUserTestEnv(t *testing.T) *userTestEnv {
+	t.Helper()
+	env := &userTestEnv{
+		users:    &fakeUsers{users: map[string]*models.User{}},
+		codes:    &fakeVerificationTokens{},
+		attempts: &fakeAuthAttempts{attempts: map[string]*models.AuthAttempt{}},
+		jwt:      utils.NewJWTManager("test-secret", 15*time.Minute),
+	}
+	env.sessions = NewSessionService(&fakeSessions{sessions: map[string]models.Session{}}, env.jwt)
+	env.limiter = NewAttemptLimiter(env.attempts)
+	env.service = NewUserService(env.users, &fakeRefreshTokens{tokens: map[string]models.RefreshToken{}}, nil, env.codes, nil, env.sessions, env.limiter, env.jwt, time.Hour)
+	return env
+}
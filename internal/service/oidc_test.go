@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"todolist/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	mockClientID = "todolist"
+	mockKeyID    = "test-key"
+)
+
+// mockGrant is an authorization the mock issuer handed out as a code.
+type mockGrant struct {
+	challenge string
+	nonce     string
+}
+
+// mockIssuer is an OpenID Connect provider with discovery, JWKS and a token
+// endpoint that checks PKCE. Claims set in override replace (or, when nil,
+// remove) the claims of the ID tokens it issues.
+type mockIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu       sync.Mutex
+	codes    map[string]mockGrant
+	override map[string]any
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockIssuer{key: key, codes: map[string]mockGrant{}, override: map[string]any{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeMockJSON(w, http.StatusOK, map[string]any{
+			"issuer":                                m.server.URL,
+			"authorization_endpoint":                m.server.URL + "/authorize",
+			"token_endpoint":                        m.server.URL + "/token",
+			"jwks_uri":                              m.server.URL + "/jwks",
+			"response_types_supported":              []string{"code"},
+			"subject_types_supported":               []string{"public"},
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeMockJSON(w, http.StatusOK, map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": mockKeyID,
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("POST /token", m.token)
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+func writeMockJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// authorize plays the user logging in at the provider: it checks the
+// authorization URL and returns the code the provider would redirect with.
+func (m *mockIssuer) authorize(t *testing.T, authorizationURL string) string {
+	t.Helper()
+	u, err := url.Parse(authorizationURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if !strings.HasPrefix(authorizationURL, m.server.URL+"/authorize?") {
+		t.Fatalf("authorization URL %q is not the issuer's", authorizationURL)
+	}
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		t.Fatalf("authorization URL %q has no S256 PKCE challenge", authorizationURL)
+	}
+	if q.Get("nonce") == "" {
+		t.Fatalf("authorization URL %q has no nonce", authorizationURL)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	code := "code-" + q.Get("state")[:8]
+	m.codes[code] = mockGrant{challenge: q.Get("code_challenge"), nonce: q.Get("nonce")}
+	return code
+}
+
+func (m *mockIssuer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeMockJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	m.mu.Lock()
+	grant, ok := m.codes[r.PostForm.Get("code")]
+	delete(m.codes, r.PostForm.Get("code"))
+	override := m.override
+	m.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge {
+		writeMockJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"iss":            m.server.URL,
+		"sub":            "mock-user-1",
+		"aud":            mockClientID,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"nonce":          grant.nonce,
+		"email":          "ann@example.com",
+		"email_verified": true,
+		"name":           "Ann",
+	}
+	for k, v := range override {
+		if v == nil {
+			delete(claims, k)
+		} else {
+			claims[k] = v
+		}
+	}
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	idToken.Header["kid"] = mockKeyID
+	signed, err := idToken.SignedString(m.key)
+	if err != nil {
+		writeMockJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeMockJSON(w, http.StatusOK, map[string]any{
+		"access_token": "mock-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     signed,
+	})
+}
+
+type fakeOIDCStates struct {
+	mu     sync.Mutex
+	states map[string]models.OIDCState
+}
+
+func (f *fakeOIDCStates) Create(s *models.OIDCState) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.states[s.State] = *s
+	return nil
+}
+
+func (f *fakeOIDCStates) Take(state string) (*models.OIDCState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.states[state]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	delete(f.states, state)
+	return &s, nil
+}
+
+func newTestOIDCService(t *testing.T, issuer *mockIssuer, trustEmail bool) (*OIDCService, *fakeOIDCStates) {
+	t.Helper()
+	states := &fakeOIDCStates{states: map[string]models.OIDCState{}}
+	s, err := NewOIDCService(states, nil, nil, "http://localhost:5173/", []OIDCProviderConfig{{
+		ID:           "mock",
+		Issuer:       issuer.server.URL,
+		ClientID:     mockClientID,
+		ClientSecret: "mock-secret",
+		TrustEmail:   trustEmail,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, states
+}
+
+func TestOIDCExchange(t *testing.T) {
+	tests := []struct {
+		name       string
+		trustEmail bool
+		override   map[string]any
+		// tamper runs between the provider's redirect and the exchange.
+		tamper  func(states *fakeOIDCStates, state string)
+		wantErr string
+	}{
+		{name: "verified email"},
+		{
+			name:     "unverified email",
+			override: map[string]any{"email_verified": false},
+			wantErr:  "the email address is not verified by the provider",
+		},
+		{
+			name:     "missing email_verified",
+			override: map[string]any{"email_verified": nil},
+			wantErr:  "the email address is not verified by the provider",
+		},
+		{
+			name:       "missing email_verified from trusted provider",
+			trustEmail: true,
+			override:   map[string]any{"email_verified": nil},
+		},
+		{
+			name:       "unverified email from trusted provider",
+			trustEmail: true,
+			override:   map[string]any{"email_verified": "false"},
+			wantErr:    "the email address is not verified by the provider",
+		},
+		{
+			name:     "nonce mismatch",
+			override: map[string]any{"nonce": "another-login"},
+			wantErr:  "invalid ID token",
+		},
+		{
+			name:     "wrong audience",
+			override: map[string]any{"aud": "another-client"},
+			wantErr:  "invalid ID token",
+		},
+		{
+			name: "wrong PKCE verifier",
+			tamper: func(states *fakeOIDCStates, state string) {
+				s := states.states[state]
+				s.CodeVerifier = "not-the-verifier-of-this-login-000000000000"
+				states.states[state] = s
+			},
+			wantErr: "the provider rejected the login",
+		},
+		{
+			name: "expired state",
+			tamper: func(states *fakeOIDCStates, state string) {
+				s := states.states[state]
+				s.ExpiresAt = time.Now().Add(-time.Second)
+				states.states[state] = s
+			},
+			wantErr: "login request expired, please try again",
+		},
+	}
+
+	issuer := newMockIssuer(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, states := newTestOIDCService(t, issuer, tt.trustEmail)
+			issuer.mu.Lock()
+			issuer.override = tt.override
+			issuer.mu.Unlock()
+
+			authorization, err := s.Begin("mock")
+			if err != nil {
+				t.Fatal(err)
+			}
+			code := issuer.authorize(t, authorization.AuthorizationURL)
+			if tt.tamper != nil {
+				tt.tamper(states, authorization.State)
+			}
+
+			user, err := s.exchange(context.Background(), code, authorization.State, nil)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if user.ID != "mock-user-1" || user.Email != "ann@example.com" || user.Name != "Ann" || user.Provider != "mock" {
+				t.Fatalf("got user %+v", user)
+			}
+		})
+	}
+}
+
+func TestOIDCStateReuse(t *testing.T) {
+	issuer := newMockIssuer(t)
+	s, _ := newTestOIDCService(t, issuer, false)
+
+	authorization, err := s.Begin("mock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := issuer.authorize(t, authorization.AuthorizationURL)
+	if _, err := s.exchange(context.Background(), code, authorization.State, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// A replayed callback fails on the spent state before the code is sent
+	// to the provider again.
+	_, err = s.exchange(context.Background(), code, authorization.State, nil)
+	if err == nil || err.Error() != "login request expired, please try again" {
+		t.Fatalf("got error %v on reused state", err)
+	}
+}
+
+func TestOIDCStateOfLink(t *testing.T) {
+	issuer := newMockIssuer(t)
+	s, _ := newTestOIDCService(t, issuer, false)
+
+	// A state started for linking can't finish a login, and vice versa.
+	userId := "user-1"
+	linkAuthorization, err := s.begin("mock", &userId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := issuer.authorize(t, linkAuthorization.AuthorizationURL)
+	if _, err := s.exchange(context.Background(), code, linkAuthorization.State, nil); err == nil {
+		t.Fatal("link state finished a login")
+	}
+
+	loginAuthorization, err := s.Begin("mock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code = issuer.authorize(t, loginAuthorization.AuthorizationURL)
+	if _, err := s.exchange(context.Background(), code, loginAuthorization.State, &userId); err == nil {
+		t.Fatal("login state finished a link")
+	}
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"todolist/internal/models"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestReauthenticateWrongPasswords(t *testing.T) {
+	env := newUserTestEnv(t)
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.users.add(&models.User{Id: "user-1", Username: "ann", Email: "ann@example.com", PasswordHash: string(hash), IsVerified: true})
+	user, _ := env.users.GetByID("user-1")
+
+	for i := 0; i < accountAttemptPolicy.Free+1; i++ {
+		if err := env.service.Reauthenticate(user, "", "wrong", testClient); !errors.Is(err, ErrInvalidPassword) {
+			t.Fatalf("attempt %d: got error %v, want %v", i+1, err, ErrInvalidPassword)
+		}
+	}
+
+	// The account is locked now, for re-authentication and login alike.
+	var tooMany *TooManyAttemptsError
+	if err := env.service.Reauthenticate(user, "", "correct horse", testClient); !errors.As(err, &tooMany) {
+		t.Fatalf("got error %v, want too many attempts", err)
+	}
+	if _, err := env.service.LoginUser("ann", "correct horse", testClient); !errors.As(err, &tooMany) {
+		t.Fatalf("login got error %v, want too many attempts", err)
+	}
+	if tooMany.RetryAfterSeconds() < 1 {
+		t.Fatalf("got Retry-After %d", tooMany.RetryAfterSeconds())
+	}
+}
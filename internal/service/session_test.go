@@ -0,0 +1,58 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"todolist/internal/models"
+	"todolist/internal/utils"
+)
+
+// fakeAccessTokens implements what revocation uses of AccessTokenRepository.
+type fakeAccessTokens struct {
+	AccessTokenRepository
+	tokens map[string]models.AccessToken
+}
+
+func (f *fakeAccessTokens) DeleteByUserID(userId string) error {
+	for id, t := range f.tokens {
+		if t.UserID == userId {
+			delete(f.tokens, id)
+		}
+	}
+	return nil
+}
+
+func TestSessionRevocationAndAccessTokens(t *testing.T) {
+	tests := []struct {
+		name       string
+		revoke     func(s *SessionService) error
+		wantTokens int
+	}{
+		{name: "other sessions", revoke: func(s *SessionService) error { return s.RevokeOthers("ann", "current") }, wantTokens: 1},
+		{name: "everywhere", revoke: func(s *SessionService) error { return s.RevokeAll("ann") }, wantTokens: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions := &fakeSessions{sessions: map[string]models.Session{
+				"current": {Id: "current", UserID: "ann"},
+				"laptop":  {Id: "laptop", UserID: "ann"},
+			}}
+			tokens := &fakeAccessTokens{tokens: map[string]models.AccessToken{
+				"script": {Id: "script", UserID: "ann"},
+			}}
+			s := NewSessionService(sessions, utils.NewJWTManager("test-secret", 15*time.Minute))
+			s.AddRevocationListener(NewAccessTokenService(tokens, s, nil))
+
+			if err := tt.revoke(s); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := sessions.sessions["laptop"]; ok {
+				t.Fatal("the other session survived")
+			}
+			if len(tokens.tokens) != tt.wantTokens {
+				t.Fatalf("got %d access tokens, want %d", len(tokens.tokens), tt.wantTokens)
+			}
+		})
+	}
+}
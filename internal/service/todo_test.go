@@ -0,0 +1,122 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+	"todolist/internal/models"
+)
+
+// fakeTodos implements what the tests below use of TodoRepository.
+type fakeTodos struct {
+	TodoRepository
+	todos map[string]models.Todo
+}
+
+func (f *fakeTodos) Create(todo *models.Todo) error {
+	f.todos[todo.Id] = *todo
+	return nil
+}
+
+func (f *fakeTodos) GetByID(id, workspaceId string) (*models.Todo, error) {
+	todo, ok := f.todos[id]
+	if !ok || todo.WorkspaceID != workspaceId {
+		return nil, sql.ErrNoRows
+	}
+	return &todo, nil
+}
+
+func (f *fakeTodos) Update(todo *models.Todo) error {
+	f.todos[todo.Id] = *todo
+	return nil
+}
+
+func (f *fakeTodos) Delete(id, workspaceId string) error {
+	if _, err := f.GetByID(id, workspaceId); err != nil {
+		return errors.New("no rows affected")
+	}
+	delete(f.todos, id)
+	return nil
+}
+
+// fakeTodoAccess puts every user in one team workspace and shares todos
+// by id; shares go away with their todo, as they do by cascade.
+type fakeTodoAccess struct {
+	todos   *fakeTodos
+	members []string
+	shares  map[string][]string
+}
+
+func (f *fakeTodoAccess) GetActiveID(userId string) (string, error) { return "team", nil }
+
+func (f *fakeTodoAccess) GetMemberIDs(workspaceId string) ([]string, error) { return f.members, nil }
+
+func (f *fakeTodoAccess) GetSharedTodos(string) ([]models.Todo, error) { return nil, nil }
+
+func (f *fakeTodoAccess) GetSharedTodosPage(string, time.Time, string, int) ([]models.Todo, error) {
+	return nil, nil
+}
+
+func (f *fakeTodoAccess) GetSharedTodo(todoId, granteeId string) (*models.Todo, error) {
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeTodoAccess) GetGranteeIDs(todoId string) ([]string, error) {
+	if _, ok := f.todos.todos[todoId]; !ok {
+		return nil, nil
+	}
+	return f.shares[todoId], nil
+}
+
+func TestTodoEventRecipients(t *testing.T) {
+	todos := &fakeTodos{todos: map[string]models.Todo{}}
+	access := &fakeTodoAccess{todos: todos, members: []string{"ann", "bob"}, shares: map[string][]string{}}
+	broker := NewEventBroker()
+	s := NewTodoService(todos, access, access)
+	s.AddListener(broker)
+
+	// Ann creates a todo in the team workspace and shares it with Carol.
+	todo, err := s.CreateTodo("ann", "Plan offsite", "", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	access.shares[todo.Id] = []string{"carol"}
+
+	streams := map[string]<-chan StreamEvent{}
+	for _, userId := range []string{"ann", "bob", "carol", "dave"} {
+		ch, _, unsubscribe := broker.Subscribe(userId, 0)
+		defer unsubscribe()
+		streams[userId] = ch
+	}
+
+	todo.Title = "Plan the offsite"
+	if err := s.UpdateTodo("bob", todo); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteTodo("bob", todo.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []models.TodoEventType{models.TodoUpdated, models.TodoDeleted}
+	for _, userId := range []string{"ann", "bob", "carol"} {
+		var got []models.TodoEventType
+		for len(got) < len(want) {
+			select {
+			case event := <-streams[userId]:
+				got = append(got, event.Event.Type)
+			default:
+				t.Fatalf("%s got events %v, want %v", userId, got, want)
+			}
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("%s got events %v, want %v", userId, got, want)
+		}
+	}
+	select {
+	case event := <-streams["dave"]:
+		t.Fatalf("dave, who can't see the todo, got %v", event.Event.Type)
+	default:
+	}
+}
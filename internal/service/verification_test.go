@@ -0,0 +1,146 @@
+package service
+
+import (
+	"os"
+	"testing"
+	"time"
+	"todolist/internal/models"
+	"todolist/internal/repository"
+	"todolist/internal/utils"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+var testClient = models.SessionClient{IP: "203.0.113.7", UserAgent: "test"}
+
+func TestConsumeVerificationCode(t *testing.T) {
+	const code = "042137"
+	tests := []struct {
+		name     string
+		codeHash func(env *userTestEnv) string
+		input    string
+		wantErr  string
+	}{
+		{name: "issued code", codeHash: func(env *userTestEnv) string { return env.jwt.HashCode(code) }, input: code},
+		// Migration 000018 stored encode(sha256(code), 'hex').
+		{name: "migrated code", codeHash: func(env *userTestEnv) string { return utils.HashToken(code) }, input: code},
+		{
+			name:     "wrong code",
+			codeHash: func(env *userTestEnv) string { return env.jwt.HashCode(code) },
+			input:    "042138",
+			wantErr:  "invalid verification code or email",
+		},
+		{
+			name:     "wrong migrated code",
+			codeHash: func(env *userTestEnv) string { return utils.HashToken(code) },
+			input:    "042138",
+			wantErr:  "invalid verification code or email",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newUserTestEnv(t)
+			env.users.add(&models.User{Id: "user-1", Username: "ann", Email: "ann@example.com"})
+			env.codes.Create(&models.VerificationToken{
+				Id:        "code-1",
+				UserID:    "user-1",
+				Purpose:   models.VerificationRegister,
+				CodeHash:  tt.codeHash(env),
+				Email:     "ann@example.com",
+				ExpiresAt: time.Now().Add(time.Hour),
+			})
+
+			resp, err := env.service.VerifyEmail("ann@example.com", tt.input, testClient)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Token == "" || !resp.User.IsVerified {
+				t.Fatalf("got %+v, want a verified login", resp)
+			}
+			if _, err := env.service.VerifyEmail("ann@example.com", tt.input, testClient); err == nil {
+				t.Fatal("the code worked twice")
+			}
+		})
+	}
+}
+
+// TestMigratedCodeAfterUpgrade stores a pending code the way the app did
+// before migration 000018, migrates to the latest schema and verifies the
+// account with the code. It needs an empty PostgreSQL database in
+// TEST_DATABASE_URL, which it wipes.
+func TestMigratedCodeAfterUpgrade(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := sqlx.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	driver, err := postgres.WithInstance(db.DB, &postgres.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := migrate.NewWithDatabaseInstance("file://../database/migrations", "postgres", driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Drop(); err != nil {
+		t.Fatal(err)
+	}
+	// Drop also removes the version table, so start over with a new instance.
+	driver, err = postgres.WithInstance(db.DB, &postgres.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err = migrate.NewWithDatabaseInstance("file://../database/migrations", "postgres", driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Migrate(17); err != nil {
+		t.Fatal(err)
+	}
+
+	const code = "042137"
+	_, err = db.Exec(`
+		INSERT INTO users (id, username, email, password_hash, is_verified, verification_code, verification_code_expires)
+		VALUES ('user-1', 'ann', 'ann@example.com', '', FALSE, $1, NOW() + INTERVAL '1 hour')`, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	jwtManager := utils.NewJWTManager("test-secret", 15*time.Minute)
+	sessions := NewSessionService(repository.NewSessionRepository(db), jwtManager)
+	limiter := NewAttemptLimiter(repository.NewAuthAttemptRepository(db))
+	users := NewUserService(
+		repository.NewUsersRepository(db),
+		repository.NewRefreshTokenRepository(db),
+		repository.NewPasswordResetRepository(db),
+		repository.NewVerificationTokenRepository(db),
+		repository.NewUserIdentityRepository(db),
+		sessions, limiter, jwtManager, time.Hour)
+
+	resp, err := users.VerifyEmail("ann@example.com", code, testClient)
+	if err != nil {
+		t.Fatalf("migrated code rejected: %v", err)
+	}
+	if !resp.User.IsVerified {
+		t.Fatal("account is not verified")
+	}
+}
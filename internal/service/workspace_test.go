@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"todolist/internal/models"
+)
+
+// fakeWorkspaces keeps members per workspace; it implements what
+// WorkspaceService.BeforeUserDelete uses.
+type fakeWorkspaces struct {
+	WorkspaceRepository
+	workspaces map[string]models.Workspace
+	members    map[string][]models.WorkspaceMember
+}
+
+func (f *fakeWorkspaces) GetListByUserID(userId string) ([]models.Workspace, error) {
+	list := []models.Workspace{}
+	for id, ws := range f.workspaces {
+		for _, m := range f.members[id] {
+			if m.UserID == userId {
+				ws.Role = m.Role
+				list = append(list, ws)
+			}
+		}
+	}
+	return list, nil
+}
+
+func (f *fakeWorkspaces) GetMembers(workspaceId string) ([]models.WorkspaceMember, error) {
+	return f.members[workspaceId], nil
+}
+
+func (f *fakeWorkspaces) CountOwners(workspaceId string) (int, error) {
+	owners := 0
+	for _, m := range f.members[workspaceId] {
+		if m.Role == models.WorkspaceRoleOwner {
+			owners++
+		}
+	}
+	return owners, nil
+}
+
+func TestWorkspaceBeforeUserDelete(t *testing.T) {
+	owner := func(id string) models.WorkspaceMember {
+		return models.WorkspaceMember{UserID: id, Role: models.WorkspaceRoleOwner}
+	}
+	member := func(id string) models.WorkspaceMember {
+		return models.WorkspaceMember{UserID: id, Role: models.WorkspaceRoleMember}
+	}
+	tests := []struct {
+		name    string
+		members []models.WorkspaceMember
+		wantErr error
+	}{
+		{name: "sole owner with members", members: []models.WorkspaceMember{owner("ann"), member("bob")}, wantErr: ErrSoleWorkspaceOwner},
+		{name: "one of two owners", members: []models.WorkspaceMember{owner("ann"), owner("bob")}},
+		{name: "last member", members: []models.WorkspaceMember{owner("ann")}},
+		{name: "plain member", members: []models.WorkspaceMember{owner("bob"), member("ann")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeWorkspaces{
+				workspaces: map[string]models.Workspace{"team": {Id: "team", Name: "Team"}},
+				members:    map[string][]models.WorkspaceMember{"team": tt.members},
+			}
+			s := NewWorkspaceService(repo, nil)
+			_, err := s.BeforeUserDelete("ann")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
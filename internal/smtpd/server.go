@@ -0,0 +1,335 @@
+// Package smtpd implements the small subset of SMTP (RFC 5321) needed to
+// receive mail for the todo-by-email feature: EHLO/HELO, MAIL, RCPT, DATA,
+// RSET, NOOP and QUIT, with the SIZE extension.
+package smtpd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Backend decides which recipients are accepted and receives complete messages.
+type Backend interface {
+	AcceptRecipient(rcpt string) bool
+	Deliver(from string, to []string, data []byte) error
+}
+
+type Server struct {
+	Addr            string
+	Domain          string
+	MaxMessageBytes int64
+	MaxRecipients   int
+	Timeout         time.Duration
+	Backend         Backend
+
+	listener net.Listener
+}
+
+// Line limits from RFC 5321 section 4.5.3.1, including the CRLF.
+const (
+	maxCommandLine = 512
+	maxTextLine    = 1000
+)
+
+var (
+	errMessageTooLarge = errors.New("message exceeds size limit")
+	errLineTooLong     = errors.New("line exceeds length limit")
+)
+
+func NewServer(addr, domain string, maxMessageBytes int64, backend Backend) *Server {
+	return &Server{
+		Addr:            addr,
+		Domain:          domain,
+		MaxMessageBytes: maxMessageBytes,
+		MaxRecipients:   10,
+		Timeout:         2 * time.Minute,
+		Backend:         backend,
+	}
+}
+
+func (s *Server) ListenAndServe() error {
+	l, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	s.listener = l
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.serve(conn)
+	}
+}
+
+func (s *Server) Close() error {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Close()
+}
+
+type session struct {
+	server *Server
+	conn   net.Conn
+	reader *bufio.Reader
+	writer *bufio.Writer
+	helo   bool
+	// mail is set by MAIL; from alone can't tell, since the null reverse
+	// path "<>" of bounces leaves it empty.
+	mail bool
+	from string
+	to   []string
+}
+
+func (s *Server) serve(conn net.Conn) {
+	defer conn.Close()
+	sess := &session{
+		server: s,
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+		writer: bufio.NewWriter(conn),
+	}
+	sess.reply(220, s.Domain+" ESMTP ready")
+
+	for {
+		conn.SetDeadline(time.Now().Add(s.Timeout))
+		line, err := sess.readLine()
+		if errors.Is(err, errLineTooLong) {
+			sess.reply(500, "Line too long")
+			continue
+		}
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		if !sess.handle(strings.ToUpper(verb), strings.TrimSpace(arg)) {
+			return
+		}
+	}
+}
+
+func (sess *session) reply(code int, lines ...string) {
+	for i, line := range lines {
+		sep := " "
+		if i < len(lines)-1 {
+			sep = "-"
+		}
+		fmt.Fprintf(sess.writer, "%d%s%s\r\n", code, sep, line)
+	}
+	sess.writer.Flush()
+}
+
+func (sess *session) readLine() (string, error) {
+	line, err := sess.readRawLine(maxCommandLine)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readRawLine reads one line of at most max bytes. A longer line is drained
+// and reported as errLineTooLong, so a peer that never sends a newline
+// cannot grow memory without bound.
+func (sess *session) readRawLine(max int) (string, error) {
+	var line []byte
+	tooLong := false
+	for {
+		chunk, err := sess.reader.ReadSlice('\n')
+		if !tooLong && len(line)+len(chunk) > max {
+			tooLong = true
+			line = nil
+		}
+		if !tooLong {
+			line = append(line, chunk...)
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		break
+	}
+	if tooLong {
+		return "", errLineTooLong
+	}
+	return string(line), nil
+}
+
+func (sess *session) reset() {
+	sess.mail = false
+	sess.from = ""
+	sess.to = nil
+}
+
+// handle processes one command and reports whether the connection stays open.
+func (sess *session) handle(verb, arg string) bool {
+	s := sess.server
+	switch verb {
+	case "HELO":
+		sess.helo = true
+		sess.reset()
+		sess.reply(250, s.Domain)
+	case "EHLO":
+		sess.helo = true
+		sess.reset()
+		sess.reply(250, s.Domain, "SIZE "+strconv.FormatInt(s.MaxMessageBytes, 10), "8BITMIME")
+	case "MAIL":
+		if !sess.helo {
+			sess.reply(503, "Send HELO/EHLO first")
+			return true
+		}
+		from, params, ok := parsePath(arg, "FROM:")
+		if !ok {
+			sess.reply(501, "Syntax: MAIL FROM:<address>")
+			return true
+		}
+		if size, ok := params["SIZE"]; ok {
+			if n, err := strconv.ParseInt(size, 10, 64); err == nil && n > s.MaxMessageBytes {
+				sess.reply(552, "Message size exceeds fixed limit")
+				return true
+			}
+		}
+		sess.reset()
+		sess.mail = true
+		sess.from = from
+		sess.reply(250, "OK")
+	case "RCPT":
+		if !sess.mail {
+			sess.reply(503, "Need MAIL command first")
+			return true
+		}
+		rcpt, _, ok := parsePath(arg, "TO:")
+		if !ok || rcpt == "" {
+			sess.reply(501, "Syntax: RCPT TO:<address>")
+			return true
+		}
+		if len(sess.to) >= s.MaxRecipients {
+			sess.reply(452, "Too many recipients")
+			return true
+		}
+		if !s.Backend.AcceptRecipient(rcpt) {
+			sess.reply(550, "No such user here")
+			return true
+		}
+		sess.to = append(sess.to, rcpt)
+		sess.reply(250, "OK")
+	case "DATA":
+		if len(sess.to) == 0 {
+			sess.reply(503, "Need RCPT command first")
+			return true
+		}
+		sess.reply(354, "End data with <CR><LF>.<CR><LF>")
+		data, err := sess.readData()
+		if errors.Is(err, errMessageTooLarge) {
+			sess.reply(552, "Message size exceeds fixed limit")
+			sess.reset()
+			return true
+		}
+		if errors.Is(err, errLineTooLong) {
+			sess.reply(500, "Line too long")
+			sess.reset()
+			return true
+		}
+		if err != nil {
+			return false
+		}
+		if err := s.Backend.Deliver(sess.from, sess.to, data); err != nil {
+			slog.Warn("Inbound mail rejected", "error", err, "from", sess.from)
+			sess.reply(554, "Transaction failed: "+err.Error())
+		} else {
+			sess.reply(250, "OK: queued")
+		}
+		sess.reset()
+	case "RSET":
+		sess.reset()
+		sess.reply(250, "OK")
+	case "NOOP":
+		sess.reply(250, "OK")
+	case "QUIT":
+		sess.reply(221, "Bye")
+		return false
+	default:
+		sess.reply(502, "Command not implemented")
+	}
+	return true
+}
+
+// readData reads a dot-terminated message body, undoing dot-stuffing and
+// enforcing the size and line length limits. Rejected messages are drained
+// before returning.
+func (sess *session) readData() ([]byte, error) {
+	var buf []byte
+	tooLarge, tooLong := false, false
+	for {
+		line, err := sess.readRawLine(maxTextLine)
+		if errors.Is(err, errLineTooLong) {
+			tooLong = true
+			buf = nil
+			continue
+		}
+		if err != nil {
+			if err == io.EOF {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		if line == ".\r\n" || line == ".\n" {
+			break
+		}
+		line = strings.TrimPrefix(line, ".")
+		if int64(len(buf)+len(line)) > sess.server.MaxMessageBytes {
+			tooLarge = true
+			buf = nil
+		}
+		if !tooLarge && !tooLong {
+			buf = append(buf, line...)
+		}
+	}
+	if tooLarge {
+		return nil, errMessageTooLarge
+	}
+	if tooLong {
+		return nil, errLineTooLong
+	}
+	return buf, nil
+}
+
+// parsePath parses "FROM:<addr> PARAM=VALUE" style arguments.
+func parsePath(arg, prefix string) (string, map[string]string, bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", nil, false
+	}
+	rest := strings.TrimSpace(arg[len(prefix):])
+	path, paramStr, _ := strings.Cut(rest, " ")
+	if !strings.HasPrefix(path, "<") || !strings.HasSuffix(path, ">") {
+		return "", nil, false
+	}
+	address := strings.TrimSuffix(strings.TrimPrefix(path, "<"), ">")
+	if address != "" {
+		parsed, err := mail.ParseAddress(address)
+		if err != nil {
+			return "", nil, false
+		}
+		address = parsed.Address
+	}
+
+	params := make(map[string]string)
+	for _, p := range strings.Fields(paramStr) {
+		key, value, _ := strings.Cut(p, "=")
+		params[strings.ToUpper(key)] = value
+	}
+	return strings.ToLower(address), params, true
+}
@@ -0,0 +1,179 @@
+package smtpd
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type delivery struct {
+	from string
+	to   []string
+	data string
+}
+
+// fakeBackend accepts every recipient except nobody@ and records deliveries.
+type fakeBackend struct {
+	mu         sync.Mutex
+	deliveries []delivery
+}
+
+func (b *fakeBackend) AcceptRecipient(rcpt string) bool {
+	return !strings.HasPrefix(rcpt, "nobody@")
+}
+
+func (b *fakeBackend) Deliver(from string, to []string, data []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.deliveries = append(b.deliveries, delivery{from: from, to: to, data: string(data)})
+	return nil
+}
+
+// client drives one session over net.Pipe, which blocks every write until
+// the other side reads it, so each command is followed by reading its reply.
+type client struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func newTestSession(t *testing.T, maxMessageBytes int64) (*client, *fakeBackend) {
+	t.Helper()
+	backend := &fakeBackend{}
+	s := NewServer("", "mx.test", maxMessageBytes, backend)
+	s.Timeout = 5 * time.Second
+	serverConn, clientConn := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		s.serve(serverConn)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		clientConn.Close()
+		<-done
+	})
+
+	c := &client{t: t, conn: clientConn, reader: bufio.NewReader(clientConn)}
+	clientConn.SetDeadline(time.Now().Add(5 * time.Second))
+	c.expect(220)
+	c.cmd("EHLO client.test", 250)
+	return c, backend
+}
+
+func (c *client) write(s string) {
+	c.t.Helper()
+	if _, err := c.conn.Write([]byte(s)); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// expect reads a (possibly multi-line) reply and checks its code.
+func (c *client) expect(code int) {
+	c.t.Helper()
+	for {
+		line, err := c.reader.ReadString('\n')
+		if err != nil {
+			c.t.Fatal(err)
+		}
+		if !strings.HasPrefix(line, strconv.Itoa(code)) {
+			c.t.Fatalf("got reply %q, want %d", strings.TrimSpace(line), code)
+		}
+		if len(line) < 4 || line[3] != '-' {
+			return
+		}
+	}
+}
+
+func (c *client) cmd(line string, code int) {
+	c.t.Helper()
+	c.write(line + "\r\n")
+	c.expect(code)
+}
+
+func TestCommandLineLimit(t *testing.T) {
+	c, _ := newTestSession(t, 1024)
+	c.cmd("NOOP "+strings.Repeat("x", maxCommandLine), 500)
+	c.cmd("NOOP "+strings.Repeat("x", maxCommandLine-len("NOOP \r\n")), 250)
+	c.cmd("QUIT", 221)
+}
+
+func TestMailTransaction(t *testing.T) {
+	c, backend := newTestSession(t, 1024)
+	c.cmd("RCPT TO:<todo@mx.test>", 503)
+	c.cmd("MAIL FROM:<Ann@Example.com>", 250)
+	c.cmd("RCPT TO:<nobody@mx.test>", 550)
+	c.cmd("RCPT TO:<todo@mx.test>", 250)
+	c.cmd("DATA", 354)
+	c.write("Subject: Buy milk\r\n\r\n..starts with a dot\r\n..\r\n.\r\n")
+	c.expect(250)
+
+	// Bounces have the null reverse path.
+	c.cmd("MAIL FROM:<>", 250)
+	c.cmd("RCPT TO:<todo@mx.test>", 250)
+	c.cmd("DATA", 354)
+	c.write("Subject: Undeliverable\r\n\r\n.\r\n")
+	c.expect(250)
+	c.cmd("QUIT", 221)
+
+	want := []delivery{
+		{from: "ann@example.com", to: []string{"todo@mx.test"}, data: "Subject: Buy milk\r\n\r\n.starts with a dot\r\n.\r\n"},
+		{from: "", to: []string{"todo@mx.test"}, data: "Subject: Undeliverable\r\n\r\n"},
+	}
+	if len(backend.deliveries) != len(want) {
+		t.Fatalf("got %d deliveries, want %d", len(backend.deliveries), len(want))
+	}
+	for i, got := range backend.deliveries {
+		if got.from != want[i].from || strings.Join(got.to, ",") != strings.Join(want[i].to, ",") || got.data != want[i].data {
+			t.Fatalf("delivery %d: got %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestRecipientLimit(t *testing.T) {
+	c, backend := newTestSession(t, 1024)
+	c.cmd("MAIL FROM:<ann@example.com>", 250)
+	for i := range 10 {
+		c.cmd("RCPT TO:<todo"+strconv.Itoa(i)+"@mx.test>", 250)
+	}
+	c.cmd("RCPT TO:<todo10@mx.test>", 452)
+	c.cmd("DATA", 354)
+	c.write("Subject: Many\r\n.\r\n")
+	c.expect(250)
+	if len(backend.deliveries) != 1 || len(backend.deliveries[0].to) != 10 {
+		t.Fatalf("got deliveries %+v, want one to 10 recipients", backend.deliveries)
+	}
+}
+
+func TestRejectedMessagesAreDrained(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{name: "line too long", body: "Subject: Long\r\n" + strings.Repeat("x", maxTextLine) + "\r\nQUIT\r\n", code: 500},
+		{name: "message too large", body: strings.Repeat("0123456789abcdef\r\n", 10) + "QUIT\r\n", code: 552},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, backend := newTestSession(t, 100)
+			c.cmd("MAIL FROM:<ann@example.com>", 250)
+			c.cmd("RCPT TO:<todo@mx.test>", 250)
+			c.cmd("DATA", 354)
+			c.write(tt.body + ".\r\n")
+			c.expect(tt.code)
+
+			// The rest of the message, QUIT included, was read as data and
+			// the transaction was reset.
+			c.cmd("NOOP", 250)
+			c.cmd("RCPT TO:<todo@mx.test>", 503)
+			if len(backend.deliveries) != 0 {
+				t.Fatalf("got deliveries %+v", backend.deliveries)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalKeys(t *testing.T) {
+	tests := []struct {
+		key     string
+		path    string // relative to the root; empty if the key is rejected
+		wantErr bool
+	}{
+		{key: "todo-1/attachment-1", path: "todo-1/attachment-1"},
+		{key: "todo-1", path: "todo-1"},
+		{key: "", wantErr: true},
+		{key: "/etc/passwd", wantErr: true},
+		{key: "../outside", wantErr: true},
+		{key: "..", wantErr: true},
+		{key: "todo-1/../../outside", wantErr: true},
+		{key: "todo-1/./attachment-1", wantErr: true},
+		{key: "todo-1//attachment-1", wantErr: true},
+		{key: "todo-1/", wantErr: true},
+	}
+
+	root := t.TempDir()
+	l, err := NewLocal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := l.path(tt.key)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("key %q was accepted as %s", tt.key, got)
+				}
+				if err := l.Put(context.Background(), tt.key, strings.NewReader("x"), 1, "text/plain"); err == nil {
+					t.Fatalf("Put accepted key %q", tt.key)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := filepath.Join(root, filepath.FromSlash(tt.path)); got != want {
+				t.Fatalf("got path %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestLocalRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	l, err := NewLocal(filepath.Join(root, "attachments"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"todo-1/a", "todo-1/b", "todo-10/a"} {
+		if err := l.Put(ctx, key, strings.NewReader("content of "+key), -1, "text/plain"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	readBlob(t, l, "todo-1/a", "content of todo-1/a")
+
+	// Overwriting replaces the blob and leaves no temporary files behind.
+	if err := l.Put(ctx, "todo-1/a", strings.NewReader("new content"), -1, "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	readBlob(t, l, "todo-1/a", "new content")
+	entries, err := os.ReadDir(filepath.Join(root, "attachments", "todo-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d files in todo-1, want 2", len(entries))
+	}
+
+	if err := l.Delete(ctx, "todo-1/b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Open(ctx, "todo-1/b"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("got error %v after Delete, want ErrNotFound", err)
+	}
+	if err := l.Delete(ctx, "todo-1/b"); err != nil {
+		t.Fatalf("deleting a missing blob: %v", err)
+	}
+
+	// The prefix is a whole path segment: todo-10 is not under todo-1.
+	if err := l.DeletePrefix(ctx, "todo-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Open(ctx, "todo-1/a"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("got error %v after DeletePrefix, want ErrNotFound", err)
+	}
+	readBlob(t, l, "todo-10/a", "content of todo-10/a")
+}
+
+func readBlob(t *testing.T, s Storage, key, want string) {
+	t.Helper()
+	r, err := s.Open(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Fatalf("%s holds %q, want %q", key, got, want)
+	}
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestS3RoundTrip runs against a real S3-compatible server, e.g. the MinIO
+// from docker-compose. It needs S3_TEST_ENDPOINT, S3_TEST_ACCESS_KEY and
+// S3_TEST_SECRET_KEY; S3_TEST_BUCKET defaults to "todolist-test".
+func TestS3RoundTrip(t *testing.T) {
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_TEST_ENDPOINT is not set")
+	}
+	bucket := os.Getenv("S3_TEST_BUCKET")
+	if bucket == "" {
+		bucket = "todolist-test"
+	}
+	ctx := context.Background()
+	s, err := NewS3(ctx, S3Config{
+		Endpoint:  endpoint,
+		AccessKey: os.Getenv("S3_TEST_ACCESS_KEY"),
+		SecretKey: os.Getenv("S3_TEST_SECRET_KEY"),
+		Bucket:    bucket,
+		Region:    os.Getenv("S3_TEST_REGION"),
+		UseSSL:    os.Getenv("S3_TEST_USE_SSL") == "true",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prefix := strings.ReplaceAll(t.Name(), "/", "-")
+	keys := []string{prefix + "/a", prefix + "/b", prefix + "0/a"}
+	t.Cleanup(func() {
+		s.DeletePrefix(ctx, prefix)
+		s.DeletePrefix(ctx, prefix+"0")
+	})
+	for _, key := range keys {
+		content := "content of " + key
+		if err := s.Put(ctx, key, strings.NewReader(content), int64(len(content)), "text/plain"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	readBlob(t, s, keys[0], "content of "+keys[0])
+
+	if err := s.Delete(ctx, keys[1]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Open(ctx, keys[1]); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("got error %v after Delete, want ErrNotFound", err)
+	}
+
+	if err := s.DeletePrefix(ctx, prefix); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Open(ctx, keys[0]); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("got error %v after DeletePrefix, want ErrNotFound", err)
+	}
+	readBlob(t, s, keys[2], "content of "+keys[2])
+
+	if err := s.Put(ctx, "../outside", strings.NewReader("x"), 1, "text/plain"); err == nil {
+		t.Fatal("Put accepted a key outside the bucket root")
+	}
+}
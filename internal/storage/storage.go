@@ -0,0 +1,29 @@
+// Package storage keeps file contents (blobs) outside the database.
+// Keys are slash-separated paths such as "<todoId>/<attachmentId>".
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// DeletePrefix removes every blob whose key starts with prefix + "/".
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// validKey rejects keys that could escape the storage root.
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || strings.HasPrefix(key, "..") {
+		return errors.New("invalid storage key")
+	}
+	return nil
+}
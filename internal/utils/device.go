@@ -0,0 +1,54 @@
+package utils
+
+import "strings"
+
+// DeviceName turns a User-Agent into a short label such as "Firefox on
+// Linux" for the session list. Unknown agents are labelled "Unknown device".
+func DeviceName(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	if strings.HasPrefix(ua, "todo-cli") {
+		return "todo CLI"
+	}
+
+	client := ""
+	switch {
+	case strings.Contains(ua, "edg/"):
+		client = "Edge"
+	case strings.Contains(ua, "opr/"):
+		client = "Opera"
+	case strings.Contains(ua, "firefox/"):
+		client = "Firefox"
+	case strings.Contains(ua, "chrome/"), strings.Contains(ua, "crios/"):
+		client = "Chrome"
+	case strings.Contains(ua, "safari/"):
+		client = "Safari"
+	case strings.HasPrefix(ua, "curl/"):
+		client = "curl"
+	case strings.HasPrefix(ua, "grpc-"):
+		client = "gRPC client"
+	}
+
+	os := ""
+	switch {
+	case strings.Contains(ua, "android"):
+		os = "Android"
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"):
+		os = "iOS"
+	case strings.Contains(ua, "windows"):
+		os = "Windows"
+	case strings.Contains(ua, "mac os x"), strings.Contains(ua, "macintosh"):
+		os = "macOS"
+	case strings.Contains(ua, "linux"):
+		os = "Linux"
+	}
+
+	switch {
+	case client != "" && os != "":
+		return client + " on " + os
+	case client != "":
+		return client
+	case os != "":
+		return os
+	}
+	return "Unknown device"
+}
@@ -4,28 +4,49 @@ import (
 	"crypto/rand"
 	"log"
 	"math/big"
+	"mime"
 	"net/smtp"
 	"os"
 	"strconv"
+	"strings"
+	"unicode"
 )
 
 func SendVerificationEmail(email, code string) {
+	sendEmail(email, "Verify Your Account", "Your verification code is: "+code+"\r\n")
+}
+
+// SendPasswordResetEmail sends the token for POST /auth/password/reset.
+func SendPasswordResetEmail(email, token string) {
+	sendEmail(email, "Reset Your Password",
+		"Someone asked to reset the password of your ToDoList account.\r\n"+
+			"Your password reset token is: "+token+"\r\n"+
+			"It is valid for one hour. If it wasn't you, ignore this email.\r\n")
+}
+
+// SendMentionEmail notifies a user that they were @mentioned in a comment.
+func SendMentionEmail(email, author, todoTitle, comment string) {
+	sendEmail(email, author+" mentioned you on \""+todoTitle+"\"",
+		author+" mentioned you in a comment on \""+todoTitle+"\":\r\n\r\n"+comment+"\r\n")
+}
+
+func sendEmail(email, subject, body string) {
 	host := os.Getenv("SMTP_HOST")
 	port := os.Getenv("SMTP_PORT")
 	user := os.Getenv("SMTP_USER")
 	password := os.Getenv("SMTP_PASS")
 
 	if host == "" || port == "" || user == "" || password == "" {
-		log.Printf("SMTP config missing. Mock sending email to %s: code %s\n", email, code)
+		log.Printf("SMTP config missing. Mock sending email to %s: %s\n%s", email, subject, body)
 		return
 	}
 
 	auth := smtp.PlainAuth("", user, password, host)
 	to := []string{email}
-	msg := []byte("To: " + email + "\r\n" +
-		"Subject: Verify Your Account\r\n" +
+	msg := []byte("To: " + headerValue(email) + "\r\n" +
+		"Subject: " + mime.QEncoding.Encode("utf-8", headerValue(subject)) + "\r\n" +
 		"\r\n" +
-		"Your verification code is: " + code + "\r\n")
+		body)
 
 	addr := host + ":" + port
 	err := smtp.SendMail(addr, auth, user, to, msg)
@@ -36,6 +57,17 @@ func SendVerificationEmail(email, code string) {
 	}
 }
 
+// headerValue drops CR, LF and other control characters so that
+// user-supplied text (todo titles, usernames) can't inject extra headers.
+func headerValue(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
 func GenerateVerificationCode() (string, error) {
 	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
 	if err != nil {
@@ -1,14 +1,30 @@
 package utils
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// mfaChallengeTTL is how long a user has to enter the second factor after
+// the password was accepted.
+const mfaChallengeTTL = 5 * time.Minute
+
+const purposeMFA = "mfa"
+
 type Claims struct {
-	UserID string `json:"user_id"`
+	UserID    string `json:"user_id"`
+	SessionID string `json:"sid"`
+	// Purpose is empty for access tokens. Tokens with a purpose are only
+	// accepted by the matching Validate* method.
+	Purpose string `json:"purpose,omitempty"`
+	// Scopes limits what a personal access token may do. It is never part
+	// of a JWT, and nil means the full access of a session.
+	Scopes []string `json:"-"`
 	jwt.RegisteredClaims
 }
 type JWTManager struct {
@@ -20,19 +36,62 @@ func NewJWTManager(secretKey string, tokenDuration time.Duration) *JWTManager {
 	return &JWTManager{secretKey, tokenDuration}
 }
 
-func (jm *JWTManager) Generate(userId string) (string, error) {
-	claims := &Claims{
-		UserID: userId,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(jm.tokenDuration)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+// TokenDuration is how long generated access tokens stay valid.
+func (jm *JWTManager) TokenDuration() time.Duration {
+	return jm.tokenDuration
+}
+
+// HashCode returns the hex HMAC-SHA256 of an emailed code keyed with the
+// server secret. A plain hash of a 6-digit code could be reversed by trying
+// all million codes.
+func (jm *JWTManager) HashCode(code string) string {
+	mac := hmac.New(sha256.New, []byte(jm.secretKey))
+	mac.Write([]byte("verification-code:" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (jm *JWTManager) Generate(userId, sessionId string) (string, error) {
+	return jm.sign(&Claims{UserID: userId, SessionID: sessionId}, jm.tokenDuration)
+}
+
+// GenerateMFAChallenge returns a short-lived token proving that the first
+// login factor was accepted. It cannot be used as an access token.
+func (jm *JWTManager) GenerateMFAChallenge(userId string) (string, error) {
+	return jm.sign(&Claims{UserID: userId, Purpose: purposeMFA}, mfaChallengeTTL)
+}
+
+func (jm *JWTManager) Validate(tokenString string) (*Claims, error) {
+	claims, err := jm.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+func (jm *JWTManager) ValidateMFAChallenge(tokenString string) (*Claims, error) {
+	claims, err := jm.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != purposeMFA {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+func (jm *JWTManager) sign(claims *Claims, ttl time.Duration) (string, error) {
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(jm.secretKey))
 }
 
-func (jm *JWTManager) Validate(tokenString string) (*Claims, error) {
+func (jm *JWTManager) parse(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("unexpected signing method")
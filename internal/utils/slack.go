@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// SlackReplayWindow is how far a request timestamp may drift from now.
+const SlackReplayWindow = 5 * time.Minute
+
+// VerifySlackSignature checks the X-Slack-Signature header, which is
+// "v0=" + hex(HMAC-SHA256("v0:<timestamp>:<body>")) with the signing secret,
+// and rejects timestamps outside SlackReplayWindow.
+func VerifySlackSignature(secret, timestamp, signature string, body []byte, now time.Time) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("invalid request timestamp")
+	}
+	if d := now.Sub(time.Unix(ts, 0)); d > SlackReplayWindow || d < -SlackReplayWindow {
+		return errors.New("request timestamp outside replay window")
+	}
+
+	expected := SignSlackRequest(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errors.New("invalid request signature")
+	}
+	return nil
+}
+
+// SignSlackRequest computes the X-Slack-Signature value for a request body.
+func SignSlackRequest(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
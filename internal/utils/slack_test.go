@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// slackFixture is a recorded Slack request and the expected verdict.
+type slackFixture struct {
+	Name      string `json:"name"`
+	Secret    string `json:"secret"`
+	Timestamp string `json:"timestamp"`
+	Signature string `json:"signature"`
+	Body      string `json:"body"`
+	Now       string `json:"now"`
+	Error     string `json:"error"`
+}
+
+func TestVerifySlackSignature(t *testing.T) {
+	data, err := os.ReadFile("testdata/slack_signatures.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixtures []slackFixture
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range fixtures {
+		t.Run(f.Name, func(t *testing.T) {
+			now, err := strconv.ParseInt(f.Now, 10, 64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = VerifySlackSignature(f.Secret, f.Timestamp, f.Signature, []byte(f.Body), time.Unix(now, 0))
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != f.Error {
+				t.Errorf("error = %q, want %q", got, f.Error)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// SignWebhookPayload computes the value of the X-Webhook-Signature header.
+// Receivers recompute HMAC-SHA256 over "<timestamp>.<body>" with the shared secret.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ErrPrivateAddress is returned for outgoing requests to addresses inside
+// the server's own network.
+var ErrPrivateAddress = errors.New("address is not publicly routable")
+
+// IsPublicIP reports whether ip may be called by server-side requests to
+// user-supplied URLs: loopback, private, link-local (cloud metadata),
+// multicast and unspecified addresses are refused.
+func IsPublicIP(ip net.IP) bool {
+	return ip != nil && !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() && !ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() &&
+		!ip.IsUnspecified() && !cgnat.Contains(ip)
+}
+
+var cgnat = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// PublicDialer only connects to public addresses. The check runs on the
+// address actually dialed, after DNS resolution and on every redirect, so a
+// host that re-resolves to an internal address is still refused.
+func PublicDialer(timeout time.Duration) *net.Dialer {
+	return &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if !IsPublicIP(net.ParseIP(host)) {
+				return ErrPrivateAddress
+			}
+			return nil
+		},
+	}
+}